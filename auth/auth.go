@@ -0,0 +1,229 @@
+// Package auth authenticates TrainService callers with bearer tokens: JWTs
+// signed with HMAC-SHA256 by a static, locally managed key set. Each token
+// carries a role that an interceptor checks against a per-method policy.
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Role is what a caller is allowed to do.
+type Role string
+
+const (
+	RolePassenger Role = "passenger"
+	RoleConductor Role = "conductor"
+	RoleAdmin     Role = "admin"
+)
+
+var roles = map[Role]bool{RolePassenger: true, RoleConductor: true, RoleAdmin: true}
+
+// Claims are the JWT claims issued and accepted by the service.
+type Claims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Email     string `json:"email,omitempty"`
+	Role      Role   `json:"role"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var (
+	// ErrInvalidToken is returned for malformed tokens, unknown keys, bad
+	// signatures and claims this service does not accept.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpiredToken is returned for well-signed tokens past their expiry.
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// Key is a shared HMAC secret identified by its key id.
+type Key struct {
+	ID     string `json:"kid"`
+	Secret []byte `json:"secret"` // base64 in JSON
+}
+
+// KeySet holds the keys tokens are signed and verified with. Every key is
+// accepted for verification, so a new signing key can be rolled out before
+// the old one is retired.
+type KeySet struct {
+	Issuer     string `json:"issuer"`
+	SigningKey string `json:"signing_key"`
+	Keys       []Key  `json:"keys"`
+}
+
+// minSecretBytes is the shortest HMAC secret accepted.
+const minSecretBytes = 32
+
+// NewKeySet returns a key set for issuer with one random signing key
+// named id.
+func NewKeySet(issuer, id string) (*KeySet, error) {
+	secret := make([]byte, minSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return &KeySet{Issuer: issuer, SigningKey: id, Keys: []Key{{ID: id, Secret: secret}}}, nil
+}
+
+// WriteKeySet writes keys to a new file at path that only its owner can
+// read. It refuses to replace an existing file, which may hold keys still
+// in use.
+func WriteKeySet(path string, keys *KeySet) error {
+	if err := keys.validate(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadKeySet reads and validates a key set file.
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys KeySet
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	if err := keys.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &keys, nil
+}
+
+func (ks *KeySet) validate() error {
+	if ks.Issuer == "" {
+		return errors.New("issuer is required")
+	}
+	seen := make(map[string]bool)
+	for _, key := range ks.Keys {
+		if key.ID == "" || seen[key.ID] {
+			return fmt.Errorf("key id %q must be unique and non-empty", key.ID)
+		}
+		if len(key.Secret) < minSecretBytes {
+			return fmt.Errorf("key %s: secret must be at least %d bytes", key.ID, minSecretBytes)
+		}
+		seen[key.ID] = true
+	}
+	if !seen[ks.SigningKey] {
+		return fmt.Errorf("signing key %q is not in the key set", ks.SigningKey)
+	}
+	return nil
+}
+
+func (ks *KeySet) key(id string) []byte {
+	for _, key := range ks.Keys {
+		if key.ID == id {
+			return key.Secret
+		}
+	}
+	return nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+var encoding = base64.RawURLEncoding
+
+func sign(secret []byte, signingInput string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+// Sign issues a token for claims with the signing key. The issuer is
+// filled in from the key set.
+func (ks *KeySet) Sign(claims Claims) (string, error) {
+	if !roles[claims.Role] {
+		return "", fmt.Errorf("unknown role %q", claims.Role)
+	}
+	claims.Issuer = ks.Issuer
+	head, err := json.Marshal(header{Alg: "HS256", Typ: "JWT", Kid: ks.SigningKey})
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encoding.EncodeToString(head) + "." + encoding.EncodeToString(body)
+	return signingInput + "." + encoding.EncodeToString(sign(ks.key(ks.SigningKey), signingInput)), nil
+}
+
+// Verify checks the signature, issuer, expiry and role of token at now.
+func (ks *KeySet) Verify(token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	var head header
+	if err := decodeSegment(parts[0], &head); err != nil {
+		return nil, ErrInvalidToken
+	}
+	// Only HS256 is accepted; in particular "none" must never verify.
+	secret := ks.key(head.Kid)
+	if head.Alg != "HS256" || secret == nil {
+		return nil, ErrInvalidToken
+	}
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, sign(secret, parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Issuer != ks.Issuer || claims.Subject == "" || !roles[claims.Role] {
+		return nil, ErrInvalidToken
+	}
+	if claims.ExpiresAt == 0 || !now.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, ErrExpiredToken
+	}
+	return &claims, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := encoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+type claimsKey struct{}
+
+// NewContext returns ctx carrying the claims of the authenticated caller.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns the claims of the authenticated caller, if any.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testKeys() *KeySet {
+	return &KeySet{
+		Issuer:     "test-train",
+		SigningKey: "k2",
+		Keys: []Key{
+			{ID: "k1", Secret: []byte(strings.Repeat("1", minSecretBytes))},
+			{ID: "k2", Secret: []byte(strings.Repeat("2", minSecretBytes))},
+		},
+	}
+}
+
+func passenger() Claims {
+	return Claims{Subject: "john", Email: "john.doe@example.com", Role: RolePassenger, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	keys := testKeys()
+	token, err := keys.Sign(passenger())
+	require.NoError(t, err, "error signing token")
+
+	claims, err := keys.Verify(token, now)
+	require.NoError(t, err, "error verifying token")
+	assert.Equal(t, "test-train", claims.Issuer)
+	assert.Equal(t, "john.doe@example.com", claims.Email)
+	assert.Equal(t, RolePassenger, claims.Role)
+
+	_, err = keys.Verify(token, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrExpiredToken)
+}
+
+func TestVerifyRejectsForgedTokens(t *testing.T) {
+	keys := testKeys()
+	token, err := keys.Sign(passenger())
+	require.NoError(t, err, "error signing token")
+	parts := strings.Split(token, ".")
+
+	admin := passenger()
+	admin.Role = RoleAdmin
+	adminToken, err := keys.Sign(admin)
+	require.NoError(t, err, "error signing token")
+	adminClaims := strings.Split(adminToken, ".")[1]
+
+	other := testKeys()
+	other.Keys[1].Secret = []byte(strings.Repeat("x", minSecretBytes))
+	foreign, err := other.Sign(passenger())
+	require.NoError(t, err, "error signing token")
+
+	none := encoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT","kid":"k2"}`))
+
+	for name, token := range map[string]string{
+		"malformed":       "not-a-token",
+		"swapped claims":  parts[0] + "." + adminClaims + "." + parts[2],
+		"unknown key":     foreign,
+		"alg none":        none + "." + parts[1] + ".",
+		"empty signature": parts[0] + "." + parts[1] + ".",
+	} {
+		_, err := keys.Verify(token, now)
+		assert.ErrorIs(t, err, ErrInvalidToken, name)
+	}
+}
+
+func TestVerifyAcceptsRotatedKeys(t *testing.T) {
+	keys := testKeys()
+	keys.SigningKey = "k1"
+	token, err := keys.Sign(passenger())
+	require.NoError(t, err, "error signing token")
+
+	// Rolling the signing key forward keeps old tokens valid until k1 is removed
+	keys.SigningKey = "k2"
+	_, err = keys.Verify(token, now)
+	assert.NoError(t, err, "token signed with the previous key")
+
+	keys.Keys = keys.Keys[1:]
+	_, err = keys.Verify(token, now)
+	assert.ErrorIs(t, err, ErrInvalidToken, "token signed with a retired key")
+}
+
+func TestKeySetValidation(t *testing.T) {
+	keys := testKeys()
+	keys.Keys[0].Secret = []byte("short")
+	assert.Error(t, keys.validate(), "short secret")
+
+	keys = testKeys()
+	keys.SigningKey = "k3"
+	assert.Error(t, keys.validate(), "unknown signing key")
+
+	keys = testKeys()
+	keys.Keys[1].ID = "k1"
+	assert.Error(t, keys.validate(), "duplicate key id")
+}
+
+func TestNewKeySet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	keys, err := NewKeySet("test-train", "k1")
+	require.NoError(t, err, "error generating key set")
+	require.NoError(t, WriteKeySet(path, keys), "error writing key set")
+
+	loaded, err := LoadKeySet(path)
+	require.NoError(t, err, "error loading key set")
+	token, err := keys.Sign(passenger())
+	require.NoError(t, err, "error signing token")
+	_, err = loaded.Verify(token, now)
+	assert.NoError(t, err, "written key set should verify tokens signed with the generated one")
+
+	other, err := NewKeySet("test-train", "k1")
+	require.NoError(t, err, "error generating key set")
+	assert.NotEqual(t, keys.Keys[0].Secret, other.Keys[0].Secret, "secrets should be random")
+	assert.ErrorIs(t, WriteKeySet(path, other), os.ErrExist, "existing key sets must not be replaced")
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	keys := testKeys()
+	a := &Authenticator{
+		Keys:   keys,
+		Policy: Policy{"/svc/Read": {RolePassenger, RoleAdmin}, "/svc/Admin": {RoleAdmin}},
+		Now:    func() time.Time { return now },
+	}
+	token, err := keys.Sign(passenger())
+	require.NoError(t, err, "error signing token")
+
+	call := func(method, authorization string) (*Claims, error) {
+		ctx := context.Background()
+		if authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+		}
+		var claims *Claims
+		_, err := a.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, req any) (any, error) {
+				claims, _ = FromContext(ctx)
+				return nil, nil
+			})
+		return claims, err
+	}
+
+	claims, err := call("/svc/Read", "Bearer "+token)
+	require.NoError(t, err, "error calling with a valid token")
+	assert.Equal(t, "john", claims.Subject)
+
+	_, err = call("/svc/Read", "")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "missing token")
+	_, err = call("/svc/Read", "Basic "+token)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "wrong scheme")
+	_, err = call("/svc/Admin", "Bearer "+token)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "role not in policy")
+	_, err = call("/svc/Unlisted", "Bearer "+token)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "method not in policy")
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"test_train/trainerr"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Policy maps full gRPC method names to the roles allowed to call them.
+// Methods missing from the policy are denied to everyone.
+type Policy map[string][]Role
+
+// Authenticator identifies the caller of each call and enforces a Policy
+// before the handler runs. Callers present a bearer token signed by Keys or,
+// if ClientCerts is set, a client certificate verified by the TLS layer. A
+// token takes precedence over the certificate of the connection it is sent
+// on.
+type Authenticator struct {
+	Keys        *KeySet
+	ClientCerts bool
+	Policy      Policy
+	Now         func() time.Time
+}
+
+// authenticate returns ctx carrying the caller's claims, or an
+// Unauthenticated or PermissionDenied status.
+func (a *Authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	claims, err := a.claims(ctx)
+	if err != nil {
+		return nil, trainerr.Unauthenticated("%v", err)
+	}
+	if !slices.Contains(a.Policy[method], claims.Role) {
+		return nil, trainerr.PermissionDenied("role %s may not call %s", claims.Role, method)
+	}
+	return NewContext(ctx, claims), nil
+}
+
+func (a *Authenticator) claims(ctx context.Context) (*Claims, error) {
+	token, err := bearerToken(ctx)
+	switch {
+	case errors.Is(err, errNoToken) && a.ClientCerts:
+		return certificateClaims(ctx, a.Now())
+	case err != nil:
+		return nil, err
+	case a.Keys == nil:
+		return nil, errors.New("bearer tokens are not accepted")
+	}
+	return a.Keys.Verify(token, a.Now())
+}
+
+var errNoToken = errors.New("missing bearer token")
+
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", errNoToken
+	}
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", errors.New("malformed authorization header")
+	}
+	return token, nil
+}
+
+// UnaryServerInterceptor authenticates unary calls.
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates streaming calls.
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context { return s.ctx }
+
+// BearerToken attaches token to every call made over a connection.
+type BearerToken string
+
+func (t BearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity is false so tokens also work against servers
+// running without TLS, such as a local development server.
+func (t BearerToken) RequireTransportSecurity() bool { return false }
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// CertReloader serves a certificate, key and optional client CA bundle from
+// files, reloading them when they change so certificates can be rotated
+// without restarting the server. The files are checked on every handshake;
+// if a reload fails, for example because only the new certificate has been
+// written so far, the previous files stay in use.
+type CertReloader struct {
+	certFile, keyFile, caFile string
+
+	mu        sync.Mutex
+	version   string // modification times of the loaded files
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// NewCertReloader loads certFile and keyFile, and caFile if it is not
+// empty, failing if any of them cannot be used.
+func NewCertReloader(certFile, keyFile, caFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *CertReloader) files() []string {
+	if r.caFile == "" {
+		return []string{r.certFile, r.keyFile}
+	}
+	return []string{r.certFile, r.keyFile, r.caFile}
+}
+
+// reload reads the files again if any has been modified since they were
+// last loaded.
+func (r *CertReloader) reload() error {
+	var version string
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		version += info.ModTime().String() + "|"
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if version == r.version {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		if clientCAs, err = loadCertPool(r.caFile); err != nil {
+			return err
+		}
+	}
+	r.version, r.cert, r.clientCAs = version, &cert, clientCAs
+	return nil
+}
+
+func (r *CertReloader) current() (*tls.Certificate, *x509.CertPool) {
+	if err := r.reload(); err != nil {
+		log.Printf("Keeping previous TLS certificate: %v", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, r.clientCAs
+}
+
+// ServerConfig returns a TLS configuration that picks up the current files
+// for each connection. With a client CA bundle, client certificates are
+// verified when presented, and required if requireClientCert is set.
+func (r *CertReloader) ServerConfig(requireClientCert bool) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, clientCAs := r.current()
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				NextProtos:   []string{"h2"},
+				ClientAuth:   tls.NoClientCert,
+			}
+			if clientCAs != nil {
+				config.ClientCAs = clientCAs
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+				if requireClientCert {
+					config.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+			}
+			return config, nil
+		},
+	}
+}
+
+// ClientConfig returns a TLS configuration trusting the CAs in caFile, or
+// the system roots if it is empty, and presenting the client certificate in
+// certFile and keyFile if they are set.
+func ClientConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: serverName}
+	if caFile != "" {
+		roots, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = roots
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client key pair: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: no PEM certificates found", path)
+	}
+	return pool, nil
+}
+
+// ClaimsFromCertificate maps a verified client certificate to claims. The
+// subject is the certificate's common name, the email its first email
+// address, and the role the first organizational unit naming a role, or
+// passenger if none does. The claims expire with the certificate.
+func ClaimsFromCertificate(cert *x509.Certificate) (*Claims, error) {
+	if cert.Subject.CommonName == "" {
+		return nil, errors.New("client certificate has no common name")
+	}
+	claims := &Claims{
+		Issuer:    cert.Issuer.CommonName,
+		Subject:   cert.Subject.CommonName,
+		Role:      RolePassenger,
+		IssuedAt:  cert.NotBefore.Unix(),
+		ExpiresAt: cert.NotAfter.Unix(),
+	}
+	if len(cert.EmailAddresses) > 0 {
+		claims.Email = cert.EmailAddresses[0]
+	}
+	if i := slices.IndexFunc(cert.Subject.OrganizationalUnit, func(ou string) bool { return roles[Role(ou)] }); i >= 0 {
+		claims.Role = Role(cert.Subject.OrganizationalUnit[i])
+	}
+	return claims, nil
+}
+
+// peerCertificate returns the verified client certificate of the
+// connection in ctx, if there is one.
+func peerCertificate(ctx context.Context) (*x509.Certificate, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	return info.State.VerifiedChains[0][0], true
+}
+
+// certificateClaims is the fallback credential for calls without a bearer
+// token, used when the Authenticator accepts client certificates.
+func certificateClaims(ctx context.Context, now time.Time) (*Claims, error) {
+	cert, ok := peerCertificate(ctx)
+	if !ok {
+		return nil, errors.New("missing bearer token or client certificate")
+	}
+	if now.After(cert.NotAfter) {
+		return nil, ErrExpiredToken
+	}
+	return ClaimsFromCertificate(cert)
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"testing"
+	"time"
+
+	"test_train/auth/authtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// servedCert returns the certificate config offers to a new connection.
+func servedCert(t *testing.T, config *tls.Config) []byte {
+	t.Helper()
+	conn, err := config.GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err, "error building connection config")
+	return conn.Certificates[0].Certificate[0]
+}
+
+// touch moves the modification time of files forward so a rewrite within
+// the file system's timestamp resolution is still noticed.
+func touch(t *testing.T, at time.Time, files ...string) {
+	for _, file := range files {
+		require.NoError(t, os.Chtimes(file, at, at))
+	}
+}
+
+func TestCertReloaderPicksUpRotatedCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := authtest.NewCA(t, "test CA")
+	first := ca.Server(t)
+	certFile, keyFile := first.Write(t, dir, "server")
+
+	reloader, err := NewCertReloader(certFile, keyFile, ca.WriteCA(t, dir))
+	require.NoError(t, err, "error loading certificates")
+	config := reloader.ServerConfig(true)
+	firstDER := servedCert(t, config)
+
+	second := ca.Server(t)
+	second.Write(t, dir, "server")
+	touch(t, time.Now().Add(time.Minute), certFile, keyFile)
+	secondDER := servedCert(t, config)
+	assert.False(t, bytes.Equal(firstDER, secondDER), "rotated certificate should be served")
+
+	// A half-finished rotation keeps the last good pair in use
+	require.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+	touch(t, time.Now().Add(2*time.Minute), certFile)
+	assert.Equal(t, secondDER, servedCert(t, config))
+
+	conn, err := config.GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err, "error building connection config")
+	assert.Equal(t, tls.RequireAndVerifyClientCert, conn.ClientAuth)
+	assert.Equal(t, []string{"h2"}, conn.NextProtos)
+}
+
+func TestNewCertReloaderRejectsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	ca := authtest.NewCA(t, "test CA")
+	certFile, keyFile := ca.Server(t).Write(t, dir, "server")
+
+	_, err := NewCertReloader(certFile, certFile, "")
+	assert.Error(t, err, "certificate used as key")
+	_, err = NewCertReloader(certFile, keyFile, keyFile)
+	assert.Error(t, err, "key used as CA bundle")
+	_, err = NewCertReloader(certFile, keyFile, dir+"/missing.pem")
+	assert.Error(t, err, "missing CA bundle")
+}
+
+func TestClaimsFromCertificate(t *testing.T) {
+	ca := authtest.NewCA(t, "test CA")
+	parse := func(c authtest.Cert) *x509.Certificate {
+		pair, err := tls.X509KeyPair(c.CertPEM, c.KeyPEM)
+		require.NoError(t, err, "error parsing key pair")
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		require.NoError(t, err, "error parsing certificate")
+		return cert
+	}
+
+	claims, err := ClaimsFromCertificate(parse(ca.Client(t, "john", "john.doe@example.com")))
+	require.NoError(t, err, "error mapping certificate")
+	assert.Equal(t, "john", claims.Subject)
+	assert.Equal(t, "john.doe@example.com", claims.Email)
+	assert.Equal(t, RolePassenger, claims.Role)
+	assert.Equal(t, "test CA", claims.Issuer)
+
+	claims, err = ClaimsFromCertificate(parse(ca.Client(t, "gate-3", "", "platforms", "conductor")))
+	require.NoError(t, err, "error mapping certificate")
+	assert.Equal(t, RoleConductor, claims.Role, "role should come from the first unit naming one")
+
+	_, err = ClaimsFromCertificate(parse(ca.Client(t, "", "")))
+	assert.Error(t, err, "certificate without a common name")
+}
@@ -1,82 +1,188 @@
+// Command client is a command-line client for TrainService. Run it without
+// arguments for the list of commands.
 package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
 
-	pb "test_train/protobuf" // Adjust according to your protobuf path
+	"test_train/auth"
+	"test_train/trainclient"
+	"test_train/trainerr"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
-func main() {
-	// Set up connection to the server
-	//conn, err := grpc.Dial("localhost:8111", grpc.WithInsecure())
-	conn, err := grpc.NewClient("localhost:8111", grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Fatalf("failed to connect: %v", err)
+// Exit statuses. A failed call exits with grpcExitBase plus its gRPC code,
+// so scripts can tell, for example, NotFound (15) from Unavailable (24).
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitUsage    = 2
+	grpcExitBase = 10
+)
+
+// exitCode returns the exit status for an error returned by a command.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
 	}
-	defer conn.Close()
+	if s, ok := status.FromError(err); ok && s.Code() != codes.OK {
+		return grpcExitBase + int(s.Code())
+	}
+	return exitFailure
+}
 
-	// Create a new client
-	client := pb.NewTrainServiceClient(conn)
+// options are the flags that come before the command.
+type options struct {
+	addr           string
+	token          string
+	useTLS         bool
+	caFile         string
+	certFile       string
+	keyFile        string
+	serverName     string
+	timeout        time.Duration
+	output         string
+	idempotencyKey string
+}
 
-	// Test PurchaseTicket
-	user := &pb.User{
-		FirstName: "John",
-		LastName:  "Doe",
-		Email:     "john.doe@example.com",
+func (o *options) define(fs *flag.FlagSet) {
+	fs.StringVar(&o.addr, "addr", envOr("TRAIN_ADDR", "localhost:8111"), "server address (env TRAIN_ADDR)")
+	fs.StringVar(&o.token, "token", os.Getenv("TRAIN_TOKEN"), "bearer token for servers that require authentication (env TRAIN_TOKEN)")
+	fs.BoolVar(&o.useTLS, "tls", false, "connect over TLS, trusting the system roots unless -ca is set")
+	fs.StringVar(&o.caFile, "ca", "", "PEM CA bundle for verifying the server; implies -tls")
+	fs.StringVar(&o.certFile, "cert", "", "PEM client certificate for mutual TLS; implies -tls")
+	fs.StringVar(&o.keyFile, "key", "", "PEM private key of -cert")
+	fs.StringVar(&o.serverName, "server-name", "", "name to verify the server certificate against, if not the dialled host")
+	fs.DurationVar(&o.timeout, "timeout", 10*time.Second, "deadline for each call; streaming commands only use it if set")
+	fs.StringVar(&o.output, "o", "table", "output format: table, json or yaml")
+	fs.StringVar(&o.idempotencyKey, "idempotency-key", "", "key that makes rerunning a change return its first result; generated per run if empty")
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
 	}
-	req := &pb.PurchaseTicketRequest{
-		User: user,
-		From: "London",
-		To:   "France",
+	return fallback
+}
+
+// dial connects to the server described by o.
+func (o *options) dial() (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if o.useTLS || o.caFile != "" || o.certFile != "" {
+		config, err := auth.ClientConfig(o.caFile, o.certFile, o.keyFile, o.serverName)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS configuration: %w", err)
+		}
+		creds = credentials.NewTLS(config)
 	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if o.token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.BearerToken(o.token)))
+	}
+	return grpc.NewClient(o.addr, opts...)
+}
 
-	// Call PurchaseTicket
-	receipt, err := client.PurchaseTicket(context.Background(), req)
-	if err != nil {
-		log.Fatalf("Error purchasing ticket: %v", err)
+func usage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintf(w, "Usage: client [flags] <command> [command flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-18s %s\n", cmd.name, cmd.summary)
 	}
-	fmt.Printf("Ticket purchased: %+v\n", receipt)
+	fmt.Fprintf(w, "\nRun client <command> -h for the flags of a command.\n\nFlags:\n")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
 
-	// Test GetReceipt
-	receiptReq := &pb.UserRequest{
-		Email: user.Email,
+// run executes the command line args and returns the exit status.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	var opts options
+	global := flag.NewFlagSet("client", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { usage(global, stderr) }
+	opts.define(global)
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
 	}
-	receiptResp, err := client.GetReceipt(context.Background(), receiptReq)
-	if err != nil {
-		log.Fatalf("Error getting receipt: %v", err)
+	if global.NArg() == 0 {
+		usage(global, stderr)
+		return exitUsage
 	}
-	fmt.Printf("Ticket receipt for %s: %+v\n", user.Email, receiptResp)
+	timeoutSet := false
+	global.Visit(func(f *flag.Flag) { timeoutSet = timeoutSet || f.Name == "timeout" })
 
-	// Test GetUsersBySection for Section A
-	sectionReq := &pb.SectionRequest{Section: "A"}
-	usersResp, err := client.GetUsersBySection(context.Background(), sectionReq)
-	if err != nil {
-		log.Fatalf("Error getting users by section: %v", err)
+	cmd := findCommand(global.Arg(0))
+	if cmd == nil {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", global.Arg(0))
+		usage(global, stderr)
+		return exitUsage
 	}
-	fmt.Printf("Users in Section A: %+v\n", usersResp)
-
-	// Test ModifyUserSeat
-	modifyReq := &pb.ModifySeatRequest{
-		Email:      user.Email,
-		NewSection: "B",
-		NewSeat:    2,
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: client [flags] %s [flags]\n\n%s.\n\nFlags:\n", cmd.name, strings.ToUpper(cmd.summary[:1])+cmd.summary[1:])
+		fs.PrintDefaults()
 	}
-	modifiedReceipt, err := client.ModifyUserSeat(context.Background(), modifyReq)
-	if err != nil {
-		log.Fatalf("Error modifying user seat: %v", err)
+	runCmd := cmd.setup(fs)
+	if err := fs.Parse(global.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "%s: unexpected arguments %q\n", cmd.name, fs.Args())
+		return exitUsage
 	}
-	fmt.Printf("User seat modified: %+v\n", modifiedReceipt)
 
-	// Test RemoveUser
-	removeResp, err := client.RemoveUser(context.Background(), receiptReq)
+	out, err := newPrinter(stdout, opts.output)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	conn, err := opts.dial()
 	if err != nil {
-		log.Fatalf("Error removing user: %v", err)
+		fmt.Fprintf(stderr, "failed to connect: %v\n", err)
+		return exitFailure
 	}
-	fmt.Printf("User removed: %+v\n", removeResp)
+	defer conn.Close()
 
+	if !cmd.streaming || timeoutSet {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	if opts.idempotencyKey != "" {
+		ctx = trainclient.WithIdempotencyKey(ctx, opts.idempotencyKey)
+	}
+	if err := runCmd(ctx, trainclient.New(conn), out); err != nil {
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintf(stderr, "%s: %v\n", cmd.name, err)
+			return exitUsage
+		}
+		fmt.Fprintf(stderr, "%s: %s\n", cmd.name, trainerr.Describe(err))
+		return exitCode(err)
+	}
+	return exitOK
 }
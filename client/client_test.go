@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeTrainService answers a few RPCs with canned responses and records
+// what it was sent.
+type fakeTrainService struct {
+	pb.UnimplementedTrainServiceServer
+	purchase      *pb.PurchaseTicketRequest
+	authorization []string
+}
+
+func (f *fakeTrainService) PurchaseTicket(ctx context.Context, req *pb.PurchaseTicketRequest) (*pb.TicketReceipt, error) {
+	f.purchase = req
+	md, _ := metadata.FromIncomingContext(ctx)
+	f.authorization = md.Get("authorization")
+	return &pb.TicketReceipt{
+		User:             req.User,
+		From:             req.From,
+		To:               req.To,
+		Seat:             &pb.SeatAllocation{Section: "A", Seat: 1},
+		BookingReference: "ABC123",
+		PassengerType:    req.PassengerType,
+	}, nil
+}
+
+func (f *fakeTrainService) GetUsersBySection(ctx context.Context, req *pb.SectionRequest) (*pb.UsersResponse, error) {
+	return &pb.UsersResponse{Users: []*pb.User{
+		{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"},
+		{FirstName: "Jane", LastName: "Smith", Email: "jane.smith@example.com"},
+	}}, nil
+}
+
+func (f *fakeTrainService) GetReceipt(ctx context.Context, req *pb.UserRequest) (*pb.TicketReceipt, error) {
+	return nil, trainerr.NotFound(trainerr.ResourceUser, req.Email, "user not found")
+}
+
+// startFake serves a fakeTrainService on a loopback port.
+func startFake(t *testing.T) (*fakeTrainService, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "error listening")
+	fake := &fakeTrainService{}
+	server := grpc.NewServer()
+	pb.RegisterTrainServiceServer(server, fake)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return fake, listener.Addr().String()
+}
+
+// runCLI runs the client with args and returns its exit status and output.
+func runCLI(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestBuy(t *testing.T) {
+	fake, addr := startFake(t)
+
+	code, stdout, stderr := runCLI("-addr", addr, "-token", "secret", "buy",
+		"-email", "john.doe@example.com", "-first-name", "John", "-last-name", "Doe",
+		"-from", "London", "-to", "France", "-passenger-type", "child", "-position", "window")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, pb.PassengerType_PASSENGER_TYPE_CHILD, fake.purchase.PassengerType)
+	assert.Equal(t, pb.SeatPosition_SEAT_POSITION_WINDOW, fake.purchase.Preferences.GetPosition())
+	assert.Equal(t, []string{"Bearer secret"}, fake.authorization)
+	assert.Contains(t, stdout, "booking_reference  ABC123")
+	assert.Contains(t, stdout, "passenger_type     CHILD")
+
+	_, _, stderr = runCLI("-addr", addr, "buy", "-email", "john.doe@example.com")
+	assert.Empty(t, stderr)
+	assert.Nil(t, fake.purchase.Preferences, "no preference flags should send no preferences")
+}
+
+func TestOutputFormats(t *testing.T) {
+	_, addr := startFake(t)
+
+	code, stdout, _ := runCLI("-addr", addr, "list-section", "-section", "A")
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, strings.Join([]string{
+		"FIRST_NAME  LAST_NAME  EMAIL",
+		"John        Doe        john.doe@example.com",
+		"Jane        Smith      jane.smith@example.com",
+		"",
+	}, "\n"), stdout)
+
+	code, stdout, _ = runCLI("-addr", addr, "-o", "json", "list-section", "-section", "A")
+	require.Equal(t, exitOK, code)
+	assert.True(t, strings.HasPrefix(stdout, "{\n  \"users\": [\n    {\n      \"firstName\": \"John\","), stdout)
+
+	code, stdout, _ = runCLI("-addr", addr, "-o", "yaml", "buy", "-email", "john.doe@example.com", "-from", "London")
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, strings.Join([]string{
+		"user:",
+		"  email: john.doe@example.com",
+		"from: London",
+		"seat:",
+		"  section: A",
+		"  seat: 1",
+		"bookingReference: ABC123",
+		"",
+	}, "\n"), stdout)
+}
+
+func TestExitCodes(t *testing.T) {
+	_, addr := startFake(t)
+
+	code, _, stderr := runCLI("-addr", addr, "receipt", "-email", "nobody@example.com")
+	assert.Equal(t, grpcExitBase+int(codes.NotFound), code)
+	assert.Contains(t, stderr, "receipt: NotFound: user not found")
+
+	code, _, _ = runCLI("-addr", addr, "cancel", "-email", "john.doe@example.com")
+	assert.Equal(t, grpcExitBase+int(codes.Unimplemented), code)
+
+	code, _, _ = runCLI("-addr", addr, "board-train")
+	assert.Equal(t, exitUsage, code, "unknown command")
+	code, _, _ = runCLI("-addr", addr, "buy", "-passenger-type", "pensioner")
+	assert.Equal(t, exitUsage, code, "bad enum value")
+	code, _, _ = runCLI("-addr", addr, "-o", "xml", "departures")
+	assert.Equal(t, exitUsage, code, "unknown output format")
+	code, _, _ = runCLI("-addr", addr, "buy-group", "-passenger", "john.doe@example.com")
+	assert.Equal(t, exitUsage, code, "incomplete passenger")
+	code, _, _ = runCLI()
+	assert.Equal(t, exitUsage, code, "no command")
+
+	assert.Equal(t, exitOK, exitCode(nil))
+	assert.Equal(t, 24, exitCode(status.Error(codes.Unavailable, "down")))
+	assert.Equal(t, exitFailure, exitCode(assert.AnError))
+}
+
+func TestEveryRPCHasACommand(t *testing.T) {
+	// Each command calls exactly one RPC; make sure none is left out
+	assert.Len(t, commands, len(pb.TrainService_ServiceDesc.Methods)+len(pb.TrainService_ServiceDesc.Streams))
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	pb "test_train/protobuf"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// runner performs a command once its flags are parsed.
+type runner func(ctx context.Context, c pb.TrainServiceClient, out *printer) error
+
+// command is a subcommand. setup defines its flags, filling in the request
+// as they are parsed, and returns the function that sends it.
+type command struct {
+	name      string
+	summary   string
+	streaming bool
+	setup     func(fs *flag.FlagSet) runner
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// usageError is a mistake on the command line found after flag parsing.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func usagef(format string, args ...any) error {
+	return usageError(fmt.Sprintf(format, args...))
+}
+
+var commands = []command{
+	{name: "buy", summary: "buy a ticket", setup: func(fs *flag.FlagSet) runner {
+		req := purchaseFlags(fs)
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.PurchaseTicket(ctx, req.build()))
+		}
+	}},
+	{name: "buy-group", summary: "buy tickets for a group under one booking reference", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.PurchaseGroupTicketRequest{}
+		var passengers stringList
+		fs.Var(&passengers, "passenger", "passenger as email,first name,last name[,adult|child|senior]; repeat for each")
+		fs.StringVar(&req.From, "from", "", "origin station")
+		fs.StringVar(&req.To, "to", "", "destination station")
+		fs.StringVar(&req.DepartureId, "departure", "", "departure id; empty for the default departure")
+		fs.StringVar(&req.PaymentToken, "payment-token", "", "card token charged once for the group")
+		prefs := preferenceFlags(fs)
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			for _, p := range passengers {
+				passenger, err := parsePassenger(p)
+				if err != nil {
+					return err
+				}
+				req.Passengers = append(req.Passengers, passenger)
+			}
+			req.Preferences = prefs.build()
+			return out.result(c.PurchaseGroupTicket(ctx, req))
+		}
+	}},
+	{name: "receipt", summary: "show a ticket", setup: func(fs *flag.FlagSet) runner {
+		req := ticketFlags(fs)
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.GetReceipt(ctx, req))
+		}
+	}},
+	{name: "tickets", summary: "list every ticket of a passenger", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.UserRequest{}
+		fs.StringVar(&req.Email, "email", "", "passenger email")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.ListTicketsForUser(ctx, req))
+		}
+	}},
+	{name: "list-section", summary: "list the passengers seated in a section", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.SectionRequest{}
+		fs.StringVar(&req.Section, "section", "", "section name, such as A")
+		fs.StringVar(&req.DepartureId, "departure", "", "departure id; empty for the default departure")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.GetUsersBySection(ctx, req))
+		}
+	}},
+	{name: "move", summary: "move a passenger to another seat", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.ModifySeatRequest{}
+		fs.StringVar(&req.Email, "email", "", "passenger email")
+		fs.StringVar(&req.BookingReference, "booking", "", "booking reference")
+		fs.StringVar(&req.NewSection, "section", "", "new section")
+		int32Var(fs, &req.NewSeat, "seat", "new seat number")
+		fs.BoolVar(&req.AnySeat, "any-seat", false, "take the lowest free seat in -section")
+		fs.StringVar(&req.DepartureId, "departure", "", "departure of the new seat; empty keeps the ticket's departure")
+		fs.Int64Var(&req.ExpectedVersion, "version", 0, "fail if the ticket has changed since this version was read; 0 skips the check")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.ModifyUserSeat(ctx, req))
+		}
+	}},
+	{name: "swap", summary: "consent to swap seats with another ticket", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.SwapSeatsRequest{}
+		fs.StringVar(&req.Email, "email", "", "your email")
+		fs.StringVar(&req.BookingReference, "booking", "", "your booking reference")
+		fs.StringVar(&req.OtherEmail, "other-email", "", "email of the passenger to swap with")
+		fs.StringVar(&req.OtherBookingReference, "other-booking", "", "booking reference to swap with")
+		fs.Int64Var(&req.ExpectedVersion, "version", 0, "fail if your ticket has changed since this version was read; 0 skips the check")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.SwapSeats(ctx, req))
+		}
+	}},
+	{name: "cancel", summary: "cancel a ticket and refund it under the refund policy", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.CancelTicketRequest{}
+		fs.StringVar(&req.Email, "email", "", "passenger email")
+		fs.StringVar(&req.BookingReference, "booking", "", "booking reference")
+		fs.StringVar(&req.Reason, "reason", "", "reason recorded with the cancellation")
+		fs.Int64Var(&req.ExpectedVersion, "version", 0, "fail if the ticket has changed since this version was read; 0 skips the check")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.CancelTicket(ctx, req))
+		}
+	}},
+	{name: "remove", summary: "remove a passenger from the train (admin)", setup: func(fs *flag.FlagSet) runner {
+		req := ticketFlags(fs)
+		fs.Int64Var(&req.ExpectedVersion, "version", 0, "fail if the ticket has changed since this version was read; 0 skips the check")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.RemoveUser(ctx, req))
+		}
+	}},
+	{name: "history", summary: "show the change history of a booking", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.BookingHistoryRequest{}
+		fs.StringVar(&req.BookingReference, "booking", "", "booking reference")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.GetBookingHistory(ctx, req))
+		}
+	}},
+	{name: "quote", summary: "quote a fare without booking", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.QuoteFareRequest{}
+		fs.StringVar(&req.DepartureId, "departure", "", "departure id; empty for the default departure")
+		fs.StringVar(&req.From, "from", "", "origin station")
+		fs.StringVar(&req.To, "to", "", "destination station")
+		enumVar(fs, &req.PassengerType, "passenger-type", "adult, child or senior")
+		fs.StringVar(&req.SeatClass, "class", "", "seat class")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.QuoteFare(ctx, req))
+		}
+	}},
+	{name: "seat-map", summary: "show which seats are free, held or sold", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.SeatMapRequest{}
+		fs.StringVar(&req.DepartureId, "departure", "", "departure id; empty for the default departure")
+		fs.StringVar(&req.From, "from", "", "origin station of the leg; empty for the whole route")
+		fs.StringVar(&req.To, "to", "", "destination station of the leg")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.GetSeatMap(ctx, req))
+		}
+	}},
+	{name: "watch", summary: "stream seat availability changes until interrupted", streaming: true, setup: func(fs *flag.FlagSet) runner {
+		req := &pb.WatchAvailabilityRequest{}
+		fs.StringVar(&req.DepartureId, "departure", "", "departure id; empty for the default departure")
+		fs.StringVar(&req.From, "from", "", "origin station of the leg; empty for the whole route")
+		fs.StringVar(&req.To, "to", "", "destination station of the leg")
+		fs.Uint64Var(&req.Cursor, "cursor", 0, "resume after this cursor instead of starting with a snapshot")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			stream, err := c.WatchAvailability(ctx, req)
+			if err != nil {
+				return err
+			}
+			for {
+				event, err := stream.Recv()
+				switch {
+				case errors.Is(err, io.EOF):
+					return nil
+				case status.Code(err) == codes.Canceled && ctx.Err() != nil:
+					// Interrupted by the user
+					return nil
+				case err != nil:
+					return err
+				}
+				if err := out.print(event); err != nil {
+					return err
+				}
+			}
+		}
+	}},
+	{name: "hold", summary: "hold a seat while paying", setup: func(fs *flag.FlagSet) runner {
+		req := purchaseFlags(fs)
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.HoldSeat(ctx, req.build()))
+		}
+	}},
+	{name: "confirm", summary: "buy a held seat", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.HoldRequest{}
+		fs.StringVar(&req.Token, "hold", "", "hold token")
+		fs.StringVar(&req.PaymentToken, "payment-token", "", "card token; empty uses the one given when holding")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.ConfirmHold(ctx, req))
+		}
+	}},
+	{name: "waitlist-join", summary: "join the waitlist of a full departure", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.WaitlistRequest{User: &pb.User{}}
+		userFlags(fs, req.User)
+		fs.StringVar(&req.DepartureId, "departure", "", "departure id; empty for the default departure")
+		fs.StringVar(&req.From, "from", "", "origin station")
+		fs.StringVar(&req.To, "to", "", "destination station")
+		enumVar(fs, &req.PassengerType, "passenger-type", "adult, child or senior")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.JoinWaitlist(ctx, req))
+		}
+	}},
+	{name: "waitlist-status", summary: "show a waitlist entry and any seat offered to it", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.WaitlistEntryRequest{}
+		fs.StringVar(&req.EntryId, "entry", "", "waitlist entry id")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.GetWaitlistPosition(ctx, req))
+		}
+	}},
+	{name: "waitlist-leave", summary: "leave a waitlist", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.WaitlistEntryRequest{}
+		fs.StringVar(&req.EntryId, "entry", "", "waitlist entry id")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.LeaveWaitlist(ctx, req))
+		}
+	}},
+	{name: "departures", summary: "list departures", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.ListDeparturesRequest{}
+		fs.StringVar(&req.TrainId, "train", "", "only departures of this train")
+		fs.StringVar(&req.Date, "date", "", "only departures on this date (YYYY-MM-DD)")
+		fs.BoolVar(&req.IncludeCancelled, "all", false, "include cancelled departures")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.ListDepartures(ctx, req))
+		}
+	}},
+	{name: "create-departure", summary: "schedule a departure (admin)", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.CreateDepartureRequest{}
+		var via stringList
+		var segmentKm int32List
+		var sectionsFile string
+		fs.StringVar(&req.TrainId, "train", "", "train id")
+		fs.StringVar(&req.Date, "date", "", "date (YYYY-MM-DD)")
+		fs.StringVar(&req.Time, "time", "", "departure time (HH:MM, UTC)")
+		fs.StringVar(&req.Origin, "origin", "", "origin station")
+		fs.StringVar(&req.Destination, "destination", "", "destination station")
+		fs.Var(&via, "via", "intermediate calling point; repeat in order")
+		fs.Var(&segmentKm, "segment-km", "length of each segment in km; repeat in order")
+		fs.StringVar(&req.Layout, "layout", "", "named layout from the server's layout file")
+		fs.StringVar(&sectionsFile, "sections", "", "JSON file with a {\"sections\": [...]} layout, instead of -layout")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			req.Via, req.SegmentKm = via, segmentKm
+			if sectionsFile != "" {
+				data, err := os.ReadFile(sectionsFile)
+				if err != nil {
+					return usagef("%v", err)
+				}
+				var layout pb.CreateDepartureRequest
+				if err := protojson.Unmarshal(data, &layout); err != nil {
+					return usagef("%s: %v", sectionsFile, err)
+				}
+				req.Sections = layout.Sections
+			}
+			return out.result(c.CreateDeparture(ctx, req))
+		}
+	}},
+	{name: "cancel-departure", summary: "cancel a departure so no more tickets are sold (admin)", setup: func(fs *flag.FlagSet) runner {
+		req := &pb.DepartureRequest{}
+		fs.StringVar(&req.DepartureId, "departure", "", "departure id")
+		return func(ctx context.Context, c pb.TrainServiceClient, out *printer) error {
+			return out.result(c.CancelDeparture(ctx, req))
+		}
+	}},
+}
+
+func userFlags(fs *flag.FlagSet, user *pb.User) {
+	fs.StringVar(&user.Email, "email", "", "passenger email")
+	fs.StringVar(&user.FirstName, "first-name", "", "passenger first name")
+	fs.StringVar(&user.LastName, "last-name", "", "passenger last name")
+}
+
+func ticketFlags(fs *flag.FlagSet) *pb.UserRequest {
+	req := &pb.UserRequest{}
+	fs.StringVar(&req.Email, "email", "", "passenger email")
+	fs.StringVar(&req.BookingReference, "booking", "", "booking reference")
+	return req
+}
+
+// purchase is a PurchaseTicketRequest being filled in from flags.
+type purchase struct {
+	req   *pb.PurchaseTicketRequest
+	prefs *preferences
+}
+
+func purchaseFlags(fs *flag.FlagSet) *purchase {
+	req := &pb.PurchaseTicketRequest{User: &pb.User{}}
+	userFlags(fs, req.User)
+	fs.StringVar(&req.From, "from", "", "origin station")
+	fs.StringVar(&req.To, "to", "", "destination station")
+	fs.StringVar(&req.DepartureId, "departure", "", "departure id; empty for the default departure")
+	enumVar(fs, &req.PassengerType, "passenger-type", "adult, child or senior")
+	fs.StringVar(&req.PaymentToken, "payment-token", "", "card token for the payment provider")
+	return &purchase{req: req, prefs: preferenceFlags(fs)}
+}
+
+func (p *purchase) build() *pb.PurchaseTicketRequest {
+	p.req.Preferences = p.prefs.build()
+	return p.req
+}
+
+type preferences struct {
+	prefs pb.SeatPreferences
+}
+
+func preferenceFlags(fs *flag.FlagSet) *preferences {
+	p := &preferences{}
+	enumVar(fs, &p.prefs.Position, "position", "preferred seat position: window or aisle")
+	fs.StringVar(&p.prefs.Section, "prefer-section", "", "preferred section")
+	fs.BoolVar(&p.prefs.ForwardFacing, "forward-facing", false, "prefer a forward-facing seat")
+	fs.BoolVar(&p.prefs.QuietCoach, "quiet", false, "prefer the quiet coach")
+	fs.BoolVar(&p.prefs.NearAccessibleToilet, "near-toilet", false, "prefer a seat near the accessible toilet")
+	fs.StringVar(&p.prefs.CompanionBookingReference, "companion", "", "sit near the passengers of this booking")
+	fs.BoolVar(&p.prefs.Strict, "strict", false, "fail rather than ignore preferences that cannot be met")
+	return p
+}
+
+// build returns the preferences, or nil if none were given.
+func (p *preferences) build() *pb.SeatPreferences {
+	if proto.Equal(&p.prefs, &pb.SeatPreferences{}) {
+		return nil
+	}
+	return proto.Clone(&p.prefs).(*pb.SeatPreferences)
+}
+
+// parsePassenger parses a -passenger value of buy-group.
+func parsePassenger(s string) (*pb.GroupPassenger, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) < 3 || len(parts) > 4 {
+		return nil, usagef("passenger %q: want email,first name,last name[,type]", s)
+	}
+	passenger := &pb.GroupPassenger{User: &pb.User{
+		Email:     strings.TrimSpace(parts[0]),
+		FirstName: strings.TrimSpace(parts[1]),
+		LastName:  strings.TrimSpace(parts[2]),
+	}}
+	if len(parts) == 4 {
+		if err := setEnum(&passenger.PassengerType, strings.TrimSpace(parts[3])); err != nil {
+			return nil, usagef("passenger %q: %v", s, err)
+		}
+	}
+	return passenger, nil
+}
+
+// protoEnum is a generated enum type.
+type protoEnum interface {
+	~int32
+	Descriptor() protoreflect.EnumDescriptor
+}
+
+// setEnum parses s as a value of the enum *e by its full name or by the
+// name without the enum's prefix, in any case: "child" for
+// PASSENGER_TYPE_CHILD.
+func setEnum[E protoEnum](e *E, s string) error {
+	desc := (*e).Descriptor()
+	name := strings.ToUpper(strings.ReplaceAll(s, "-", "_"))
+	for _, candidate := range []string{name, enumPrefix(desc) + name} {
+		if v := desc.Values().ByName(protoreflect.Name(candidate)); v != nil {
+			*e = E(v.Number())
+			return nil
+		}
+	}
+	var names []string
+	for i := 0; i < desc.Values().Len(); i++ {
+		names = append(names, strings.ToLower(strings.TrimPrefix(string(desc.Values().Get(i).Name()), enumPrefix(desc))))
+	}
+	return fmt.Errorf("unknown %s %q; want one of %s", desc.Name(), s, strings.Join(names, ", "))
+}
+
+// enumPrefix returns the prefix of an enum's value names, such as
+// PASSENGER_TYPE_ for PassengerType.
+func enumPrefix(desc protoreflect.EnumDescriptor) string {
+	var b strings.Builder
+	for i, r := range string(desc.Name()) {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String()) + "_"
+}
+
+type enumFlag[E protoEnum] struct{ e *E }
+
+func (f enumFlag[E]) String() string {
+	if f.e == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(string((*f.e).Descriptor().Values().ByNumber(protoreflect.EnumNumber(*f.e)).Name()), enumPrefix((*f.e).Descriptor())))
+}
+
+func (f enumFlag[E]) Set(s string) error { return setEnum(f.e, s) }
+
+func enumVar[E protoEnum](fs *flag.FlagSet, e *E, name, usage string) {
+	fs.Var(enumFlag[E]{e}, name, usage)
+}
+
+type int32Flag struct{ v *int32 }
+
+func (f int32Flag) String() string {
+	if f.v == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*f.v))
+}
+
+func (f int32Flag) Set(s string) error {
+	n, err := strconv.ParseInt(s, 10, 32)
+	*f.v = int32(n)
+	return err
+}
+
+func int32Var(fs *flag.FlagSet, v *int32, name, usage string) {
+	fs.Var(int32Flag{v}, name, usage)
+}
+
+// stringList collects every value of a repeated flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// int32List collects every value of a repeated integer flag.
+type int32List []int32
+
+func (l *int32List) String() string { return fmt.Sprint([]int32(*l)) }
+
+func (l *int32List) Set(s string) error {
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, int32(n))
+	return nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// printer writes responses in the format chosen with -o.
+type printer struct {
+	w       io.Writer
+	format  string
+	printed int
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case "table", "json", "yaml":
+		return &printer{w: w, format: format}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q; want table, json or yaml", format)
+}
+
+// result prints msg unless the call that returned it failed.
+func (p *printer) result(msg proto.Message, err error) error {
+	if err != nil {
+		return err
+	}
+	return p.print(msg)
+}
+
+// print writes msg. Successive messages of a stream are separated by a
+// blank line in tables, a newline in JSON and a document marker in YAML.
+func (p *printer) print(msg proto.Message) error {
+	// protojson deliberately varies its whitespace, so its output is
+	// re-encoded to keep ours stable
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	switch p.format {
+	case "json":
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+	case "yaml":
+		node, err := jsonToYAML(json.NewDecoder(bytes.NewReader(data)))
+		if err != nil {
+			return err
+		}
+		if p.printed > 0 {
+			buf.WriteString("---\n")
+		}
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(node); err != nil {
+			return err
+		}
+	case "table":
+		if p.printed > 0 {
+			buf.WriteByte('\n')
+		}
+		writeTable(&buf, msg.ProtoReflect())
+	}
+	p.printed++
+	_, err = p.w.Write(buf.Bytes())
+	return err
+}
+
+// jsonToYAML converts the next JSON value of dec to a YAML node, keeping
+// object keys in their JSON order.
+func jsonToYAML(dec *json.Decoder) (*yaml.Node, error) {
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if v == '[' {
+			node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			child, err := jsonToYAML(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		// Closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}, nil
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: v.String()}, nil
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: v.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+// writeTable writes msg as a table. A message that is just a list, such as
+// the users of a section, gets one row per element; anything else gets one
+// row per field, with nested fields named by their path.
+func writeTable(w io.Writer, msg protoreflect.Message) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+
+	fields := msg.Descriptor().Fields()
+	if fields.Len() == 1 && fields.Get(0).IsList() && fields.Get(0).Message() != nil {
+		list := msg.Get(fields.Get(0)).List()
+		var columns []string
+		seen := make(map[string]bool)
+		rows := make([]map[string]string, list.Len())
+		for i := range rows {
+			rows[i] = make(map[string]string)
+			flatten(list.Get(i).Message(), "", true, func(path, value string) {
+				if !seen[path] {
+					seen[path] = true
+					columns = append(columns, path)
+				}
+				rows[i][path] = value
+			})
+		}
+		if len(rows) == 0 {
+			fmt.Fprintf(tw, "No %s.\n", strings.ReplaceAll(string(fields.Get(0).Name()), "_", " "))
+			return
+		}
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, row := range rows {
+			values := make([]string, len(columns))
+			for i, column := range columns {
+				values[i] = row[column]
+			}
+			fmt.Fprintln(tw, strings.Join(values, "\t"))
+		}
+		return
+	}
+
+	empty := true
+	flatten(msg, "", false, func(path, value string) {
+		empty = false
+		fmt.Fprintf(tw, "%s\t%s\n", path, value)
+	})
+	if empty {
+		fmt.Fprintln(tw, "OK")
+	}
+}
+
+// flatten calls add for each set scalar field of msg, in field order, named
+// by its dotted path. In compact mode lists of messages are summarised by
+// their length rather than expanded.
+func flatten(msg protoreflect.Message, prefix string, compact bool, add func(path, value string)) {
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !msg.Has(fd) {
+			continue
+		}
+		path := prefix + string(fd.Name())
+		v := msg.Get(fd)
+		switch {
+		case fd.IsMap():
+			var keys []string
+			entries := make(map[string]protoreflect.Value)
+			v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				keys = append(keys, k.String())
+				entries[k.String()] = v
+				return true
+			})
+			sort.Strings(keys)
+			for _, k := range keys {
+				add(path+"."+k, scalar(fd.MapValue(), entries[k]))
+			}
+		case fd.IsList() && fd.Message() != nil:
+			if compact {
+				add(path, fmt.Sprintf("%d items", v.List().Len()))
+				continue
+			}
+			for j := 0; j < v.List().Len(); j++ {
+				flatten(v.List().Get(j).Message(), fmt.Sprintf("%s[%d].", path, j), compact, add)
+			}
+		case fd.IsList():
+			values := make([]string, v.List().Len())
+			for j := range values {
+				values[j] = scalar(fd, v.List().Get(j))
+			}
+			add(path, strings.Join(values, ", "))
+		case fd.Message() != nil && fd.Message().FullName() == "google.protobuf.Timestamp":
+			ts := v.Message()
+			fields := fd.Message().Fields()
+			at := time.Unix(ts.Get(fields.ByName("seconds")).Int(), ts.Get(fields.ByName("nanos")).Int())
+			add(path, at.UTC().Format(time.RFC3339))
+		case fd.Message() != nil:
+			flatten(v.Message(), path+".", compact, add)
+		default:
+			add(path, scalar(fd, v))
+		}
+	}
+}
+
+// scalar formats a single value of fd. Enum values drop their type prefix.
+func scalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if value := fd.Enum().Values().ByNumber(v.Enum()); value != nil {
+			return strings.TrimPrefix(string(value.Name()), enumPrefix(fd.Enum()))
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	}
+	return v.String()
+}
@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"test_train/trainerr"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// HTTPStatus returns the HTTP status for a gRPC code, following the mapping
+// in google/rpc/code.proto.
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // Client Closed Request
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// invalidRequest reports an HTTP request that could not be transcoded.
+func invalidRequest(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// statusJSON encodes err as a google.rpc.Status, details included.
+func statusJSON(err error) []byte {
+	data, marshalErr := protojson.Marshal(status.Convert(err).Proto())
+	if marshalErr != nil {
+		data, _ = protojson.Marshal(status.New(codes.Internal, "error encoding status").Proto())
+	}
+	return data
+}
+
+// writeError writes err as a JSON google.rpc.Status with the HTTP status of
+// its code. Retry advice becomes a Retry-After header.
+func writeError(w http.ResponseWriter, err error) {
+	code := status.Code(err)
+	w.Header().Set("Content-Type", "application/json")
+	if code == codes.Unauthenticated {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	if delay, ok := trainerr.RetryDelay(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+	}
+	w.WriteHeader(HTTPStatus(code))
+	w.Write(statusJSON(err))
+}
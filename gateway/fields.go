@@ -0,0 +1,110 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// field returns the field of desc called name, by its proto or JSON name.
+func field(desc protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if fd := desc.Fields().ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return desc.Fields().ByJSONName(name)
+}
+
+// checkFieldPath reports whether path, such as user.email, names a field of
+// desc that can be set from a URL.
+func checkFieldPath(desc protoreflect.MessageDescriptor, path string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := field(desc, name)
+		if fd == nil {
+			return fmt.Errorf("unknown field %q", path)
+		}
+		last := i == len(names)-1
+		if fd.IsMap() || (last && fd.Message() != nil) || (!last && (fd.Message() == nil || fd.IsList())) {
+			return fmt.Errorf("field %q cannot be set from a URL", path)
+		}
+		desc = fd.Message()
+	}
+	return nil
+}
+
+// setField parses values into the field at path in msg. Repeated fields
+// take every value; other fields exactly one.
+func setField(msg protoreflect.Message, path string, values ...string) error {
+	if err := checkFieldPath(msg.Descriptor(), path); err != nil {
+		return err
+	}
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		msg = msg.Mutable(field(msg.Descriptor(), name)).Message()
+	}
+	fd := field(msg.Descriptor(), names[len(names)-1])
+	if !fd.IsList() && len(values) != 1 {
+		return fmt.Errorf("field %q takes a single value", path)
+	}
+	for _, value := range values {
+		v, err := parseScalar(fd, value)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		if fd.IsList() {
+			msg.Mutable(fd).List().Append(v)
+		} else {
+			msg.Set(fd, v)
+		}
+	}
+	return nil
+}
+
+// parseScalar parses s as a value of the scalar or enum field fd, using the
+// same formats as the proto3 JSON mapping.
+func parseScalar(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(s)
+		}
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if v := fd.Enum().Values().ByName(protoreflect.Name(s)); v != nil {
+			return protoreflect.ValueOfEnum(v.Number()), nil
+		}
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown %s value %q", fd.Enum().Name(), s)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("%s fields cannot be set from a URL", fd.Kind())
+}
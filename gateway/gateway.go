@@ -0,0 +1,299 @@
+// Package gateway serves a gRPC service as HTTP/JSON. Routes come from the
+// google.api.http annotations on the service's methods; each HTTP request
+// is transcoded into a call over a gRPC connection, so it passes through the
+// same interceptors as any other client.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// maxBodyBytes bounds the JSON body of a request.
+const maxBodyBytes = 1 << 20
+
+// Gateway is an http.Handler for the HTTP bindings of one service.
+type Gateway struct {
+	conn grpc.ClientConnInterface
+	mux  *http.ServeMux
+}
+
+// binding is one HTTP rule of a method.
+type binding struct {
+	method     protoreflect.MethodDescriptor
+	fullMethod string
+	verb       string
+	// template is the annotated path, such as /v1/tickets/{email}.
+	template string
+	// params are the request fields bound by the path, in order.
+	params []string
+	// body is the request field filled from the body, "*" for the whole
+	// request, or empty for none.
+	body string
+}
+
+// pattern returns the ServeMux pattern for b, naming the wildcards p0,
+// p1, ... since field paths are not valid wildcard names.
+func (b *binding) pattern() string {
+	path := b.template
+	for i, param := range b.params {
+		path = strings.Replace(path, "{"+param+"}", fmt.Sprintf("{p%d}", i), 1)
+	}
+	return b.verb + " " + path
+}
+
+// bindings returns the HTTP rules of every annotated method of service.
+func bindings(service protoreflect.ServiceDescriptor) ([]*binding, error) {
+	var all []*binding
+	methods := service.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || rule == nil {
+			continue
+		}
+		for _, r := range append([]*annotations.HttpRule{rule}, rule.AdditionalBindings...) {
+			b, err := newBinding(method, r)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", method.FullName(), err)
+			}
+			all = append(all, b)
+		}
+	}
+	return all, nil
+}
+
+func newBinding(method protoreflect.MethodDescriptor, rule *annotations.HttpRule) (*binding, error) {
+	b := &binding{
+		method:     method,
+		fullMethod: fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name()),
+		body:       rule.Body,
+	}
+	switch pattern := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		b.verb, b.template = http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		b.verb, b.template = http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		b.verb, b.template = http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		b.verb, b.template = http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		b.verb, b.template = http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		b.verb, b.template = pattern.Custom.Kind, pattern.Custom.Path
+	default:
+		return nil, errors.New("HTTP rule has no pattern")
+	}
+	if method.IsStreamingClient() {
+		return nil, errors.New("client streaming methods cannot be served over HTTP")
+	}
+	if !strings.HasPrefix(b.template, "/") {
+		return nil, fmt.Errorf("path %q must start with /", b.template)
+	}
+	for _, segment := range strings.Split(b.template[1:], "/") {
+		if !strings.ContainsAny(segment, "{}:=*") {
+			continue
+		}
+		// Only whole-segment field variables are supported; verbs and
+		// multi-segment matches are not
+		field, ok := strings.CutPrefix(segment, "{")
+		if field, ok = strings.CutSuffix(field, "}"); !ok || strings.ContainsAny(field, "{}:=*") {
+			return nil, fmt.Errorf("unsupported path segment %q in %s", segment, b.template)
+		}
+		if err := checkFieldPath(method.Input(), field); err != nil {
+			return nil, err
+		}
+		b.params = append(b.params, field)
+	}
+	if b.body != "" && b.body != "*" {
+		fd := method.Input().Fields().ByName(protoreflect.Name(b.body))
+		if fd == nil || fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return nil, fmt.Errorf("body %q must name a message field", b.body)
+		}
+	}
+	return b, nil
+}
+
+// New returns a Gateway for the annotated methods of service, calling them
+// over conn. Every request and response message type must be registered
+// in the global registry, as generated code does.
+func New(service protoreflect.ServiceDescriptor, conn grpc.ClientConnInterface) (*Gateway, error) {
+	all, err := bindings(service)
+	if err != nil {
+		return nil, err
+	}
+	g := &Gateway{conn: conn, mux: http.NewServeMux()}
+	for _, b := range all {
+		for _, desc := range []protoreflect.MessageDescriptor{b.method.Input(), b.method.Output()} {
+			if _, err := protoregistry.GlobalTypes.FindMessageByName(desc.FullName()); err != nil {
+				return nil, fmt.Errorf("%s: %w", b.method.FullName(), err)
+			}
+		}
+		b := b
+		g.mux.HandleFunc(b.pattern(), func(w http.ResponseWriter, r *http.Request) {
+			g.serve(w, r, b)
+		})
+	}
+	return g, nil
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+func newMessage(desc protoreflect.MessageDescriptor) proto.Message {
+	// New checked that the type is registered
+	mt, _ := protoregistry.GlobalTypes.FindMessageByName(desc.FullName())
+	return mt.New().Interface()
+}
+
+// request transcodes r into the request message of b.
+func request(r *http.Request, b *binding) (proto.Message, error) {
+	req := newMessage(b.method.Input())
+	if b.body != "" {
+		data, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+		if err != nil {
+			return nil, fmt.Errorf("read body: %w", err)
+		}
+		target := req
+		if b.body != "*" {
+			fd := b.method.Input().Fields().ByName(protoreflect.Name(b.body))
+			target = req.ProtoReflect().Mutable(fd).Message().Interface()
+		}
+		if len(data) > 0 {
+			if err := protojson.Unmarshal(data, target); err != nil {
+				return nil, fmt.Errorf("decode body: %w", err)
+			}
+		}
+	}
+	for i, param := range b.params {
+		if err := setField(req.ProtoReflect(), param, r.PathValue(fmt.Sprintf("p%d", i))); err != nil {
+			return nil, err
+		}
+	}
+	if b.body != "*" {
+		for key, values := range r.URL.Query() {
+			if err := setField(req.ProtoReflect(), key, values...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return req, nil
+}
+
+// outgoingContext carries the caller's address and credentials, including
+// a verified TLS client certificate, idempotency key and any headers named
+// Grpc-Metadata-<key> to the gRPC call as metadata.
+func outgoingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	for name, values := range r.Header {
+		if key, ok := strings.CutPrefix(name, "Grpc-Metadata-"); ok {
+			md.Append(strings.ToLower(key), values...)
+		}
+	}
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		md.Set("authorization", authorization)
+	}
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		md.Set("idempotency-key", key)
+	}
+	// Only the connection may vouch for the caller's address and certificate
+	md.Delete(ClientCertificateKey)
+	if cert, ok := clientCertificate(r); ok {
+		md.Set(ClientCertificateKey, cert)
+	}
+	md.Set(ClientAddressKey, r.RemoteAddr)
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+func (g *Gateway) serve(w http.ResponseWriter, r *http.Request, b *binding) {
+	req, err := request(r, b)
+	if err != nil {
+		writeError(w, invalidRequest(err))
+		return
+	}
+	ctx := outgoingContext(r)
+	if b.method.IsStreamingServer() {
+		g.serveStream(ctx, w, b, req)
+		return
+	}
+	resp := newMessage(b.method.Output())
+	if err := g.conn.Invoke(ctx, b.fullMethod, req, resp); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// serveStream writes each message of a server stream as one line of JSON.
+// Errors before the first message get an ordinary error response; later
+// ones end the stream with a line holding only an "error" object.
+func (g *Gateway) serveStream(ctx context.Context, w http.ResponseWriter, b *binding, req proto.Message) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := g.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, b.fullMethod)
+	if err == nil {
+		err = stream.SendMsg(req)
+	}
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	for first := true; ; first = false {
+		msg := newMessage(b.method.Output())
+		err := stream.RecvMsg(msg)
+		switch {
+		case errors.Is(err, io.EOF):
+			return
+		case err != nil && first:
+			writeError(w, err)
+			return
+		case first:
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+		}
+		if err != nil {
+			fmt.Fprintf(w, "{\"error\":%s}\n", statusJSON(err))
+			return
+		}
+		line, err := protojson.Marshal(msg)
+		if err != nil {
+			return
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, msg proto.Message) {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	for code, want := range map[codes.Code]int{
+		codes.OK:                 http.StatusOK,
+		codes.InvalidArgument:    http.StatusBadRequest,
+		codes.FailedPrecondition: http.StatusBadRequest,
+		codes.NotFound:           http.StatusNotFound,
+		codes.AlreadyExists:      http.StatusConflict,
+		codes.Aborted:            http.StatusConflict,
+		codes.Unauthenticated:    http.StatusUnauthorized,
+		codes.PermissionDenied:   http.StatusForbidden,
+		codes.ResourceExhausted:  http.StatusTooManyRequests,
+		codes.Unavailable:        http.StatusServiceUnavailable,
+		codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+		codes.Internal:           http.StatusInternalServerError,
+		codes.Unknown:            http.StatusInternalServerError,
+	} {
+		assert.Equal(t, want, HTTPStatus(code), code.String())
+	}
+}
+
+func TestSetField(t *testing.T) {
+	req := &pb.PurchaseTicketRequest{}
+	msg := req.ProtoReflect()
+	require.NoError(t, setField(msg, "user.email", "john.doe@example.com"))
+	require.NoError(t, setField(msg, "passengerType", "PASSENGER_TYPE_CHILD"), "JSON names are accepted")
+	require.NoError(t, setField(msg, "preferences.forward_facing", "true"))
+	assert.Equal(t, "john.doe@example.com", req.User.Email)
+	assert.Equal(t, pb.PassengerType_PASSENGER_TYPE_CHILD, req.PassengerType)
+	assert.True(t, req.Preferences.ForwardFacing)
+
+	group := &pb.CreateDepartureRequest{}
+	assert.Error(t, setField(group.ProtoReflect(), "sections", "A"), "message lists cannot be set from a URL")
+	assert.Error(t, setField(msg, "user", "john"), "messages cannot be set from a URL")
+	assert.Error(t, setField(msg, "nope", "1"), "unknown field")
+	assert.Error(t, setField(msg, "from", "London", "Paris"), "repeated value for a singular field")
+	assert.Error(t, setField(msg, "passenger_type", "PASSENGER_TYPE_GHOST"), "unknown enum value")
+
+	watch := &pb.WatchAvailabilityRequest{}
+	assert.Error(t, setField(watch.ProtoReflect(), "cursor", "-1"), "negative unsigned value")
+	require.NoError(t, setField(watch.ProtoReflect(), "cursor", "18446744073709551615"))
+	assert.Equal(t, uint64(18446744073709551615), watch.Cursor)
+}
+
+func TestNewBindingRejectsUnsupportedRules(t *testing.T) {
+	method := pb.File_train_schema_proto.Services().ByName("TrainService").Methods().ByName("GetReceipt")
+	rule := func(get, body string) *annotations.HttpRule {
+		return &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: get}, Body: body}
+	}
+
+	b, err := newBinding(method, rule("/v1/tickets/{email}", ""))
+	require.NoError(t, err, "error binding a simple rule")
+	assert.Equal(t, "GET /v1/tickets/{p0}", b.pattern())
+	assert.Equal(t, "/train.TrainService/GetReceipt", b.fullMethod)
+
+	for _, r := range []*annotations.HttpRule{
+		rule("v1/tickets", ""),
+		rule("/v1/tickets/{email=users/*}", ""),
+		rule("/v1/tickets/{email}:cancel", ""),
+		rule("/v1/tickets/{nope}", ""),
+		rule("/v1/tickets", "email"),
+		{},
+	} {
+		_, err := newBinding(method, r)
+		assert.Error(t, err, prototext.Format(r))
+	}
+}
+
+func TestEveryMethodHasBinding(t *testing.T) {
+	service := pb.File_train_schema_proto.Services().ByName("TrainService")
+	all, err := bindings(service)
+	require.NoError(t, err, "error reading bindings")
+	bound := make(map[string]bool)
+	for _, b := range all {
+		bound[string(b.method.Name())] = true
+	}
+	for i := 0; i < service.Methods().Len(); i++ {
+		name := string(service.Methods().Get(i).Name())
+		assert.True(t, bound[name], "%s has no HTTP binding", name)
+	}
+}
@@ -0,0 +1,225 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Info describes the API in its OpenAPI document.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// statusSchema is the schema of every error response.
+var statusSchema = (&spb.Status{}).ProtoReflect().Descriptor()
+
+// OpenAPI returns an OpenAPI 3 document, as indented JSON, describing the
+// HTTP bindings of service as the Gateway serves them.
+func OpenAPI(service protoreflect.ServiceDescriptor, info Info) ([]byte, error) {
+	all, err := bindings(service)
+	if err != nil {
+		return nil, err
+	}
+	doc := &openAPIDoc{schemas: make(map[string]any)}
+	paths := make(map[string]map[string]any)
+	operationIDs := make(map[string]int)
+	for _, b := range all {
+		if paths[b.template] == nil {
+			paths[b.template] = make(map[string]any)
+		}
+		id := string(b.method.Name())
+		if operationIDs[id]++; operationIDs[id] > 1 {
+			id = fmt.Sprintf("%s%d", id, operationIDs[id])
+		}
+		paths[b.template][strings.ToLower(b.verb)] = doc.operation(b, id, string(service.Name()))
+	}
+	doc.schema(statusSchema)
+
+	return json.MarshalIndent(map[string]any{
+		"openapi": "3.0.3",
+		"info":    info,
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": doc.schemas,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+		"security": []any{map[string]any{"bearer": []string{}}},
+	}, "", "  ")
+}
+
+// openAPIDoc collects the component schemas an OpenAPI document refers to.
+type openAPIDoc struct {
+	schemas map[string]any
+}
+
+func (d *openAPIDoc) operation(b *binding, id, tag string) map[string]any {
+	op := map[string]any{
+		"operationId": id,
+		"tags":        []string{tag},
+	}
+	var params []any
+	bound := make(map[string]bool)
+	for _, param := range b.params {
+		bound[param] = true
+		params = append(params, map[string]any{
+			"name":     param,
+			"in":       "path",
+			"required": true,
+			"schema":   d.fieldSchema(fieldAt(b.method.Input(), param)),
+		})
+	}
+	if b.body != "*" {
+		fields := b.method.Input().Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			if bound[string(fd.Name())] || string(fd.Name()) == b.body || fd.Message() != nil || fd.IsMap() {
+				continue
+			}
+			params = append(params, map[string]any{
+				"name":   string(fd.Name()),
+				"in":     "query",
+				"schema": d.fieldSchema(fd),
+			})
+		}
+	}
+	if params != nil {
+		op["parameters"] = params
+	}
+
+	switch b.body {
+	case "":
+	case "*":
+		op["requestBody"] = jsonContent("application/json", d.schema(b.method.Input()), true)
+	default:
+		op["requestBody"] = jsonContent("application/json", d.schema(b.method.Input().Fields().ByName(protoreflect.Name(b.body)).Message()), true)
+	}
+
+	ok := jsonContent("application/json", d.schema(b.method.Output()), false)
+	ok["description"] = "OK"
+	if b.method.IsStreamingServer() {
+		ok = jsonContent("application/x-ndjson", d.schema(b.method.Output()), false)
+		ok["description"] = "A stream of messages, one JSON object per line. An error after the stream has started is sent as a final line holding only an \"error\" object."
+	}
+	errResponse := jsonContent("application/json", d.schema(statusSchema), false)
+	errResponse["description"] = "The call failed; the HTTP status is mapped from the gRPC code."
+	op["responses"] = map[string]any{"200": ok, "default": errResponse}
+	return op
+}
+
+func jsonContent(mediaType string, schema any, required bool) map[string]any {
+	content := map[string]any{"content": map[string]any{mediaType: map[string]any{"schema": schema}}}
+	if required {
+		content["required"] = true
+	}
+	return content
+}
+
+// fieldAt returns the field at a dotted path already checked by
+// checkFieldPath.
+func fieldAt(desc protoreflect.MessageDescriptor, path string) protoreflect.FieldDescriptor {
+	var fd protoreflect.FieldDescriptor
+	for _, name := range strings.Split(path, ".") {
+		fd = field(desc, name)
+		desc = fd.Message()
+	}
+	return fd
+}
+
+// schema returns a reference to the component schema of desc, adding it and
+// every message it uses to the document. Well-known types use their JSON
+// mapping instead.
+func (d *openAPIDoc) schema(desc protoreflect.MessageDescriptor) map[string]any {
+	switch desc.FullName() {
+	case "google.protobuf.Timestamp":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "google.protobuf.Duration":
+		return map[string]any{"type": "string", "example": "1.5s"}
+	case "google.protobuf.Any":
+		return map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{"@type": map[string]any{"type": "string"}},
+			"additionalProperties": true,
+		}
+	}
+	name := string(desc.FullName())
+	ref := map[string]any{"$ref": "#/components/schemas/" + name}
+	if _, ok := d.schemas[name]; ok {
+		return ref
+	}
+	properties := make(map[string]any)
+	schema := map[string]any{"type": "object", "properties": properties}
+	d.schemas[name] = schema // before the fields, for recursive messages
+
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		properties[fd.JSONName()] = d.fieldSchema(fd)
+	}
+	return ref
+}
+
+func (d *openAPIDoc) fieldSchema(fd protoreflect.FieldDescriptor) map[string]any {
+	if fd.IsMap() {
+		return map[string]any{"type": "object", "additionalProperties": d.valueSchema(fd.MapValue())}
+	}
+	if fd.IsList() {
+		return map[string]any{"type": "array", "items": d.valueSchema(fd)}
+	}
+	return d.valueSchema(fd)
+}
+
+// valueSchema returns the schema of a single value of fd, following the
+// proto3 JSON mapping.
+func (d *openAPIDoc) valueSchema(fd protoreflect.FieldDescriptor) map[string]any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return d.schema(fd.Message())
+	case protoreflect.EnumKind:
+		name := string(fd.Enum().FullName())
+		if _, ok := d.schemas[name]; !ok {
+			var values []string
+			for i := 0; i < fd.Enum().Values().Len(); i++ {
+				values = append(values, string(fd.Enum().Values().Get(i).Name()))
+			}
+			d.schemas[name] = map[string]any{"type": "string", "enum": values}
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]any{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "format": "byte"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer", "format": "int64", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		// 64-bit integers are JSON strings so they survive JavaScript
+		return map[string]any{"type": "string", "format": "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]any{"type": "string", "format": "uint64"}
+	case protoreflect.FloatKind:
+		return map[string]any{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return map[string]any{"type": "number", "format": "double"}
+	}
+	return map[string]any{}
+}
+
+// ServeOpenAPI returns a handler serving an OpenAPI document.
+func ServeOpenAPI(doc []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(doc)
+	})
+}
@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ClientCertificateKey is the metadata key that carries the verified
+	// TLS client certificate of an HTTP request, DER encoded, to its gRPC
+	// call. Callers cannot set it through Grpc-Metadata- headers.
+	ClientCertificateKey = "gateway-client-cert-bin"
+	// ClientAddressKey likewise carries the remote address of the request.
+	ClientAddressKey = "gateway-client-addr"
+)
+
+// clientAddr is a remote address forwarded by the gateway.
+type clientAddr string
+
+func (a clientAddr) Network() string { return "tcp" }
+func (a clientAddr) String() string  { return string(a) }
+
+// clientCertificate returns the DER encoding of r's verified client
+// certificate, if it has one.
+func clientCertificate(r *http.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return string(r.TLS.VerifiedChains[0][0].Raw), true
+}
+
+// UnaryServerInterceptor makes the client address and certificate
+// forwarded by the gateway the peer of the call, so certificate
+// authentication and anything else keyed by peer work as they do on a
+// direct connection. Install it only on a gRPC server that the gateway
+// alone can reach, ahead of authentication: it trusts ClientAddressKey and
+// ClientCertificateKey.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := forwardedPeer(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := forwardedPeer(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &peerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// peerStream is a ServerStream with a replaced context.
+type peerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *peerStream) Context() context.Context { return s.ctx }
+
+// forwardedPeer returns ctx with its peer's address and TLS state taken
+// from ClientAddressKey and ClientCertificateKey, where they are set.
+func forwardedPeer(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	addrs, certs := md.Get(ClientAddressKey), md.Get(ClientCertificateKey)
+	if len(addrs) == 0 && len(certs) == 0 {
+		return ctx, nil
+	}
+	forwarded := &peer.Peer{}
+	if p, ok := peer.FromContext(ctx); ok {
+		*forwarded = *p
+	}
+	if len(addrs) > 0 {
+		forwarded.Addr = clientAddr(addrs[0])
+	}
+	if len(certs) > 0 {
+		cert, err := x509.ParseCertificate([]byte(certs[0]))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "malformed forwarded client certificate")
+		}
+		forwarded.AuthInfo = credentials.TLSInfo{
+			State:          tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+		}
+	}
+	return peer.NewContext(ctx, forwarded), nil
+}
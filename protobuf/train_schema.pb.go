@@ -7,8 +7,10 @@
 package train
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -20,6 +22,318 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type PassengerType int32
+
+const (
+	PassengerType_PASSENGER_TYPE_ADULT  PassengerType = 0
+	PassengerType_PASSENGER_TYPE_CHILD  PassengerType = 1
+	PassengerType_PASSENGER_TYPE_SENIOR PassengerType = 2
+)
+
+// Enum value maps for PassengerType.
+var (
+	PassengerType_name = map[int32]string{
+		0: "PASSENGER_TYPE_ADULT",
+		1: "PASSENGER_TYPE_CHILD",
+		2: "PASSENGER_TYPE_SENIOR",
+	}
+	PassengerType_value = map[string]int32{
+		"PASSENGER_TYPE_ADULT":  0,
+		"PASSENGER_TYPE_CHILD":  1,
+		"PASSENGER_TYPE_SENIOR": 2,
+	}
+)
+
+func (x PassengerType) Enum() *PassengerType {
+	p := new(PassengerType)
+	*p = x
+	return p
+}
+
+func (x PassengerType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PassengerType) Descriptor() protoreflect.EnumDescriptor {
+	return file_train_schema_proto_enumTypes[0].Descriptor()
+}
+
+func (PassengerType) Type() protoreflect.EnumType {
+	return &file_train_schema_proto_enumTypes[0]
+}
+
+func (x PassengerType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PassengerType.Descriptor instead.
+func (PassengerType) EnumDescriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{0}
+}
+
+type PaymentStatus int32
+
+const (
+	PaymentStatus_PAYMENT_STATUS_UNSPECIFIED        PaymentStatus = 0
+	PaymentStatus_PAYMENT_STATUS_AUTHORIZED         PaymentStatus = 1
+	PaymentStatus_PAYMENT_STATUS_CAPTURED           PaymentStatus = 2
+	PaymentStatus_PAYMENT_STATUS_VOIDED             PaymentStatus = 3
+	PaymentStatus_PAYMENT_STATUS_REFUNDED           PaymentStatus = 4
+	PaymentStatus_PAYMENT_STATUS_PARTIALLY_REFUNDED PaymentStatus = 5
+)
+
+// Enum value maps for PaymentStatus.
+var (
+	PaymentStatus_name = map[int32]string{
+		0: "PAYMENT_STATUS_UNSPECIFIED",
+		1: "PAYMENT_STATUS_AUTHORIZED",
+		2: "PAYMENT_STATUS_CAPTURED",
+		3: "PAYMENT_STATUS_VOIDED",
+		4: "PAYMENT_STATUS_REFUNDED",
+		5: "PAYMENT_STATUS_PARTIALLY_REFUNDED",
+	}
+	PaymentStatus_value = map[string]int32{
+		"PAYMENT_STATUS_UNSPECIFIED":        0,
+		"PAYMENT_STATUS_AUTHORIZED":         1,
+		"PAYMENT_STATUS_CAPTURED":           2,
+		"PAYMENT_STATUS_VOIDED":             3,
+		"PAYMENT_STATUS_REFUNDED":           4,
+		"PAYMENT_STATUS_PARTIALLY_REFUNDED": 5,
+	}
+)
+
+func (x PaymentStatus) Enum() *PaymentStatus {
+	p := new(PaymentStatus)
+	*p = x
+	return p
+}
+
+func (x PaymentStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PaymentStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_train_schema_proto_enumTypes[1].Descriptor()
+}
+
+func (PaymentStatus) Type() protoreflect.EnumType {
+	return &file_train_schema_proto_enumTypes[1]
+}
+
+func (x PaymentStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PaymentStatus.Descriptor instead.
+func (PaymentStatus) EnumDescriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{1}
+}
+
+type SeatPosition int32
+
+const (
+	SeatPosition_SEAT_POSITION_ANY    SeatPosition = 0
+	SeatPosition_SEAT_POSITION_WINDOW SeatPosition = 1
+	SeatPosition_SEAT_POSITION_AISLE  SeatPosition = 2
+)
+
+// Enum value maps for SeatPosition.
+var (
+	SeatPosition_name = map[int32]string{
+		0: "SEAT_POSITION_ANY",
+		1: "SEAT_POSITION_WINDOW",
+		2: "SEAT_POSITION_AISLE",
+	}
+	SeatPosition_value = map[string]int32{
+		"SEAT_POSITION_ANY":    0,
+		"SEAT_POSITION_WINDOW": 1,
+		"SEAT_POSITION_AISLE":  2,
+	}
+)
+
+func (x SeatPosition) Enum() *SeatPosition {
+	p := new(SeatPosition)
+	*p = x
+	return p
+}
+
+func (x SeatPosition) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SeatPosition) Descriptor() protoreflect.EnumDescriptor {
+	return file_train_schema_proto_enumTypes[2].Descriptor()
+}
+
+func (SeatPosition) Type() protoreflect.EnumType {
+	return &file_train_schema_proto_enumTypes[2]
+}
+
+func (x SeatPosition) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SeatPosition.Descriptor instead.
+func (SeatPosition) EnumDescriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{2}
+}
+
+type SwapStatus int32
+
+const (
+	SwapStatus_SWAP_STATUS_UNSPECIFIED SwapStatus = 0
+	SwapStatus_SWAP_STATUS_PENDING     SwapStatus = 1 // waiting for the other passenger's consent
+	SwapStatus_SWAP_STATUS_COMPLETED   SwapStatus = 2 // seats exchanged
+)
+
+// Enum value maps for SwapStatus.
+var (
+	SwapStatus_name = map[int32]string{
+		0: "SWAP_STATUS_UNSPECIFIED",
+		1: "SWAP_STATUS_PENDING",
+		2: "SWAP_STATUS_COMPLETED",
+	}
+	SwapStatus_value = map[string]int32{
+		"SWAP_STATUS_UNSPECIFIED": 0,
+		"SWAP_STATUS_PENDING":     1,
+		"SWAP_STATUS_COMPLETED":   2,
+	}
+)
+
+func (x SwapStatus) Enum() *SwapStatus {
+	p := new(SwapStatus)
+	*p = x
+	return p
+}
+
+func (x SwapStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SwapStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_train_schema_proto_enumTypes[3].Descriptor()
+}
+
+func (SwapStatus) Type() protoreflect.EnumType {
+	return &file_train_schema_proto_enumTypes[3]
+}
+
+func (x SwapStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SwapStatus.Descriptor instead.
+func (SwapStatus) EnumDescriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{3}
+}
+
+type SeatState int32
+
+const (
+	SeatState_SEAT_STATE_FREE SeatState = 0
+	SeatState_SEAT_STATE_SOLD SeatState = 1
+	SeatState_SEAT_STATE_HELD SeatState = 2
+)
+
+// Enum value maps for SeatState.
+var (
+	SeatState_name = map[int32]string{
+		0: "SEAT_STATE_FREE",
+		1: "SEAT_STATE_SOLD",
+		2: "SEAT_STATE_HELD",
+	}
+	SeatState_value = map[string]int32{
+		"SEAT_STATE_FREE": 0,
+		"SEAT_STATE_SOLD": 1,
+		"SEAT_STATE_HELD": 2,
+	}
+)
+
+func (x SeatState) Enum() *SeatState {
+	p := new(SeatState)
+	*p = x
+	return p
+}
+
+func (x SeatState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SeatState) Descriptor() protoreflect.EnumDescriptor {
+	return file_train_schema_proto_enumTypes[4].Descriptor()
+}
+
+func (SeatState) Type() protoreflect.EnumType {
+	return &file_train_schema_proto_enumTypes[4]
+}
+
+func (x SeatState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SeatState.Descriptor instead.
+func (SeatState) EnumDescriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{4}
+}
+
+type BookingEventType int32
+
+const (
+	BookingEventType_BOOKING_EVENT_TYPE_UNSPECIFIED         BookingEventType = 0
+	BookingEventType_BOOKING_EVENT_TYPE_TICKET_PURCHASED    BookingEventType = 1
+	BookingEventType_BOOKING_EVENT_TYPE_SEAT_MODIFIED       BookingEventType = 2
+	BookingEventType_BOOKING_EVENT_TYPE_TICKET_CANCELLED    BookingEventType = 3
+	BookingEventType_BOOKING_EVENT_TYPE_DEPARTURE_CREATED   BookingEventType = 4
+	BookingEventType_BOOKING_EVENT_TYPE_DEPARTURE_CANCELLED BookingEventType = 5
+)
+
+// Enum value maps for BookingEventType.
+var (
+	BookingEventType_name = map[int32]string{
+		0: "BOOKING_EVENT_TYPE_UNSPECIFIED",
+		1: "BOOKING_EVENT_TYPE_TICKET_PURCHASED",
+		2: "BOOKING_EVENT_TYPE_SEAT_MODIFIED",
+		3: "BOOKING_EVENT_TYPE_TICKET_CANCELLED",
+		4: "BOOKING_EVENT_TYPE_DEPARTURE_CREATED",
+		5: "BOOKING_EVENT_TYPE_DEPARTURE_CANCELLED",
+	}
+	BookingEventType_value = map[string]int32{
+		"BOOKING_EVENT_TYPE_UNSPECIFIED":         0,
+		"BOOKING_EVENT_TYPE_TICKET_PURCHASED":    1,
+		"BOOKING_EVENT_TYPE_SEAT_MODIFIED":       2,
+		"BOOKING_EVENT_TYPE_TICKET_CANCELLED":    3,
+		"BOOKING_EVENT_TYPE_DEPARTURE_CREATED":   4,
+		"BOOKING_EVENT_TYPE_DEPARTURE_CANCELLED": 5,
+	}
+)
+
+func (x BookingEventType) Enum() *BookingEventType {
+	p := new(BookingEventType)
+	*p = x
+	return p
+}
+
+func (x BookingEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BookingEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_train_schema_proto_enumTypes[5].Descriptor()
+}
+
+func (BookingEventType) Type() protoreflect.EnumType {
+	return &file_train_schema_proto_enumTypes[5]
+}
+
+func (x BookingEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BookingEventType.Descriptor instead.
+func (BookingEventType) EnumDescriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{5}
+}
+
 type User struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -30,21 +344,2756 @@ type User struct {
 	Email     string `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
 }
 
-func (x *User) Reset() {
-	*x = User{}
-	mi := &file_train_schema_proto_msgTypes[0]
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_train_schema_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *User) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *User) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *User) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type SeatAllocation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Section string `protobuf:"bytes,1,opt,name=section,proto3" json:"section,omitempty"`
+	Seat    int32  `protobuf:"varint,2,opt,name=seat,proto3" json:"seat,omitempty"`
+}
+
+func (x *SeatAllocation) Reset() {
+	*x = SeatAllocation{}
+	mi := &file_train_schema_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeatAllocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeatAllocation) ProtoMessage() {}
+
+func (x *SeatAllocation) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeatAllocation.ProtoReflect.Descriptor instead.
+func (*SeatAllocation) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SeatAllocation) GetSection() string {
+	if x != nil {
+		return x.Section
+	}
+	return ""
+}
+
+func (x *SeatAllocation) GetSeat() int32 {
+	if x != nil {
+		return x.Seat
+	}
+	return 0
+}
+
+type FareComponent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // base, class, lead_time, demand, passenger
+	Description string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	AmountCents int64  `protobuf:"varint,3,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"` // negative for discounts
+}
+
+func (x *FareComponent) Reset() {
+	*x = FareComponent{}
+	mi := &file_train_schema_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FareComponent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FareComponent) ProtoMessage() {}
+
+func (x *FareComponent) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FareComponent.ProtoReflect.Descriptor instead.
+func (*FareComponent) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FareComponent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FareComponent) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *FareComponent) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+type FareBreakdown struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Currency   string           `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	Components []*FareComponent `protobuf:"bytes,2,rep,name=components,proto3" json:"components,omitempty"`
+	TotalCents int64            `protobuf:"varint,3,opt,name=total_cents,json=totalCents,proto3" json:"total_cents,omitempty"`
+}
+
+func (x *FareBreakdown) Reset() {
+	*x = FareBreakdown{}
+	mi := &file_train_schema_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FareBreakdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FareBreakdown) ProtoMessage() {}
+
+func (x *FareBreakdown) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FareBreakdown.ProtoReflect.Descriptor instead.
+func (*FareBreakdown) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FareBreakdown) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *FareBreakdown) GetComponents() []*FareComponent {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+func (x *FareBreakdown) GetTotalCents() int64 {
+	if x != nil {
+		return x.TotalCents
+	}
+	return 0
+}
+
+type PaymentInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Provider      string        `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Status        PaymentStatus `protobuf:"varint,2,opt,name=status,proto3,enum=train.PaymentStatus" json:"status,omitempty"`
+	TransactionId string        `protobuf:"bytes,3,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	AmountCents   int64         `protobuf:"varint,4,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	Currency      string        `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	RefundedCents int64         `protobuf:"varint,6,opt,name=refunded_cents,json=refundedCents,proto3" json:"refunded_cents,omitempty"`
+	RefundIds     []string      `protobuf:"bytes,7,rep,name=refund_ids,json=refundIds,proto3" json:"refund_ids,omitempty"`
+}
+
+func (x *PaymentInfo) Reset() {
+	*x = PaymentInfo{}
+	mi := &file_train_schema_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentInfo) ProtoMessage() {}
+
+func (x *PaymentInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentInfo.ProtoReflect.Descriptor instead.
+func (*PaymentInfo) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PaymentInfo) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *PaymentInfo) GetStatus() PaymentStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+}
+
+func (x *PaymentInfo) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *PaymentInfo) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *PaymentInfo) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *PaymentInfo) GetRefundedCents() int64 {
+	if x != nil {
+		return x.RefundedCents
+	}
+	return 0
+}
+
+func (x *PaymentInfo) GetRefundIds() []string {
+	if x != nil {
+		return x.RefundIds
+	}
+	return nil
+}
+
+type TicketReceipt struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User             *User           `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	From             string          `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To               string          `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Price            int32           `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"` // fare.total_cents rounded to whole currency units
+	Seat             *SeatAllocation `protobuf:"bytes,5,opt,name=seat,proto3" json:"seat,omitempty"`
+	DepartureId      string          `protobuf:"bytes,6,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	BookingReference string          `protobuf:"bytes,7,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"` // PNR shared by every ticket in a booking
+	TicketId         string          `protobuf:"bytes,8,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	Fare             *FareBreakdown  `protobuf:"bytes,9,opt,name=fare,proto3" json:"fare,omitempty"`
+	PassengerType    PassengerType   `protobuf:"varint,10,opt,name=passenger_type,json=passengerType,proto3,enum=train.PassengerType" json:"passenger_type,omitempty"`
+	Payment          *PaymentInfo    `protobuf:"bytes,11,opt,name=payment,proto3" json:"payment,omitempty"`
+	// Starts at 1 and goes up with every change to the ticket. Pass it as
+	// expected_version to change the ticket only if nobody has since.
+	Version int64 `protobuf:"varint,12,opt,name=version,proto3" json:"version,omitempty"`
+	// Set while the ticket is being cancelled, once its refund is decided and
+	// before it is made. Cancelling the ticket again completes this
+	// cancellation rather than starting another.
+	PendingCancellation *Cancellation `protobuf:"bytes,13,opt,name=pending_cancellation,json=pendingCancellation,proto3" json:"pending_cancellation,omitempty"`
+}
+
+func (x *TicketReceipt) Reset() {
+	*x = TicketReceipt{}
+	mi := &file_train_schema_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TicketReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TicketReceipt) ProtoMessage() {}
+
+func (x *TicketReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TicketReceipt.ProtoReflect.Descriptor instead.
+func (*TicketReceipt) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TicketReceipt) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *TicketReceipt) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *TicketReceipt) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *TicketReceipt) GetPrice() int32 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *TicketReceipt) GetSeat() *SeatAllocation {
+	if x != nil {
+		return x.Seat
+	}
+	return nil
+}
+
+func (x *TicketReceipt) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+func (x *TicketReceipt) GetBookingReference() string {
+	if x != nil {
+		return x.BookingReference
+	}
+	return ""
+}
+
+func (x *TicketReceipt) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
+}
+
+func (x *TicketReceipt) GetFare() *FareBreakdown {
+	if x != nil {
+		return x.Fare
+	}
+	return nil
+}
+
+func (x *TicketReceipt) GetPassengerType() PassengerType {
+	if x != nil {
+		return x.PassengerType
+	}
+	return PassengerType_PASSENGER_TYPE_ADULT
+}
+
+func (x *TicketReceipt) GetPayment() *PaymentInfo {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+func (x *TicketReceipt) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *TicketReceipt) GetPendingCancellation() *Cancellation {
+	if x != nil {
+		return x.PendingCancellation
+	}
+	return nil
+}
+
+type PurchaseTicketRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User *User  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	From string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To   string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	// Departure to book on; empty selects the default departure.
+	DepartureId   string        `protobuf:"bytes,4,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	PassengerType PassengerType `protobuf:"varint,5,opt,name=passenger_type,json=passengerType,proto3,enum=train.PassengerType" json:"passenger_type,omitempty"`
+	// Opaque card token passed to the payment provider.
+	PaymentToken string           `protobuf:"bytes,6,opt,name=payment_token,json=paymentToken,proto3" json:"payment_token,omitempty"`
+	Preferences  *SeatPreferences `protobuf:"bytes,7,opt,name=preferences,proto3" json:"preferences,omitempty"`
+	// Makes retries safe: repeating the request with the same key returns the
+	// first response instead of booking again, for as long as the server
+	// remembers keys. The idempotency-key metadata header works the same way
+	// for every change.
+	IdempotencyKey string `protobuf:"bytes,8,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+}
+
+func (x *PurchaseTicketRequest) Reset() {
+	*x = PurchaseTicketRequest{}
+	mi := &file_train_schema_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurchaseTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurchaseTicketRequest) ProtoMessage() {}
+
+func (x *PurchaseTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurchaseTicketRequest.ProtoReflect.Descriptor instead.
+func (*PurchaseTicketRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PurchaseTicketRequest) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *PurchaseTicketRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *PurchaseTicketRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *PurchaseTicketRequest) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+func (x *PurchaseTicketRequest) GetPassengerType() PassengerType {
+	if x != nil {
+		return x.PassengerType
+	}
+	return PassengerType_PASSENGER_TYPE_ADULT
+}
+
+func (x *PurchaseTicketRequest) GetPaymentToken() string {
+	if x != nil {
+		return x.PaymentToken
+	}
+	return ""
+}
+
+func (x *PurchaseTicketRequest) GetPreferences() *SeatPreferences {
+	if x != nil {
+		return x.Preferences
+	}
+	return nil
+}
+
+func (x *PurchaseTicketRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type GroupPassenger struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User          *User         `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	PassengerType PassengerType `protobuf:"varint,2,opt,name=passenger_type,json=passengerType,proto3,enum=train.PassengerType" json:"passenger_type,omitempty"`
+}
+
+func (x *GroupPassenger) Reset() {
+	*x = GroupPassenger{}
+	mi := &file_train_schema_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupPassenger) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupPassenger) ProtoMessage() {}
+
+func (x *GroupPassenger) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupPassenger.ProtoReflect.Descriptor instead.
+func (*GroupPassenger) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GroupPassenger) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *GroupPassenger) GetPassengerType() PassengerType {
+	if x != nil {
+		return x.PassengerType
+	}
+	return PassengerType_PASSENGER_TYPE_ADULT
+}
+
+// PurchaseGroupTicketRequest books every passenger on the same journey under
+// one booking reference, or none of them.
+type PurchaseGroupTicketRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Passengers  []*GroupPassenger `protobuf:"bytes,1,rep,name=passengers,proto3" json:"passengers,omitempty"`
+	From        string            `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To          string            `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	DepartureId string            `protobuf:"bytes,4,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	// Charged once for the whole group.
+	PaymentToken string           `protobuf:"bytes,5,opt,name=payment_token,json=paymentToken,proto3" json:"payment_token,omitempty"`
+	Preferences  *SeatPreferences `protobuf:"bytes,6,opt,name=preferences,proto3" json:"preferences,omitempty"`
+	// See PurchaseTicketRequest.
+	IdempotencyKey string `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+}
+
+func (x *PurchaseGroupTicketRequest) Reset() {
+	*x = PurchaseGroupTicketRequest{}
+	mi := &file_train_schema_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurchaseGroupTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurchaseGroupTicketRequest) ProtoMessage() {}
+
+func (x *PurchaseGroupTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurchaseGroupTicketRequest.ProtoReflect.Descriptor instead.
+func (*PurchaseGroupTicketRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PurchaseGroupTicketRequest) GetPassengers() []*GroupPassenger {
+	if x != nil {
+		return x.Passengers
+	}
+	return nil
+}
+
+func (x *PurchaseGroupTicketRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *PurchaseGroupTicketRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *PurchaseGroupTicketRequest) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+func (x *PurchaseGroupTicketRequest) GetPaymentToken() string {
+	if x != nil {
+		return x.PaymentToken
+	}
+	return ""
+}
+
+func (x *PurchaseGroupTicketRequest) GetPreferences() *SeatPreferences {
+	if x != nil {
+		return x.Preferences
+	}
+	return nil
+}
+
+func (x *PurchaseGroupTicketRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type GroupReceipt struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BookingReference string `protobuf:"bytes,1,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"`
+	DepartureId      string `protobuf:"bytes,2,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	// One ticket per passenger, in request order, each with its seat and fare.
+	Tickets    []*TicketReceipt `protobuf:"bytes,3,rep,name=tickets,proto3" json:"tickets,omitempty"`
+	TotalCents int64            `protobuf:"varint,4,opt,name=total_cents,json=totalCents,proto3" json:"total_cents,omitempty"`
+	Currency   string           `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	Payment    *PaymentInfo     `protobuf:"bytes,6,opt,name=payment,proto3" json:"payment,omitempty"`
+}
+
+func (x *GroupReceipt) Reset() {
+	*x = GroupReceipt{}
+	mi := &file_train_schema_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupReceipt) ProtoMessage() {}
+
+func (x *GroupReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupReceipt.ProtoReflect.Descriptor instead.
+func (*GroupReceipt) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GroupReceipt) GetBookingReference() string {
+	if x != nil {
+		return x.BookingReference
+	}
+	return ""
+}
+
+func (x *GroupReceipt) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+func (x *GroupReceipt) GetTickets() []*TicketReceipt {
+	if x != nil {
+		return x.Tickets
+	}
+	return nil
+}
+
+func (x *GroupReceipt) GetTotalCents() int64 {
+	if x != nil {
+		return x.TotalCents
+	}
+	return 0
+}
+
+func (x *GroupReceipt) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *GroupReceipt) GetPayment() *PaymentInfo {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+// SeatPreferences guide seat allocation. They are best effort unless strict
+// is set, in which case only matching seats are assigned.
+type SeatPreferences struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Position             SeatPosition `protobuf:"varint,1,opt,name=position,proto3,enum=train.SeatPosition" json:"position,omitempty"`
+	Section              string       `protobuf:"bytes,2,opt,name=section,proto3" json:"section,omitempty"`
+	ForwardFacing        bool         `protobuf:"varint,3,opt,name=forward_facing,json=forwardFacing,proto3" json:"forward_facing,omitempty"`
+	QuietCoach           bool         `protobuf:"varint,4,opt,name=quiet_coach,json=quietCoach,proto3" json:"quiet_coach,omitempty"`
+	NearAccessibleToilet bool         `protobuf:"varint,5,opt,name=near_accessible_toilet,json=nearAccessibleToilet,proto3" json:"near_accessible_toilet,omitempty"`
+	// Seat close to the passengers of this booking on the same departure. A
+	// booking the caller may not read is reported as not found.
+	CompanionBookingReference string `protobuf:"bytes,6,opt,name=companion_booking_reference,json=companionBookingReference,proto3" json:"companion_booking_reference,omitempty"`
+	Strict                    bool   `protobuf:"varint,7,opt,name=strict,proto3" json:"strict,omitempty"`
+}
+
+func (x *SeatPreferences) Reset() {
+	*x = SeatPreferences{}
+	mi := &file_train_schema_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeatPreferences) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeatPreferences) ProtoMessage() {}
+
+func (x *SeatPreferences) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeatPreferences.ProtoReflect.Descriptor instead.
+func (*SeatPreferences) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SeatPreferences) GetPosition() SeatPosition {
+	if x != nil {
+		return x.Position
+	}
+	return SeatPosition_SEAT_POSITION_ANY
+}
+
+func (x *SeatPreferences) GetSection() string {
+	if x != nil {
+		return x.Section
+	}
+	return ""
+}
+
+func (x *SeatPreferences) GetForwardFacing() bool {
+	if x != nil {
+		return x.ForwardFacing
+	}
+	return false
+}
+
+func (x *SeatPreferences) GetQuietCoach() bool {
+	if x != nil {
+		return x.QuietCoach
+	}
+	return false
+}
+
+func (x *SeatPreferences) GetNearAccessibleToilet() bool {
+	if x != nil {
+		return x.NearAccessibleToilet
+	}
+	return false
+}
+
+func (x *SeatPreferences) GetCompanionBookingReference() string {
+	if x != nil {
+		return x.CompanionBookingReference
+	}
+	return ""
+}
+
+func (x *SeatPreferences) GetStrict() bool {
+	if x != nil {
+		return x.Strict
+	}
+	return false
+}
+
+// UserRequest identifies a ticket. A booking reference selects the booking;
+// the email alone is accepted when the user holds a single ticket.
+type UserRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Email            string `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	BookingReference string `protobuf:"bytes,2,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"`
+	// RemoveUser fails with Aborted unless the ticket is at this version;
+	// zero skips the check. Reads ignore it.
+	ExpectedVersion int64 `protobuf:"varint,3,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+}
+
+func (x *UserRequest) Reset() {
+	*x = UserRequest{}
+	mi := &file_train_schema_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserRequest) ProtoMessage() {}
+
+func (x *UserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserRequest.ProtoReflect.Descriptor instead.
+func (*UserRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UserRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UserRequest) GetBookingReference() string {
+	if x != nil {
+		return x.BookingReference
+	}
+	return ""
+}
+
+func (x *UserRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type SectionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Section     string `protobuf:"bytes,1,opt,name=section,proto3" json:"section,omitempty"`
+	DepartureId string `protobuf:"bytes,2,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+}
+
+func (x *SectionRequest) Reset() {
+	*x = SectionRequest{}
+	mi := &file_train_schema_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SectionRequest) ProtoMessage() {}
+
+func (x *SectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SectionRequest.ProtoReflect.Descriptor instead.
+func (*SectionRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SectionRequest) GetSection() string {
+	if x != nil {
+		return x.Section
+	}
+	return ""
+}
+
+func (x *SectionRequest) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+type UsersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Users []*User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+}
+
+func (x *UsersResponse) Reset() {
+	*x = UsersResponse{}
+	mi := &file_train_schema_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsersResponse) ProtoMessage() {}
+
+func (x *UsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsersResponse.ProtoReflect.Descriptor instead.
+func (*UsersResponse) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type TicketsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tickets []*TicketReceipt `protobuf:"bytes,1,rep,name=tickets,proto3" json:"tickets,omitempty"`
+}
+
+func (x *TicketsResponse) Reset() {
+	*x = TicketsResponse{}
+	mi := &file_train_schema_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TicketsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TicketsResponse) ProtoMessage() {}
+
+func (x *TicketsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TicketsResponse.ProtoReflect.Descriptor instead.
+func (*TicketsResponse) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TicketsResponse) GetTickets() []*TicketReceipt {
+	if x != nil {
+		return x.Tickets
+	}
+	return nil
+}
+
+type ModifySeatRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Email      string `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	NewSection string `protobuf:"bytes,2,opt,name=new_section,json=newSection,proto3" json:"new_section,omitempty"`
+	NewSeat    int32  `protobuf:"varint,3,opt,name=new_seat,json=newSeat,proto3" json:"new_seat,omitempty"`
+	// Departure of the new seat; empty keeps the ticket's current departure.
+	// It must call at the ticket's stations, and a move to another departure
+	// fails with FailedPrecondition unless the fare there matches the fare paid.
+	DepartureId      string `protobuf:"bytes,4,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	BookingReference string `protobuf:"bytes,5,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"`
+	// Assign the lowest free seat in new_section instead of new_seat.
+	AnySeat bool `protobuf:"varint,6,opt,name=any_seat,json=anySeat,proto3" json:"any_seat,omitempty"`
+	// See PurchaseTicketRequest.
+	IdempotencyKey string `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Fails with Aborted unless the ticket is at this version, so a change
+	// based on a stale read is not applied; zero skips the check.
+	ExpectedVersion int64 `protobuf:"varint,8,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+}
+
+func (x *ModifySeatRequest) Reset() {
+	*x = ModifySeatRequest{}
+	mi := &file_train_schema_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModifySeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModifySeatRequest) ProtoMessage() {}
+
+func (x *ModifySeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModifySeatRequest.ProtoReflect.Descriptor instead.
+func (*ModifySeatRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ModifySeatRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ModifySeatRequest) GetNewSection() string {
+	if x != nil {
+		return x.NewSection
+	}
+	return ""
+}
+
+func (x *ModifySeatRequest) GetNewSeat() int32 {
+	if x != nil {
+		return x.NewSeat
+	}
+	return 0
+}
+
+func (x *ModifySeatRequest) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+func (x *ModifySeatRequest) GetBookingReference() string {
+	if x != nil {
+		return x.BookingReference
+	}
+	return ""
+}
+
+func (x *ModifySeatRequest) GetAnySeat() bool {
+	if x != nil {
+		return x.AnySeat
+	}
+	return false
+}
+
+func (x *ModifySeatRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *ModifySeatRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+// SwapSeatsRequest gives one passenger's consent to exchange seats with
+// another ticket on the same departure. The swap happens once the other
+// passenger consents in return.
+type SwapSeatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Ticket of the consenting passenger.
+	BookingReference string `protobuf:"bytes,1,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"`
+	Email            string `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	// Ticket to swap with; other_email narrows a multi-passenger booking.
+	OtherBookingReference string `protobuf:"bytes,3,opt,name=other_booking_reference,json=otherBookingReference,proto3" json:"other_booking_reference,omitempty"`
+	OtherEmail            string `protobuf:"bytes,4,opt,name=other_email,json=otherEmail,proto3" json:"other_email,omitempty"`
+	// See PurchaseTicketRequest.
+	IdempotencyKey string `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Version of the consenting passenger's ticket; see ModifySeatRequest.
+	ExpectedVersion int64 `protobuf:"varint,6,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+}
+
+func (x *SwapSeatsRequest) Reset() {
+	*x = SwapSeatsRequest{}
+	mi := &file_train_schema_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SwapSeatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwapSeatsRequest) ProtoMessage() {}
+
+func (x *SwapSeatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwapSeatsRequest.ProtoReflect.Descriptor instead.
+func (*SwapSeatsRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SwapSeatsRequest) GetBookingReference() string {
+	if x != nil {
+		return x.BookingReference
+	}
+	return ""
+}
+
+func (x *SwapSeatsRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *SwapSeatsRequest) GetOtherBookingReference() string {
+	if x != nil {
+		return x.OtherBookingReference
+	}
+	return ""
+}
+
+func (x *SwapSeatsRequest) GetOtherEmail() string {
+	if x != nil {
+		return x.OtherEmail
+	}
+	return ""
+}
+
+func (x *SwapSeatsRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *SwapSeatsRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type SwapSeatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status SwapStatus `protobuf:"varint,1,opt,name=status,proto3,enum=train.SwapStatus" json:"status,omitempty"`
+	// The consenting passenger's ticket, re-seated if the swap completed.
+	Ticket *TicketReceipt `protobuf:"bytes,2,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	// When a pending consent lapses.
+	ConsentExpires *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=consent_expires,json=consentExpires,proto3" json:"consent_expires,omitempty"`
+}
+
+func (x *SwapSeatsResponse) Reset() {
+	*x = SwapSeatsResponse{}
+	mi := &file_train_schema_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SwapSeatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwapSeatsResponse) ProtoMessage() {}
+
+func (x *SwapSeatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwapSeatsResponse.ProtoReflect.Descriptor instead.
+func (*SwapSeatsResponse) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SwapSeatsResponse) GetStatus() SwapStatus {
+	if x != nil {
+		return x.Status
+	}
+	return SwapStatus_SWAP_STATUS_UNSPECIFIED
+}
+
+func (x *SwapSeatsResponse) GetTicket() *TicketReceipt {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+func (x *SwapSeatsResponse) GetConsentExpires() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ConsentExpires
+	}
+	return nil
+}
+
+type EmptyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *EmptyResponse) Reset() {
+	*x = EmptyResponse{}
+	mi := &file_train_schema_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmptyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmptyResponse) ProtoMessage() {}
+
+func (x *EmptyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmptyResponse.ProtoReflect.Descriptor instead.
+func (*EmptyResponse) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{18}
+}
+
+type SeatAttributes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Seat       int32    `protobuf:"varint,1,opt,name=seat,proto3" json:"seat,omitempty"`
+	Attributes []string `protobuf:"bytes,2,rep,name=attributes,proto3" json:"attributes,omitempty"` // window, aisle, table, accessible, ...
+}
+
+func (x *SeatAttributes) Reset() {
+	*x = SeatAttributes{}
+	mi := &file_train_schema_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeatAttributes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeatAttributes) ProtoMessage() {}
+
+func (x *SeatAttributes) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeatAttributes.ProtoReflect.Descriptor instead.
+func (*SeatAttributes) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SeatAttributes) GetSeat() int32 {
+	if x != nil {
+		return x.Seat
+	}
+	return 0
+}
+
+func (x *SeatAttributes) GetAttributes() []string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type SectionLayout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Seats     int32  `protobuf:"varint,2,opt,name=seats,proto3" json:"seats,omitempty"` // seats are numbered 1..seats
+	SeatClass string `protobuf:"bytes,3,opt,name=seat_class,json=seatClass,proto3" json:"seat_class,omitempty"`
+	// Only seats that have attributes are listed.
+	SeatAttributes []*SeatAttributes `protobuf:"bytes,4,rep,name=seat_attributes,json=seatAttributes,proto3" json:"seat_attributes,omitempty"`
+	Quiet          bool              `protobuf:"varint,5,opt,name=quiet,proto3" json:"quiet,omitempty"` // quiet coach
+	// Seats per row, used to seat groups side by side; 0 if unknown.
+	SeatsPerRow int32 `protobuf:"varint,6,opt,name=seats_per_row,json=seatsPerRow,proto3" json:"seats_per_row,omitempty"`
+}
+
+func (x *SectionLayout) Reset() {
+	*x = SectionLayout{}
+	mi := &file_train_schema_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SectionLayout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SectionLayout) ProtoMessage() {}
+
+func (x *SectionLayout) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SectionLayout.ProtoReflect.Descriptor instead.
+func (*SectionLayout) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SectionLayout) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SectionLayout) GetSeats() int32 {
+	if x != nil {
+		return x.Seats
+	}
+	return 0
+}
+
+func (x *SectionLayout) GetSeatClass() string {
+	if x != nil {
+		return x.SeatClass
+	}
+	return ""
+}
+
+func (x *SectionLayout) GetSeatAttributes() []*SeatAttributes {
+	if x != nil {
+		return x.SeatAttributes
+	}
+	return nil
+}
+
+func (x *SectionLayout) GetQuiet() bool {
+	if x != nil {
+		return x.Quiet
+	}
+	return false
+}
+
+func (x *SectionLayout) GetSeatsPerRow() int32 {
+	if x != nil {
+		return x.SeatsPerRow
+	}
+	return 0
+}
+
+// Departure is one dated run of a train between two stations.
+type Departure struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// <train_id>-<date>, followed by -HHMM when the departure has a time, so
+	// a train may run several services a day but only one at each time.
+	Id          string           `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TrainId     string           `protobuf:"bytes,2,opt,name=train_id,json=trainId,proto3" json:"train_id,omitempty"`
+	Date        string           `protobuf:"bytes,3,opt,name=date,proto3" json:"date,omitempty"` // YYYY-MM-DD
+	Origin      string           `protobuf:"bytes,4,opt,name=origin,proto3" json:"origin,omitempty"`
+	Destination string           `protobuf:"bytes,5,opt,name=destination,proto3" json:"destination,omitempty"`
+	Sections    []*SectionLayout `protobuf:"bytes,6,rep,name=sections,proto3" json:"sections,omitempty"`
+	Cancelled   bool             `protobuf:"varint,7,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
+	// Calling points in travel order, origin first and destination last.
+	Stations []string `protobuf:"bytes,8,rep,name=stations,proto3" json:"stations,omitempty"`
+	// Length of each segment between consecutive stations, if known.
+	SegmentKm []int32 `protobuf:"varint,9,rep,packed,name=segment_km,json=segmentKm,proto3" json:"segment_km,omitempty"`
+	Time      string  `protobuf:"bytes,10,opt,name=time,proto3" json:"time,omitempty"` // HH:MM (UTC) the train leaves its origin
+}
+
+func (x *Departure) Reset() {
+	*x = Departure{}
+	mi := &file_train_schema_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Departure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Departure) ProtoMessage() {}
+
+func (x *Departure) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Departure.ProtoReflect.Descriptor instead.
+func (*Departure) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Departure) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Departure) GetTrainId() string {
+	if x != nil {
+		return x.TrainId
+	}
+	return ""
+}
+
+func (x *Departure) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *Departure) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *Departure) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *Departure) GetSections() []*SectionLayout {
+	if x != nil {
+		return x.Sections
+	}
+	return nil
+}
+
+func (x *Departure) GetCancelled() bool {
+	if x != nil {
+		return x.Cancelled
+	}
+	return false
+}
+
+func (x *Departure) GetStations() []string {
+	if x != nil {
+		return x.Stations
+	}
+	return nil
+}
+
+func (x *Departure) GetSegmentKm() []int32 {
+	if x != nil {
+		return x.SegmentKm
+	}
+	return nil
+}
+
+func (x *Departure) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+type CreateDepartureRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TrainId     string           `protobuf:"bytes,1,opt,name=train_id,json=trainId,proto3" json:"train_id,omitempty"`
+	Date        string           `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	Origin      string           `protobuf:"bytes,3,opt,name=origin,proto3" json:"origin,omitempty"`
+	Destination string           `protobuf:"bytes,4,opt,name=destination,proto3" json:"destination,omitempty"`
+	Sections    []*SectionLayout `protobuf:"bytes,5,rep,name=sections,proto3" json:"sections,omitempty"`
+	// Named layout from the server's layout file, used when sections is empty.
+	Layout string `protobuf:"bytes,6,opt,name=layout,proto3" json:"layout,omitempty"`
+	// Intermediate calling points between origin and destination, in order.
+	Via []string `protobuf:"bytes,7,rep,name=via,proto3" json:"via,omitempty"`
+	// Optional length of each segment; one entry per pair of stations.
+	SegmentKm []int32 `protobuf:"varint,8,rep,packed,name=segment_km,json=segmentKm,proto3" json:"segment_km,omitempty"`
+	Time      string  `protobuf:"bytes,9,opt,name=time,proto3" json:"time,omitempty"` // HH:MM (UTC)
+	// See PurchaseTicketRequest.
+	IdempotencyKey string `protobuf:"bytes,10,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+}
+
+func (x *CreateDepartureRequest) Reset() {
+	*x = CreateDepartureRequest{}
+	mi := &file_train_schema_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDepartureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDepartureRequest) ProtoMessage() {}
+
+func (x *CreateDepartureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDepartureRequest.ProtoReflect.Descriptor instead.
+func (*CreateDepartureRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CreateDepartureRequest) GetTrainId() string {
+	if x != nil {
+		return x.TrainId
+	}
+	return ""
+}
+
+func (x *CreateDepartureRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *CreateDepartureRequest) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *CreateDepartureRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *CreateDepartureRequest) GetSections() []*SectionLayout {
+	if x != nil {
+		return x.Sections
+	}
+	return nil
+}
+
+func (x *CreateDepartureRequest) GetLayout() string {
+	if x != nil {
+		return x.Layout
+	}
+	return ""
+}
+
+func (x *CreateDepartureRequest) GetVia() []string {
+	if x != nil {
+		return x.Via
+	}
+	return nil
+}
+
+func (x *CreateDepartureRequest) GetSegmentKm() []int32 {
+	if x != nil {
+		return x.SegmentKm
+	}
+	return nil
+}
+
+func (x *CreateDepartureRequest) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *CreateDepartureRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type ListDeparturesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Optional filters; empty matches everything.
+	TrainId          string `protobuf:"bytes,1,opt,name=train_id,json=trainId,proto3" json:"train_id,omitempty"`
+	Date             string `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	IncludeCancelled bool   `protobuf:"varint,3,opt,name=include_cancelled,json=includeCancelled,proto3" json:"include_cancelled,omitempty"`
+}
+
+func (x *ListDeparturesRequest) Reset() {
+	*x = ListDeparturesRequest{}
+	mi := &file_train_schema_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeparturesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeparturesRequest) ProtoMessage() {}
+
+func (x *ListDeparturesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeparturesRequest.ProtoReflect.Descriptor instead.
+func (*ListDeparturesRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListDeparturesRequest) GetTrainId() string {
+	if x != nil {
+		return x.TrainId
+	}
+	return ""
+}
+
+func (x *ListDeparturesRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *ListDeparturesRequest) GetIncludeCancelled() bool {
+	if x != nil {
+		return x.IncludeCancelled
+	}
+	return false
+}
+
+type ListDeparturesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Departures []*Departure `protobuf:"bytes,1,rep,name=departures,proto3" json:"departures,omitempty"`
+}
+
+func (x *ListDeparturesResponse) Reset() {
+	*x = ListDeparturesResponse{}
+	mi := &file_train_schema_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeparturesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeparturesResponse) ProtoMessage() {}
+
+func (x *ListDeparturesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeparturesResponse.ProtoReflect.Descriptor instead.
+func (*ListDeparturesResponse) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListDeparturesResponse) GetDepartures() []*Departure {
+	if x != nil {
+		return x.Departures
+	}
+	return nil
+}
+
+type DepartureRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DepartureId string `protobuf:"bytes,1,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+}
+
+func (x *DepartureRequest) Reset() {
+	*x = DepartureRequest{}
+	mi := &file_train_schema_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DepartureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepartureRequest) ProtoMessage() {}
+
+func (x *DepartureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepartureRequest.ProtoReflect.Descriptor instead.
+func (*DepartureRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DepartureRequest) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+type SeatStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Seat       int32     `protobuf:"varint,1,opt,name=seat,proto3" json:"seat,omitempty"`
+	Attributes []string  `protobuf:"bytes,2,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	State      SeatState `protobuf:"varint,3,opt,name=state,proto3,enum=train.SeatState" json:"state,omitempty"`
+}
+
+func (x *SeatStatus) Reset() {
+	*x = SeatStatus{}
+	mi := &file_train_schema_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeatStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeatStatus) ProtoMessage() {}
+
+func (x *SeatStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeatStatus.ProtoReflect.Descriptor instead.
+func (*SeatStatus) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SeatStatus) GetSeat() int32 {
+	if x != nil {
+		return x.Seat
+	}
+	return 0
+}
+
+func (x *SeatStatus) GetAttributes() []string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *SeatStatus) GetState() SeatState {
+	if x != nil {
+		return x.State
+	}
+	return SeatState_SEAT_STATE_FREE
+}
+
+type SectionMap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name      string        `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	SeatClass string        `protobuf:"bytes,2,opt,name=seat_class,json=seatClass,proto3" json:"seat_class,omitempty"`
+	Seats     []*SeatStatus `protobuf:"bytes,3,rep,name=seats,proto3" json:"seats,omitempty"`
+}
+
+func (x *SectionMap) Reset() {
+	*x = SectionMap{}
+	mi := &file_train_schema_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SectionMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SectionMap) ProtoMessage() {}
+
+func (x *SectionMap) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SectionMap.ProtoReflect.Descriptor instead.
+func (*SectionMap) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SectionMap) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SectionMap) GetSeatClass() string {
+	if x != nil {
+		return x.SeatClass
+	}
+	return ""
+}
+
+func (x *SectionMap) GetSeats() []*SeatStatus {
+	if x != nil {
+		return x.Seats
+	}
+	return nil
+}
+
+// SeatHold reserves a seat for a customer until expires_at. The fare is
+// fixed when the hold is taken.
+type SeatHold struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Token       string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	DepartureId string                 `protobuf:"bytes,2,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	Seat        *SeatAllocation        `protobuf:"bytes,3,opt,name=seat,proto3" json:"seat,omitempty"`
+	ExpiresAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Fare        *FareBreakdown         `protobuf:"bytes,5,opt,name=fare,proto3" json:"fare,omitempty"`
+}
+
+func (x *SeatHold) Reset() {
+	*x = SeatHold{}
+	mi := &file_train_schema_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeatHold) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeatHold) ProtoMessage() {}
+
+func (x *SeatHold) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeatHold.ProtoReflect.Descriptor instead.
+func (*SeatHold) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SeatHold) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *SeatHold) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+func (x *SeatHold) GetSeat() *SeatAllocation {
+	if x != nil {
+		return x.Seat
+	}
+	return nil
+}
+
+func (x *SeatHold) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *SeatHold) GetFare() *FareBreakdown {
+	if x != nil {
+		return x.Fare
+	}
+	return nil
+}
+
+type HoldRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// Card token for ConfirmHold; empty reuses the one given to HoldSeat.
+	PaymentToken string `protobuf:"bytes,2,opt,name=payment_token,json=paymentToken,proto3" json:"payment_token,omitempty"`
+	// See PurchaseTicketRequest.
+	IdempotencyKey string `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+}
+
+func (x *HoldRequest) Reset() {
+	*x = HoldRequest{}
+	mi := &file_train_schema_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HoldRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HoldRequest) ProtoMessage() {}
+
+func (x *HoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HoldRequest.ProtoReflect.Descriptor instead.
+func (*HoldRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *HoldRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *HoldRequest) GetPaymentToken() string {
+	if x != nil {
+		return x.PaymentToken
+	}
+	return ""
+}
+
+func (x *HoldRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type CancelTicketRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BookingReference string `protobuf:"bytes,1,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"`
+	// Selects the passenger within a booking, or the ticket when no booking
+	// reference is given and the user holds a single ticket.
+	Email  string `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// See PurchaseTicketRequest.
+	IdempotencyKey string `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// See ModifySeatRequest.
+	ExpectedVersion int64 `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+}
+
+func (x *CancelTicketRequest) Reset() {
+	*x = CancelTicketRequest{}
+	mi := &file_train_schema_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTicketRequest) ProtoMessage() {}
+
+func (x *CancelTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTicketRequest.ProtoReflect.Descriptor instead.
+func (*CancelTicketRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CancelTicketRequest) GetBookingReference() string {
+	if x != nil {
+		return x.BookingReference
+	}
+	return ""
+}
+
+func (x *CancelTicketRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CancelTicketRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CancelTicketRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CancelTicketRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+// Cancellation records a cancelled ticket and the refund it produced.
+type Cancellation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TicketId         string                 `protobuf:"bytes,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	BookingReference string                 `protobuf:"bytes,2,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"`
+	User             *User                  `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	DepartureId      string                 `protobuf:"bytes,4,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	CancelledAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=cancelled_at,json=cancelledAt,proto3" json:"cancelled_at,omitempty"`
+	Reason           string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	Policy           string                 `protobuf:"bytes,7,opt,name=policy,proto3" json:"policy,omitempty"` // which refund rule applied
+	RefundCents      int64                  `protobuf:"varint,8,opt,name=refund_cents,json=refundCents,proto3" json:"refund_cents,omitempty"`
+	Currency         string                 `protobuf:"bytes,9,opt,name=currency,proto3" json:"currency,omitempty"`
+	Payment          *PaymentInfo           `protobuf:"bytes,10,opt,name=payment,proto3" json:"payment,omitempty"`
+}
+
+func (x *Cancellation) Reset() {
+	*x = Cancellation{}
+	mi := &file_train_schema_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cancellation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cancellation) ProtoMessage() {}
+
+func (x *Cancellation) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cancellation.ProtoReflect.Descriptor instead.
+func (*Cancellation) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Cancellation) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
+}
+
+func (x *Cancellation) GetBookingReference() string {
+	if x != nil {
+		return x.BookingReference
+	}
+	return ""
+}
+
+func (x *Cancellation) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *Cancellation) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+func (x *Cancellation) GetCancelledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CancelledAt
+	}
+	return nil
+}
+
+func (x *Cancellation) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Cancellation) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *Cancellation) GetRefundCents() int64 {
+	if x != nil {
+		return x.RefundCents
+	}
+	return 0
+}
+
+func (x *Cancellation) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Cancellation) GetPayment() *PaymentInfo {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+type WaitlistRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User          *User         `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	DepartureId   string        `protobuf:"bytes,2,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	From          string        `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            string        `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	PassengerType PassengerType `protobuf:"varint,5,opt,name=passenger_type,json=passengerType,proto3,enum=train.PassengerType" json:"passenger_type,omitempty"`
+	// See PurchaseTicketRequest.
+	IdempotencyKey string `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+}
+
+func (x *WaitlistRequest) Reset() {
+	*x = WaitlistRequest{}
+	mi := &file_train_schema_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitlistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitlistRequest) ProtoMessage() {}
+
+func (x *WaitlistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitlistRequest.ProtoReflect.Descriptor instead.
+func (*WaitlistRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *WaitlistRequest) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *WaitlistRequest) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+func (x *WaitlistRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *WaitlistRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *WaitlistRequest) GetPassengerType() PassengerType {
+	if x != nil {
+		return x.PassengerType
+	}
+	return PassengerType_PASSENGER_TYPE_ADULT
+}
+
+func (x *WaitlistRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+// WaitlistEntry is a customer queued for a full departure. When a seat
+// frees up the entry is offered a hold, which must be confirmed before it
+// expires.
+type WaitlistEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EntryId     string                 `protobuf:"bytes,1,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	DepartureId string                 `protobuf:"bytes,2,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	User        *User                  `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	From        string                 `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To          string                 `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	Position    int32                  `protobuf:"varint,6,opt,name=position,proto3" json:"position,omitempty"` // 1 is the head of the queue
+	JoinedAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=joined_at,json=joinedAt,proto3" json:"joined_at,omitempty"`
+	OfferedHold *SeatHold              `protobuf:"bytes,8,opt,name=offered_hold,json=offeredHold,proto3" json:"offered_hold,omitempty"`
+}
+
+func (x *WaitlistEntry) Reset() {
+	*x = WaitlistEntry{}
+	mi := &file_train_schema_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitlistEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitlistEntry) ProtoMessage() {}
+
+func (x *WaitlistEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitlistEntry.ProtoReflect.Descriptor instead.
+func (*WaitlistEntry) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *WaitlistEntry) GetEntryId() string {
+	if x != nil {
+		return x.EntryId
+	}
+	return ""
+}
+
+func (x *WaitlistEntry) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+func (x *WaitlistEntry) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *WaitlistEntry) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *WaitlistEntry) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *WaitlistEntry) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *WaitlistEntry) GetJoinedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.JoinedAt
+	}
+	return nil
+}
+
+func (x *WaitlistEntry) GetOfferedHold() *SeatHold {
+	if x != nil {
+		return x.OfferedHold
+	}
+	return nil
+}
+
+type WaitlistEntryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EntryId string `protobuf:"bytes,1,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+}
+
+func (x *WaitlistEntryRequest) Reset() {
+	*x = WaitlistEntryRequest{}
+	mi := &file_train_schema_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitlistEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitlistEntryRequest) ProtoMessage() {}
+
+func (x *WaitlistEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitlistEntryRequest.ProtoReflect.Descriptor instead.
+func (*WaitlistEntryRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *WaitlistEntryRequest) GetEntryId() string {
+	if x != nil {
+		return x.EntryId
+	}
+	return ""
+}
+
+type QuoteFareRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DepartureId   string        `protobuf:"bytes,1,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	From          string        `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            string        `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	PassengerType PassengerType `protobuf:"varint,4,opt,name=passenger_type,json=passengerType,proto3,enum=train.PassengerType" json:"passenger_type,omitempty"`
+	// Seat class to quote; empty quotes the class of the first section.
+	SeatClass string `protobuf:"bytes,5,opt,name=seat_class,json=seatClass,proto3" json:"seat_class,omitempty"`
+}
+
+func (x *QuoteFareRequest) Reset() {
+	*x = QuoteFareRequest{}
+	mi := &file_train_schema_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuoteFareRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuoteFareRequest) ProtoMessage() {}
+
+func (x *QuoteFareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuoteFareRequest.ProtoReflect.Descriptor instead.
+func (*QuoteFareRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *QuoteFareRequest) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
+}
+
+func (x *QuoteFareRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *QuoteFareRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *QuoteFareRequest) GetPassengerType() PassengerType {
+	if x != nil {
+		return x.PassengerType
+	}
+	return PassengerType_PASSENGER_TYPE_ADULT
+}
+
+func (x *QuoteFareRequest) GetSeatClass() string {
+	if x != nil {
+		return x.SeatClass
+	}
+	return ""
+}
+
+type SeatMapRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DepartureId string `protobuf:"bytes,1,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	// Optional leg; seats are reported sold only if taken on this leg.
+	// Empty means the whole route.
+	From string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To   string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (x *SeatMapRequest) Reset() {
+	*x = SeatMapRequest{}
+	mi := &file_train_schema_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *User) String() string {
+func (x *SeatMapRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*User) ProtoMessage() {}
+func (*SeatMapRequest) ProtoMessage() {}
 
-func (x *User) ProtoReflect() protoreflect.Message {
-	mi := &file_train_schema_proto_msgTypes[0]
+func (x *SeatMapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -55,56 +3104,56 @@ func (x *User) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use User.ProtoReflect.Descriptor instead.
-func (*User) Descriptor() ([]byte, []int) {
-	return file_train_schema_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use SeatMapRequest.ProtoReflect.Descriptor instead.
+func (*SeatMapRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *User) GetFirstName() string {
+func (x *SeatMapRequest) GetDepartureId() string {
 	if x != nil {
-		return x.FirstName
+		return x.DepartureId
 	}
 	return ""
 }
 
-func (x *User) GetLastName() string {
+func (x *SeatMapRequest) GetFrom() string {
 	if x != nil {
-		return x.LastName
+		return x.From
 	}
 	return ""
 }
 
-func (x *User) GetEmail() string {
+func (x *SeatMapRequest) GetTo() string {
 	if x != nil {
-		return x.Email
+		return x.To
 	}
 	return ""
 }
 
-type SeatAllocation struct {
+type SeatMap struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Section string `protobuf:"bytes,1,opt,name=section,proto3" json:"section,omitempty"`
-	Seat    int32  `protobuf:"varint,2,opt,name=seat,proto3" json:"seat,omitempty"`
+	DepartureId string        `protobuf:"bytes,1,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	Sections    []*SectionMap `protobuf:"bytes,2,rep,name=sections,proto3" json:"sections,omitempty"`
 }
 
-func (x *SeatAllocation) Reset() {
-	*x = SeatAllocation{}
-	mi := &file_train_schema_proto_msgTypes[1]
+func (x *SeatMap) Reset() {
+	*x = SeatMap{}
+	mi := &file_train_schema_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SeatAllocation) String() string {
+func (x *SeatMap) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SeatAllocation) ProtoMessage() {}
+func (*SeatMap) ProtoMessage() {}
 
-func (x *SeatAllocation) ProtoReflect() protoreflect.Message {
-	mi := &file_train_schema_proto_msgTypes[1]
+func (x *SeatMap) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -115,52 +3164,55 @@ func (x *SeatAllocation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SeatAllocation.ProtoReflect.Descriptor instead.
-func (*SeatAllocation) Descriptor() ([]byte, []int) {
-	return file_train_schema_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use SeatMap.ProtoReflect.Descriptor instead.
+func (*SeatMap) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *SeatAllocation) GetSection() string {
+func (x *SeatMap) GetDepartureId() string {
 	if x != nil {
-		return x.Section
+		return x.DepartureId
 	}
 	return ""
 }
 
-func (x *SeatAllocation) GetSeat() int32 {
+func (x *SeatMap) GetSections() []*SectionMap {
 	if x != nil {
-		return x.Seat
+		return x.Sections
 	}
-	return 0
+	return nil
 }
 
-type TicketReceipt struct {
+type WatchAvailabilityRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	User  *User           `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
-	From  string          `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
-	To    string          `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
-	Price int32           `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
-	Seat  *SeatAllocation `protobuf:"bytes,5,opt,name=seat,proto3" json:"seat,omitempty"`
+	DepartureId string `protobuf:"bytes,1,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	// Optional leg; seat states are reported for this leg. Empty means the
+	// whole route.
+	From string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To   string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	// Resume after this cursor. 0, or a cursor too old to replay, starts with
+	// a fresh snapshot.
+	Cursor uint64 `protobuf:"varint,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
 }
 
-func (x *TicketReceipt) Reset() {
-	*x = TicketReceipt{}
-	mi := &file_train_schema_proto_msgTypes[2]
+func (x *WatchAvailabilityRequest) Reset() {
+	*x = WatchAvailabilityRequest{}
+	mi := &file_train_schema_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TicketReceipt) String() string {
+func (x *WatchAvailabilityRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TicketReceipt) ProtoMessage() {}
+func (*WatchAvailabilityRequest) ProtoMessage() {}
 
-func (x *TicketReceipt) ProtoReflect() protoreflect.Message {
-	mi := &file_train_schema_proto_msgTypes[2]
+func (x *WatchAvailabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -171,71 +3223,65 @@ func (x *TicketReceipt) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TicketReceipt.ProtoReflect.Descriptor instead.
-func (*TicketReceipt) Descriptor() ([]byte, []int) {
-	return file_train_schema_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use WatchAvailabilityRequest.ProtoReflect.Descriptor instead.
+func (*WatchAvailabilityRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *TicketReceipt) GetUser() *User {
+func (x *WatchAvailabilityRequest) GetDepartureId() string {
 	if x != nil {
-		return x.User
+		return x.DepartureId
 	}
-	return nil
+	return ""
 }
 
-func (x *TicketReceipt) GetFrom() string {
+func (x *WatchAvailabilityRequest) GetFrom() string {
 	if x != nil {
 		return x.From
 	}
 	return ""
 }
 
-func (x *TicketReceipt) GetTo() string {
+func (x *WatchAvailabilityRequest) GetTo() string {
 	if x != nil {
 		return x.To
 	}
 	return ""
 }
 
-func (x *TicketReceipt) GetPrice() int32 {
+func (x *WatchAvailabilityRequest) GetCursor() uint64 {
 	if x != nil {
-		return x.Price
+		return x.Cursor
 	}
 	return 0
 }
 
-func (x *TicketReceipt) GetSeat() *SeatAllocation {
-	if x != nil {
-		return x.Seat
-	}
-	return nil
-}
-
-type PurchaseTicketRequest struct {
+type SeatChange struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	User *User  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
-	From string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
-	To   string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Section string    `protobuf:"bytes,1,opt,name=section,proto3" json:"section,omitempty"`
+	Seat    int32     `protobuf:"varint,2,opt,name=seat,proto3" json:"seat,omitempty"`
+	State   SeatState `protobuf:"varint,3,opt,name=state,proto3,enum=train.SeatState" json:"state,omitempty"` // state after the change, for the watched leg
+	Reason  string    `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`                     // e.g. "purchased", "cancelled", "held", "hold_expired"
 }
 
-func (x *PurchaseTicketRequest) Reset() {
-	*x = PurchaseTicketRequest{}
-	mi := &file_train_schema_proto_msgTypes[3]
+func (x *SeatChange) Reset() {
+	*x = SeatChange{}
+	mi := &file_train_schema_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PurchaseTicketRequest) String() string {
+func (x *SeatChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PurchaseTicketRequest) ProtoMessage() {}
+func (*SeatChange) ProtoMessage() {}
 
-func (x *PurchaseTicketRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_train_schema_proto_msgTypes[3]
+func (x *SeatChange) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -246,55 +3292,68 @@ func (x *PurchaseTicketRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PurchaseTicketRequest.ProtoReflect.Descriptor instead.
-func (*PurchaseTicketRequest) Descriptor() ([]byte, []int) {
-	return file_train_schema_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use SeatChange.ProtoReflect.Descriptor instead.
+func (*SeatChange) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *PurchaseTicketRequest) GetUser() *User {
+func (x *SeatChange) GetSection() string {
 	if x != nil {
-		return x.User
+		return x.Section
 	}
-	return nil
+	return ""
 }
 
-func (x *PurchaseTicketRequest) GetFrom() string {
+func (x *SeatChange) GetSeat() int32 {
 	if x != nil {
-		return x.From
+		return x.Seat
 	}
-	return ""
+	return 0
 }
 
-func (x *PurchaseTicketRequest) GetTo() string {
+func (x *SeatChange) GetState() SeatState {
 	if x != nil {
-		return x.To
+		return x.State
+	}
+	return SeatState_SEAT_STATE_FREE
+}
+
+func (x *SeatChange) GetReason() string {
+	if x != nil {
+		return x.Reason
 	}
 	return ""
 }
 
-type UserRequest struct {
+// AvailabilityEvent is one message of the WatchAvailability stream. Pass the
+// cursor of the last event received to resume after a disconnect.
+type AvailabilityEvent struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Email string `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Cursor uint64 `protobuf:"varint,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Types that are assignable to Event:
+	//	*AvailabilityEvent_Snapshot
+	//	*AvailabilityEvent_Change
+	Event isAvailabilityEvent_Event `protobuf_oneof:"event"`
 }
 
-func (x *UserRequest) Reset() {
-	*x = UserRequest{}
-	mi := &file_train_schema_proto_msgTypes[4]
+func (x *AvailabilityEvent) Reset() {
+	*x = AvailabilityEvent{}
+	mi := &file_train_schema_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UserRequest) String() string {
+func (x *AvailabilityEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UserRequest) ProtoMessage() {}
+func (*AvailabilityEvent) ProtoMessage() {}
 
-func (x *UserRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_train_schema_proto_msgTypes[4]
+func (x *AvailabilityEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -305,41 +3364,94 @@ func (x *UserRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UserRequest.ProtoReflect.Descriptor instead.
-func (*UserRequest) Descriptor() ([]byte, []int) {
-	return file_train_schema_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use AvailabilityEvent.ProtoReflect.Descriptor instead.
+func (*AvailabilityEvent) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *UserRequest) GetEmail() string {
+func (x *AvailabilityEvent) GetCursor() uint64 {
 	if x != nil {
-		return x.Email
+		return x.Cursor
 	}
-	return ""
+	return 0
 }
 
-type SectionRequest struct {
+func (m *AvailabilityEvent) GetEvent() isAvailabilityEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *AvailabilityEvent) GetSnapshot() *SeatMap {
+	if x, ok := x.GetEvent().(*AvailabilityEvent_Snapshot); ok {
+		return x.Snapshot
+	}
+	return nil
+}
+
+func (x *AvailabilityEvent) GetChange() *SeatChange {
+	if x, ok := x.GetEvent().(*AvailabilityEvent_Change); ok {
+		return x.Change
+	}
+	return nil
+}
+
+type isAvailabilityEvent_Event interface {
+	isAvailabilityEvent_Event()
+}
+
+type AvailabilityEvent_Snapshot struct {
+	Snapshot *SeatMap `protobuf:"bytes,2,opt,name=snapshot,proto3,oneof"`
+}
+
+type AvailabilityEvent_Change struct {
+	Change *SeatChange `protobuf:"bytes,3,opt,name=change,proto3,oneof"`
+}
+
+func (*AvailabilityEvent_Snapshot) isAvailabilityEvent_Event() {}
+
+func (*AvailabilityEvent_Change) isAvailabilityEvent_Event() {}
+
+// BookingEvent is one immutable entry of the booking ledger. Replaying the
+// ledger in sequence order rebuilds departures, tickets and cancellations.
+type BookingEvent struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Section string `protobuf:"bytes,1,opt,name=section,proto3" json:"section,omitempty"`
+	Sequence uint64                 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Type     BookingEventType       `protobuf:"varint,2,opt,name=type,proto3,enum=train.BookingEventType" json:"type,omitempty"`
+	At       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=at,proto3" json:"at,omitempty"`
+	// Who made the change; "system" for changes the server makes itself.
+	Actor            string `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	Reason           string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	BookingReference string `protobuf:"bytes,6,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"`
+	TicketId         string `protobuf:"bytes,7,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	DepartureId      string `protobuf:"bytes,8,opt,name=departure_id,json=departureId,proto3" json:"departure_id,omitempty"`
+	// Ticket before and after the change; before is unset for purchases and
+	// after is unset for cancellations.
+	Before       *TicketReceipt `protobuf:"bytes,9,opt,name=before,proto3" json:"before,omitempty"`
+	After        *TicketReceipt `protobuf:"bytes,10,opt,name=after,proto3" json:"after,omitempty"`
+	Cancellation *Cancellation  `protobuf:"bytes,11,opt,name=cancellation,proto3" json:"cancellation,omitempty"`
+	Departure    *Departure     `protobuf:"bytes,12,opt,name=departure,proto3" json:"departure,omitempty"`
 }
 
-func (x *SectionRequest) Reset() {
-	*x = SectionRequest{}
-	mi := &file_train_schema_proto_msgTypes[5]
+func (x *BookingEvent) Reset() {
+	*x = BookingEvent{}
+	mi := &file_train_schema_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SectionRequest) String() string {
+func (x *BookingEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SectionRequest) ProtoMessage() {}
+func (*BookingEvent) ProtoMessage() {}
 
-func (x *SectionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_train_schema_proto_msgTypes[5]
+func (x *BookingEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -350,88 +3462,118 @@ func (x *SectionRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SectionRequest.ProtoReflect.Descriptor instead.
-func (*SectionRequest) Descriptor() ([]byte, []int) {
-	return file_train_schema_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use BookingEvent.ProtoReflect.Descriptor instead.
+func (*BookingEvent) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *SectionRequest) GetSection() string {
+func (x *BookingEvent) GetSequence() uint64 {
 	if x != nil {
-		return x.Section
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *BookingEvent) GetType() BookingEventType {
+	if x != nil {
+		return x.Type
+	}
+	return BookingEventType_BOOKING_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *BookingEvent) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+func (x *BookingEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
 	}
 	return ""
 }
 
-type UsersResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *BookingEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
 
-	Users []*User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+func (x *BookingEvent) GetBookingReference() string {
+	if x != nil {
+		return x.BookingReference
+	}
+	return ""
 }
 
-func (x *UsersResponse) Reset() {
-	*x = UsersResponse{}
-	mi := &file_train_schema_proto_msgTypes[6]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *BookingEvent) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
 }
 
-func (x *UsersResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *BookingEvent) GetDepartureId() string {
+	if x != nil {
+		return x.DepartureId
+	}
+	return ""
 }
 
-func (*UsersResponse) ProtoMessage() {}
+func (x *BookingEvent) GetBefore() *TicketReceipt {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
 
-func (x *UsersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_train_schema_proto_msgTypes[6]
+func (x *BookingEvent) GetAfter() *TicketReceipt {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.After
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use UsersResponse.ProtoReflect.Descriptor instead.
-func (*UsersResponse) Descriptor() ([]byte, []int) {
-	return file_train_schema_proto_rawDescGZIP(), []int{6}
+func (x *BookingEvent) GetCancellation() *Cancellation {
+	if x != nil {
+		return x.Cancellation
+	}
+	return nil
 }
 
-func (x *UsersResponse) GetUsers() []*User {
+func (x *BookingEvent) GetDeparture() *Departure {
 	if x != nil {
-		return x.Users
+		return x.Departure
 	}
 	return nil
 }
 
-type ModifySeatRequest struct {
+type BookingHistoryRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Email      string `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
-	NewSection string `protobuf:"bytes,2,opt,name=new_section,json=newSection,proto3" json:"new_section,omitempty"`
-	NewSeat    int32  `protobuf:"varint,3,opt,name=new_seat,json=newSeat,proto3" json:"new_seat,omitempty"`
+	BookingReference string `protobuf:"bytes,1,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"`
 }
 
-func (x *ModifySeatRequest) Reset() {
-	*x = ModifySeatRequest{}
-	mi := &file_train_schema_proto_msgTypes[7]
+func (x *BookingHistoryRequest) Reset() {
+	*x = BookingHistoryRequest{}
+	mi := &file_train_schema_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ModifySeatRequest) String() string {
+func (x *BookingHistoryRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ModifySeatRequest) ProtoMessage() {}
+func (*BookingHistoryRequest) ProtoMessage() {}
 
-func (x *ModifySeatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_train_schema_proto_msgTypes[7]
+func (x *BookingHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -442,53 +3584,41 @@ func (x *ModifySeatRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ModifySeatRequest.ProtoReflect.Descriptor instead.
-func (*ModifySeatRequest) Descriptor() ([]byte, []int) {
-	return file_train_schema_proto_rawDescGZIP(), []int{7}
-}
-
-func (x *ModifySeatRequest) GetEmail() string {
-	if x != nil {
-		return x.Email
-	}
-	return ""
+// Deprecated: Use BookingHistoryRequest.ProtoReflect.Descriptor instead.
+func (*BookingHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *ModifySeatRequest) GetNewSection() string {
+func (x *BookingHistoryRequest) GetBookingReference() string {
 	if x != nil {
-		return x.NewSection
+		return x.BookingReference
 	}
 	return ""
 }
 
-func (x *ModifySeatRequest) GetNewSeat() int32 {
-	if x != nil {
-		return x.NewSeat
-	}
-	return 0
-}
-
-type EmptyResponse struct {
+type BookingHistory struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Events []*BookingEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
 }
 
-func (x *EmptyResponse) Reset() {
-	*x = EmptyResponse{}
-	mi := &file_train_schema_proto_msgTypes[8]
+func (x *BookingHistory) Reset() {
+	*x = BookingHistory{}
+	mi := &file_train_schema_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EmptyResponse) String() string {
+func (x *BookingHistory) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EmptyResponse) ProtoMessage() {}
+func (*BookingHistory) ProtoMessage() {}
 
-func (x *EmptyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_train_schema_proto_msgTypes[8]
+func (x *BookingHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_train_schema_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -499,79 +3629,700 @@ func (x *EmptyResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EmptyResponse.ProtoReflect.Descriptor instead.
-func (*EmptyResponse) Descriptor() ([]byte, []int) {
-	return file_train_schema_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use BookingHistory.ProtoReflect.Descriptor instead.
+func (*BookingHistory) Descriptor() ([]byte, []int) {
+	return file_train_schema_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *BookingHistory) GetEvents() []*BookingEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
 }
 
 var File_train_schema_proto protoreflect.FileDescriptor
 
 var file_train_schema_proto_rawDesc = []byte{
 	0x0a, 0x12, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x22, 0x58, 0x0a, 0x04, 0x55,
-	0x73, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x6e, 0x61, 0x6d,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x66, 0x69, 0x72, 0x73, 0x74, 0x4e, 0x61,
-	0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12,
-	0x14, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
-	0x65, 0x6d, 0x61, 0x69, 0x6c, 0x22, 0x3e, 0x0a, 0x0e, 0x53, 0x65, 0x61, 0x74, 0x41, 0x6c, 0x6c,
-	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69,
-	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f,
-	0x6e, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x04, 0x73, 0x65, 0x61, 0x74, 0x22, 0x95, 0x01, 0x0a, 0x0d, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74,
-	0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x12, 0x1f, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73,
-	0x65, 0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02,
-	0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x14, 0x0a, 0x05,
-	0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x70, 0x72, 0x69,
-	0x63, 0x65, 0x12, 0x29, 0x0a, 0x04, 0x73, 0x65, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x15, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74, 0x41, 0x6c, 0x6c,
-	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x73, 0x65, 0x61, 0x74, 0x22, 0x5c, 0x0a,
-	0x15, 0x50, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65,
-	0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74,
-	0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x23, 0x0a, 0x0b, 0x55,
-	0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d,
-	0x61, 0x69, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c,
-	0x22, 0x2a, 0x0a, 0x0e, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x32, 0x0a, 0x0d,
-	0x55, 0x73, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a,
-	0x05, 0x75, 0x73, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74,
-	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73,
-	0x22, 0x65, 0x0a, 0x11, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x53, 0x65, 0x61, 0x74, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x6e,
-	0x65, 0x77, 0x5f, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0a, 0x6e, 0x65, 0x77, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x08,
-	0x6e, 0x65, 0x77, 0x5f, 0x73, 0x65, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07,
-	0x6e, 0x65, 0x77, 0x53, 0x65, 0x61, 0x74, 0x22, 0x0f, 0x0a, 0x0d, 0x45, 0x6d, 0x70, 0x74, 0x79,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xc8, 0x02, 0x0a, 0x0c, 0x54, 0x72, 0x61,
-	0x69, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x44, 0x0a, 0x0e, 0x50, 0x75, 0x72,
-	0x63, 0x68, 0x61, 0x73, 0x65, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x1c, 0x2e, 0x74, 0x72,
-	0x61, 0x69, 0x6e, 0x2e, 0x50, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x54, 0x69, 0x63, 0x6b,
-	0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69,
-	0x6e, 0x2e, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x12,
-	0x36, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x12, 0x12, 0x2e,
-	0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74,
-	0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x12, 0x40, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x55, 0x73,
-	0x65, 0x72, 0x73, 0x42, 0x79, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x15, 0x2e, 0x74,
-	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72,
-	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x0a, 0x52, 0x65, 0x6d,
-	0x6f, 0x76, 0x65, 0x55, 0x73, 0x65, 0x72, 0x12, 0x12, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e,
-	0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72,
-	0x61, 0x69, 0x6e, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x40, 0x0a, 0x0e, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x55, 0x73, 0x65, 0x72, 0x53,
-	0x65, 0x61, 0x74, 0x12, 0x18, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x4d, 0x6f, 0x64, 0x69,
-	0x66, 0x79, 0x53, 0x65, 0x61, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x1a, 0x1c, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x58, 0x0a, 0x04, 0x55, 0x73,
+	0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x66, 0x69, 0x72, 0x73, 0x74, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x6d, 0x61, 0x69, 0x6c, 0x22, 0x3e, 0x0a, 0x0e, 0x53, 0x65, 0x61, 0x74, 0x41, 0x6c, 0x6c, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x73, 0x65, 0x61, 0x74, 0x22, 0x68, 0x0a, 0x0d, 0x46, 0x61, 0x72, 0x65, 0x43, 0x6f, 0x6d, 0x70,
+	0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x61,
+	0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x63, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0b, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x82,
+	0x01, 0x0a, 0x0d, 0x46, 0x61, 0x72, 0x65, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77, 0x6e,
+	0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x34, 0x0a, 0x0a,
+	0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x46, 0x61, 0x72, 0x65, 0x43, 0x6f, 0x6d,
+	0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e,
+	0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x65, 0x6e, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x65,
+	0x6e, 0x74, 0x73, 0x22, 0x83, 0x02, 0x0a, 0x0b, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12,
+	0x2c, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x25, 0x0a,
+	0x0e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x63,
+	0x65, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x61, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x43, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x63, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x63, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x65, 0x64, 0x5f,
+	0x63, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x72, 0x65, 0x66,
+	0x75, 0x6e, 0x64, 0x65, 0x64, 0x43, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65,
+	0x66, 0x75, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09,
+	0x72, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x49, 0x64, 0x73, 0x22, 0xf9, 0x03, 0x0a, 0x0d, 0x54, 0x69,
+	0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x12, 0x1f, 0x0a, 0x04, 0x75,
+	0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x72, 0x61, 0x69,
+	0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04,
+	0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d,
+	0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f,
+	0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x29, 0x0a, 0x04, 0x73, 0x65, 0x61, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61,
+	0x74, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x73, 0x65, 0x61,
+	0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75,
+	0x72, 0x65, 0x49, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f,
+	0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x10, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63,
+	0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x12, 0x28,
+	0x0a, 0x04, 0x66, 0x61, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74,
+	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x46, 0x61, 0x72, 0x65, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f,
+	0x77, 0x6e, 0x52, 0x04, 0x66, 0x61, 0x72, 0x65, 0x12, 0x3b, 0x0a, 0x0e, 0x70, 0x61, 0x73, 0x73,
+	0x65, 0x6e, 0x67, 0x65, 0x72, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x50, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67,
+	0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0d, 0x70, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65,
+	0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x2c, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x50,
+	0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x46, 0x0a,
+	0x14, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x72,
+	0x61, 0x69, 0x6e, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x13, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xc4, 0x02, 0x0a, 0x15, 0x50, 0x75, 0x72, 0x63, 0x68, 0x61,
+	0x73, 0x65, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1f, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e,
+	0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72,
+	0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x74, 0x6f, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x70, 0x61,
+	0x72, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64, 0x12, 0x3b, 0x0a, 0x0e, 0x70, 0x61, 0x73, 0x73, 0x65,
+	0x6e, 0x67, 0x65, 0x72, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x50, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65,
+	0x72, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0d, 0x70, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65, 0x72,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x61, 0x79,
+	0x6d, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x38, 0x0a, 0x0b, 0x70, 0x72, 0x65,
+	0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74, 0x50, 0x72, 0x65, 0x66, 0x65,
+	0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x52, 0x0b, 0x70, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e,
+	0x63, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e,
+	0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x64,
+	0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x22, 0x6e, 0x0a, 0x0e,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x50, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65, 0x72, 0x12, 0x1f,
+	0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74,
+	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12,
+	0x3b, 0x0a, 0x0e, 0x70, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65, 0x72, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e,
+	0x50, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0d, 0x70,
+	0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x22, 0xa2, 0x02, 0x0a,
+	0x1a, 0x50, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x54, 0x69,
+	0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x35, 0x0a, 0x0a, 0x70,
+	0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x50, 0x61, 0x73,
+	0x73, 0x65, 0x6e, 0x67, 0x65, 0x72, 0x52, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65,
+	0x72, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74,
+	0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65,
+	0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x61, 0x79,
+	0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0c, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x38,
+	0x0a, 0x0b, 0x70, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74,
+	0x50, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x52, 0x0b, 0x70, 0x72, 0x65,
+	0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x64, 0x65, 0x6d,
+	0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65,
+	0x79, 0x22, 0xf9, 0x01, 0x0a, 0x0c, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65, 0x63, 0x65, 0x69,
+	0x70, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65,
+	0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x62,
+	0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12,
+	0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65,
+	0x49, 0x64, 0x12, 0x2e, 0x0a, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x54, 0x69, 0x63, 0x6b,
+	0x65, 0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x52, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65,
+	0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x65, 0x6e, 0x74,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x65,
+	0x6e, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12,
+	0x2c, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0xb2, 0x02,
+	0x0a, 0x0f, 0x53, 0x65, 0x61, 0x74, 0x50, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
+	0x73, 0x12, 0x2f, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74,
+	0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e,
+	0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x5f, 0x66, 0x61, 0x63, 0x69, 0x6e, 0x67, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x46, 0x61, 0x63,
+	0x69, 0x6e, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x71, 0x75, 0x69, 0x65, 0x74, 0x5f, 0x63, 0x6f, 0x61,
+	0x63, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x71, 0x75, 0x69, 0x65, 0x74, 0x43,
+	0x6f, 0x61, 0x63, 0x68, 0x12, 0x34, 0x0a, 0x16, 0x6e, 0x65, 0x61, 0x72, 0x5f, 0x61, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x5f, 0x74, 0x6f, 0x69, 0x6c, 0x65, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x6e, 0x65, 0x61, 0x72, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x69, 0x62, 0x6c, 0x65, 0x54, 0x6f, 0x69, 0x6c, 0x65, 0x74, 0x12, 0x3e, 0x0a, 0x1b, 0x63, 0x6f,
+	0x6d, 0x70, 0x61, 0x6e, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f,
+	0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x19, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x69, 0x6f, 0x6e, 0x42, 0x6f, 0x6f, 0x6b, 0x69, 0x6e,
+	0x67, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74,
+	0x72, 0x69, 0x63, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x74, 0x72, 0x69,
+	0x63, 0x74, 0x22, 0x7b, 0x0a, 0x0b, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x2b, 0x0a, 0x11, 0x62, 0x6f, 0x6f, 0x6b, 0x69,
+	0x6e, 0x67, 0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x10, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x66, 0x65, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f,
+	0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22,
+	0x4d, 0x0a, 0x0e, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x64,
+	0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64, 0x22, 0x32,
+	0x0a, 0x0d, 0x55, 0x73, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x21, 0x0a, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x05, 0x75, 0x73, 0x65,
+	0x72, 0x73, 0x22, 0x41, 0x0a, 0x0f, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x54,
+	0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x52, 0x07, 0x74, 0x69,
+	0x63, 0x6b, 0x65, 0x74, 0x73, 0x22, 0xa4, 0x02, 0x0a, 0x11, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79,
+	0x53, 0x65, 0x61, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x6d, 0x61, 0x69, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x6d, 0x61, 0x69,
+	0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x65, 0x77, 0x5f, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x77, 0x53, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x08, 0x6e, 0x65, 0x77, 0x5f, 0x73, 0x65, 0x61, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6e, 0x65, 0x77, 0x53, 0x65, 0x61, 0x74, 0x12, 0x21, 0x0a,
+	0x0c, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64,
+	0x12, 0x2b, 0x0a, 0x11, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x66, 0x65,
+	0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x62, 0x6f, 0x6f,
+	0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x19, 0x0a,
+	0x08, 0x61, 0x6e, 0x79, 0x5f, 0x73, 0x65, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x61, 0x6e, 0x79, 0x53, 0x65, 0x61, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x64, 0x65, 0x6d,
+	0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65,
+	0x79, 0x12, 0x29, 0x0a, 0x10, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x65, 0x78, 0x70,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x82, 0x02, 0x0a,
+	0x10, 0x53, 0x77, 0x61, 0x70, 0x53, 0x65, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x2b, 0x0a, 0x11, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x66,
+	0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x62, 0x6f,
+	0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x6d, 0x61, 0x69, 0x6c, 0x12, 0x36, 0x0a, 0x17, 0x6f, 0x74, 0x68, 0x65, 0x72, 0x5f, 0x62, 0x6f,
+	0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x6f, 0x74, 0x68, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x6f, 0x74, 0x68, 0x65, 0x72, 0x5f, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x6f, 0x74, 0x68, 0x65, 0x72, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x27, 0x0a,
+	0x0f, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65,
+	0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x12, 0x29, 0x0a, 0x10, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74,
+	0x65, 0x64, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x22, 0xb1, 0x01, 0x0a, 0x11, 0x53, 0x77, 0x61, 0x70, 0x53, 0x65, 0x61, 0x74, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e,
+	0x53, 0x77, 0x61, 0x70, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x2c, 0x0a, 0x06, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x54, 0x69, 0x63, 0x6b, 0x65,
+	0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x52, 0x06, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74,
+	0x12, 0x43, 0x0a, 0x0f, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x74, 0x5f, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0e, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x74, 0x45, 0x78,
+	0x70, 0x69, 0x72, 0x65, 0x73, 0x22, 0x0f, 0x0a, 0x0d, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x44, 0x0a, 0x0e, 0x53, 0x65, 0x61, 0x74, 0x41, 0x74,
+	0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x61, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x65, 0x61, 0x74, 0x12, 0x1e, 0x0a, 0x0a,
+	0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x22, 0xd2, 0x01, 0x0a,
+	0x0d, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x65, 0x61, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x05, 0x73, 0x65, 0x61, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x61, 0x74,
+	0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65,
+	0x61, 0x74, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x3e, 0x0a, 0x0f, 0x73, 0x65, 0x61, 0x74, 0x5f,
+	0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74, 0x41, 0x74, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x0e, 0x73, 0x65, 0x61, 0x74, 0x41, 0x74, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x69, 0x65, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x71, 0x75, 0x69, 0x65, 0x74, 0x12, 0x22, 0x0a,
+	0x0d, 0x73, 0x65, 0x61, 0x74, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x72, 0x6f, 0x77, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x73, 0x65, 0x61, 0x74, 0x73, 0x50, 0x65, 0x72, 0x52, 0x6f,
+	0x77, 0x22, 0xa3, 0x02, 0x0a, 0x09, 0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x19, 0x0a, 0x08, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73,
+	0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x08, 0x73, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x61,
+	0x69, 0x6e, 0x2e, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74,
+	0x52, 0x08, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x61,
+	0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63,
+	0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x73, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x5f,
+	0x6b, 0x6d, 0x18, 0x09, 0x20, 0x03, 0x28, 0x05, 0x52, 0x09, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e,
+	0x74, 0x4b, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x22, 0xb9, 0x02, 0x0a, 0x16, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x49, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73,
+	0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x08, 0x73,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x61, 0x79,
+	0x6f, 0x75, 0x74, 0x52, 0x08, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c,
+	0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x69, 0x61, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x03, 0x76, 0x69, 0x61, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x67, 0x6d, 0x65,
+	0x6e, 0x74, 0x5f, 0x6b, 0x6d, 0x18, 0x08, 0x20, 0x03, 0x28, 0x05, 0x52, 0x09, 0x73, 0x65, 0x67,
+	0x6d, 0x65, 0x6e, 0x74, 0x4b, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x64,
+	0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79,
+	0x4b, 0x65, 0x79, 0x22, 0x73, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x70, 0x61, 0x72,
+	0x74, 0x75, 0x72, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08,
+	0x74, 0x72, 0x61, 0x69, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x74, 0x72, 0x61, 0x69, 0x6e, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x69,
+	0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x65, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x43,
+	0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x65, 0x64, 0x22, 0x4a, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74,
+	0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x30, 0x0a, 0x0a, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x44,
+	0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x52, 0x0a, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74,
+	0x75, 0x72, 0x65, 0x73, 0x22, 0x35, 0x0a, 0x10, 0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61,
+	0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64, 0x22, 0x68, 0x0a, 0x0a, 0x53,
+	0x65, 0x61, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x61,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x65, 0x61, 0x74, 0x12, 0x1e, 0x0a,
+	0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x26, 0x0a,
+	0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x74,
+	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x22, 0x68, 0x0a, 0x0a, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x4d, 0x61, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x61, 0x74, 0x5f,
+	0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x61,
+	0x74, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x27, 0x0a, 0x05, 0x73, 0x65, 0x61, 0x74, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65,
+	0x61, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x05, 0x73, 0x65, 0x61, 0x74, 0x73, 0x22,
+	0xd3, 0x01, 0x0a, 0x08, 0x53, 0x65, 0x61, 0x74, 0x48, 0x6f, 0x6c, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74,
+	0x75, 0x72, 0x65, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x04, 0x73, 0x65, 0x61, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74,
+	0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x73, 0x65, 0x61, 0x74,
+	0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x28, 0x0a, 0x04, 0x66,
+	0x61, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69,
+	0x6e, 0x2e, 0x46, 0x61, 0x72, 0x65, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x52,
+	0x04, 0x66, 0x61, 0x72, 0x65, 0x22, 0x71, 0x0a, 0x0b, 0x48, 0x6f, 0x6c, 0x64, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x61,
+	0x79, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12,
+	0x27, 0x0a, 0x0f, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b,
+	0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f,
+	0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x22, 0xc4, 0x01, 0x0a, 0x13, 0x43, 0x61, 0x6e,
+	0x63, 0x65, 0x6c, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x2b, 0x0a, 0x11, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x66, 0x65,
+	0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x62, 0x6f, 0x6f,
+	0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x6d,
+	0x61, 0x69, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x69,
+	0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63,
+	0x79, 0x4b, 0x65, 0x79, 0x12, 0x29, 0x0a, 0x10, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f,
+	0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22,
+	0xf8, 0x02, 0x0a, 0x0c, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x12, 0x2b, 0x0a,
+	0x11, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e,
+	0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e,
+	0x67, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1f, 0x0a, 0x04, 0x75, 0x73,
+	0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e,
+	0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x64,
+	0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64, 0x12, 0x3d,
+	0x0a, 0x0c, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x0b, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x65, 0x64, 0x41, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x21, 0x0a,
+	0x0c, 0x72, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x5f, 0x63, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0b, 0x72, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x43, 0x65, 0x6e, 0x74, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x2c, 0x0a, 0x07,
+	0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x6e, 0x66,
+	0x6f, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0xdf, 0x01, 0x0a, 0x0f, 0x57,
+	0x61, 0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f,
+	0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74,
+	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12,
+	0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65,
+	0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x3b, 0x0a, 0x0e, 0x70, 0x61, 0x73, 0x73, 0x65, 0x6e,
+	0x67, 0x65, 0x72, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14,
+	0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x50, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65, 0x72,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x0d, 0x70, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65, 0x72, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e,
+	0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x64,
+	0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x22, 0x9b, 0x02, 0x0a,
+	0x0d, 0x57, 0x61, 0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x19,
+	0x0a, 0x08, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70,
+	0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x04,
+	0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x72, 0x61,
+	0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x12, 0x0a,
+	0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f,
+	0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74,
+	0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x37, 0x0a,
+	0x09, 0x6a, 0x6f, 0x69, 0x6e, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6a, 0x6f,
+	0x69, 0x6e, 0x65, 0x64, 0x41, 0x74, 0x12, 0x32, 0x0a, 0x0c, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x65,
+	0x64, 0x5f, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x74,
+	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74, 0x48, 0x6f, 0x6c, 0x64, 0x52, 0x0b, 0x6f,
+	0x66, 0x66, 0x65, 0x72, 0x65, 0x64, 0x48, 0x6f, 0x6c, 0x64, 0x22, 0x31, 0x0a, 0x14, 0x57, 0x61,
+	0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x49, 0x64, 0x22, 0xb5, 0x01,
+	0x0a, 0x10, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x46, 0x61, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74,
+	0x75, 0x72, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x3b, 0x0a, 0x0e, 0x70, 0x61, 0x73,
+	0x73, 0x65, 0x6e, 0x67, 0x65, 0x72, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x50, 0x61, 0x73, 0x73, 0x65, 0x6e,
+	0x67, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0d, 0x70, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67,
+	0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x61, 0x74, 0x5f, 0x63,
+	0x6c, 0x61, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x61, 0x74,
+	0x43, 0x6c, 0x61, 0x73, 0x73, 0x22, 0x57, 0x0a, 0x0e, 0x53, 0x65, 0x61, 0x74, 0x4d, 0x61, 0x70,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61, 0x72,
+	0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
+	0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72,
+	0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e,
+	0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x5b,
+	0x0a, 0x07, 0x53, 0x65, 0x61, 0x74, 0x4d, 0x61, 0x70, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70,
+	0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64, 0x12, 0x2d, 0x0a, 0x08,
+	0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x61,
+	0x70, 0x52, 0x08, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x79, 0x0a, 0x18, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61, 0x72,
+	0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
+	0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72,
+	0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e,
+	0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x16,
+	0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06,
+	0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x22, 0x7a, 0x0a, 0x0a, 0x53, 0x65, 0x61, 0x74, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x65, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x65,
+	0x61, 0x74, 0x12, 0x26, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x10, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x22, 0x8f, 0x01, 0x0a, 0x11, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73,
+	0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72,
+	0x12, 0x2c, 0x0a, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74, 0x4d,
+	0x61, 0x70, 0x48, 0x00, 0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x2b,
+	0x0a, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x48, 0x00, 0x52, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x22, 0xe1, 0x03, 0x0a, 0x0c, 0x42, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63,
+	0x65, 0x12, 0x2b, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x17, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x42, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x2a,
+	0x0a, 0x02, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x61, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x63,
+	0x74, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x63, 0x74, 0x6f, 0x72,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x11, 0x62, 0x6f, 0x6f, 0x6b,
+	0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x10, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x66, 0x65,
+	0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x5f,
+	0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74,
+	0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f,
+	0x69, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74,
+	0x75, 0x72, 0x65, 0x49, 0x64, 0x12, 0x2c, 0x0a, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x54, 0x69,
+	0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x52, 0x06, 0x62, 0x65, 0x66,
+	0x6f, 0x72, 0x65, 0x12, 0x2a, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x54, 0x69, 0x63, 0x6b, 0x65,
+	0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x12,
+	0x37, 0x0a, 0x0c, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x43, 0x61,
+	0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x63, 0x61, 0x6e, 0x63,
+	0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x0a, 0x09, 0x64, 0x65, 0x70, 0x61,
+	0x72, 0x74, 0x75, 0x72, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x72,
+	0x61, 0x69, 0x6e, 0x2e, 0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x52, 0x09, 0x64,
+	0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x22, 0x44, 0x0a, 0x15, 0x42, 0x6f, 0x6f, 0x6b,
+	0x69, 0x6e, 0x67, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x2b, 0x0a, 0x11, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x66,
+	0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x62, 0x6f,
+	0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x22, 0x3d,
+	0x0a, 0x0e, 0x42, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79,
+	0x12, 0x2b, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x42, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2a, 0x5e, 0x0a,
+	0x0d, 0x50, 0x61, 0x73, 0x73, 0x65, 0x6e, 0x67, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18,
+	0x0a, 0x14, 0x50, 0x41, 0x53, 0x53, 0x45, 0x4e, 0x47, 0x45, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x41, 0x44, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x50, 0x41, 0x53, 0x53,
+	0x45, 0x4e, 0x47, 0x45, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x48, 0x49, 0x4c, 0x44,
+	0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x50, 0x41, 0x53, 0x53, 0x45, 0x4e, 0x47, 0x45, 0x52, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x45, 0x4e, 0x49, 0x4f, 0x52, 0x10, 0x02, 0x2a, 0xca, 0x01,
+	0x0a, 0x0d, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x1e, 0x0a, 0x1a, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12,
+	0x1d, 0x0a, 0x19, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x41, 0x55, 0x54, 0x48, 0x4f, 0x52, 0x49, 0x5a, 0x45, 0x44, 0x10, 0x01, 0x12, 0x1b,
+	0x0a, 0x17, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53,
+	0x5f, 0x43, 0x41, 0x50, 0x54, 0x55, 0x52, 0x45, 0x44, 0x10, 0x02, 0x12, 0x19, 0x0a, 0x15, 0x50,
+	0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x56, 0x4f,
+	0x49, 0x44, 0x45, 0x44, 0x10, 0x03, 0x12, 0x1b, 0x0a, 0x17, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e,
+	0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x52, 0x45, 0x46, 0x55, 0x4e, 0x44, 0x45,
+	0x44, 0x10, 0x04, 0x12, 0x25, 0x0a, 0x21, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x53,
+	0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x41, 0x4c, 0x4c, 0x59, 0x5f,
+	0x52, 0x45, 0x46, 0x55, 0x4e, 0x44, 0x45, 0x44, 0x10, 0x05, 0x2a, 0x58, 0x0a, 0x0c, 0x53, 0x65,
+	0x61, 0x74, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x15, 0x0a, 0x11, 0x53, 0x45,
+	0x41, 0x54, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x41, 0x4e, 0x59, 0x10,
+	0x00, 0x12, 0x18, 0x0a, 0x14, 0x53, 0x45, 0x41, 0x54, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49,
+	0x4f, 0x4e, 0x5f, 0x57, 0x49, 0x4e, 0x44, 0x4f, 0x57, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x53,
+	0x45, 0x41, 0x54, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x41, 0x49, 0x53,
+	0x4c, 0x45, 0x10, 0x02, 0x2a, 0x5d, 0x0a, 0x0a, 0x53, 0x77, 0x61, 0x70, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x1b, 0x0a, 0x17, 0x53, 0x57, 0x41, 0x50, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12,
+	0x17, 0x0a, 0x13, 0x53, 0x57, 0x41, 0x50, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x50,
+	0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x53, 0x57, 0x41, 0x50,
+	0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45,
+	0x44, 0x10, 0x02, 0x2a, 0x4a, 0x0a, 0x09, 0x53, 0x65, 0x61, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x12, 0x13, 0x0a, 0x0f, 0x53, 0x45, 0x41, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x46,
+	0x52, 0x45, 0x45, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x45, 0x41, 0x54, 0x5f, 0x53, 0x54,
+	0x41, 0x54, 0x45, 0x5f, 0x53, 0x4f, 0x4c, 0x44, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x45,
+	0x41, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x48, 0x45, 0x4c, 0x44, 0x10, 0x02, 0x2a,
+	0x84, 0x02, 0x0a, 0x10, 0x42, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x22, 0x0a, 0x1e, 0x42, 0x4f, 0x4f, 0x4b, 0x49, 0x4e, 0x47, 0x5f,
+	0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
+	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x27, 0x0a, 0x23, 0x42, 0x4f, 0x4f, 0x4b,
+	0x49, 0x4e, 0x47, 0x5f, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x54,
+	0x49, 0x43, 0x4b, 0x45, 0x54, 0x5f, 0x50, 0x55, 0x52, 0x43, 0x48, 0x41, 0x53, 0x45, 0x44, 0x10,
+	0x01, 0x12, 0x24, 0x0a, 0x20, 0x42, 0x4f, 0x4f, 0x4b, 0x49, 0x4e, 0x47, 0x5f, 0x45, 0x56, 0x45,
+	0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x45, 0x41, 0x54, 0x5f, 0x4d, 0x4f, 0x44,
+	0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x02, 0x12, 0x27, 0x0a, 0x23, 0x42, 0x4f, 0x4f, 0x4b, 0x49,
+	0x4e, 0x47, 0x5f, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x54, 0x49,
+	0x43, 0x4b, 0x45, 0x54, 0x5f, 0x43, 0x41, 0x4e, 0x43, 0x45, 0x4c, 0x4c, 0x45, 0x44, 0x10, 0x03,
+	0x12, 0x28, 0x0a, 0x24, 0x42, 0x4f, 0x4f, 0x4b, 0x49, 0x4e, 0x47, 0x5f, 0x45, 0x56, 0x45, 0x4e,
+	0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x45, 0x50, 0x41, 0x52, 0x54, 0x55, 0x52, 0x45,
+	0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x44, 0x10, 0x04, 0x12, 0x2a, 0x0a, 0x26, 0x42, 0x4f,
+	0x4f, 0x4b, 0x49, 0x4e, 0x47, 0x5f, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x44, 0x45, 0x50, 0x41, 0x52, 0x54, 0x55, 0x52, 0x45, 0x5f, 0x43, 0x41, 0x4e, 0x43, 0x45,
+	0x4c, 0x4c, 0x45, 0x44, 0x10, 0x05, 0x32, 0x81, 0x11, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x69, 0x6e,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5c, 0x0a, 0x0e, 0x50, 0x75, 0x72, 0x63, 0x68,
+	0x61, 0x73, 0x65, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x1c, 0x2e, 0x74, 0x72, 0x61, 0x69,
+	0x6e, 0x2e, 0x50, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e,
+	0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x22, 0x16, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x10, 0x3a, 0x01, 0x2a, 0x22, 0x0b, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x69,
+	0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x64, 0x0a, 0x13, 0x50, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73,
+	0x65, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x21, 0x2e, 0x74,
+	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x50, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x47, 0x72, 0x6f,
+	0x75, 0x70, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x13, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65, 0x63,
+	0x65, 0x69, 0x70, 0x74, 0x22, 0x15, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0f, 0x3a, 0x01, 0x2a, 0x22,
+	0x0a, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x7e, 0x0a, 0x0a, 0x47,
+	0x65, 0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x12, 0x12, 0x2e, 0x74, 0x72, 0x61, 0x69,
+	0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e,
 	0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x63, 0x65,
-	0x69, 0x70, 0x74, 0x42, 0x12, 0x5a, 0x10, 0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x3b, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x69, 0x70, 0x74, 0x22, 0x46, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x40, 0x5a, 0x29, 0x12, 0x27, 0x2f,
+	0x76, 0x31, 0x2f, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x73, 0x2f, 0x7b, 0x62, 0x6f, 0x6f,
+	0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x7d, 0x2f,
+	0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x13, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x69, 0x63, 0x6b,
+	0x65, 0x74, 0x73, 0x2f, 0x7b, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x7d, 0x12, 0x66, 0x0a, 0x11, 0x47,
+	0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x73, 0x42, 0x79, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x15, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e,
+	0x55, 0x73, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x24, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x12, 0x1c, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x2f, 0x7b, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x7d, 0x2f, 0x75, 0x73,
+	0x65, 0x72, 0x73, 0x12, 0x53, 0x0a, 0x0a, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x55, 0x73, 0x65,
+	0x72, 0x12, 0x12, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4,
+	0x93, 0x02, 0x15, 0x2a, 0x13, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73,
+	0x2f, 0x7b, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x7d, 0x12, 0x65, 0x0a, 0x0e, 0x4d, 0x6f, 0x64, 0x69,
+	0x66, 0x79, 0x55, 0x73, 0x65, 0x72, 0x53, 0x65, 0x61, 0x74, 0x12, 0x18, 0x2e, 0x74, 0x72, 0x61,
+	0x69, 0x6e, 0x2e, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x53, 0x65, 0x61, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x54, 0x69, 0x63,
+	0x6b, 0x65, 0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93,
+	0x02, 0x1d, 0x3a, 0x01, 0x2a, 0x32, 0x18, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x69, 0x63, 0x6b, 0x65,
+	0x74, 0x73, 0x2f, 0x7b, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x7d, 0x2f, 0x73, 0x65, 0x61, 0x74, 0x12,
+	0x63, 0x0a, 0x09, 0x53, 0x77, 0x61, 0x70, 0x53, 0x65, 0x61, 0x74, 0x73, 0x12, 0x17, 0x2e, 0x74,
+	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x77, 0x61, 0x70, 0x53, 0x65, 0x61, 0x74, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x77,
+	0x61, 0x70, 0x53, 0x65, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x3a, 0x01, 0x2a, 0x22, 0x18, 0x2f, 0x76, 0x31, 0x2f,
+	0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x2f, 0x7b, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x7d, 0x2f,
+	0x73, 0x77, 0x61, 0x70, 0x12, 0x60, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x53, 0x65, 0x61, 0x74, 0x4d,
+	0x61, 0x70, 0x12, 0x15, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74, 0x4d,
+	0x61, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x74, 0x72, 0x61, 0x69,
+	0x6e, 0x2e, 0x53, 0x65, 0x61, 0x74, 0x4d, 0x61, 0x70, 0x22, 0x2b, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x25, 0x12, 0x23, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65,
+	0x73, 0x2f, 0x7b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x7d,
+	0x2f, 0x73, 0x65, 0x61, 0x74, 0x73, 0x12, 0x84, 0x01, 0x0a, 0x11, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x1f, 0x2e, 0x74,
+	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e,
+	0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69,
+	0x74, 0x79, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x32, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x2c, 0x12,
+	0x2a, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x73, 0x2f,
+	0x7b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x61,
+	0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x30, 0x01, 0x12, 0x63, 0x0a,
+	0x12, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x46, 0x6f, 0x72, 0x55,
+	0x73, 0x65, 0x72, 0x12, 0x12, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x55, 0x73, 0x65, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e,
+	0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x12, 0x19, 0x2f, 0x76, 0x31, 0x2f, 0x75, 0x73, 0x65,
+	0x72, 0x73, 0x2f, 0x7b, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x7d, 0x2f, 0x74, 0x69, 0x63, 0x6b, 0x65,
+	0x74, 0x73, 0x12, 0x66, 0x0a, 0x09, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x46, 0x61, 0x72, 0x65, 0x12,
+	0x17, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x46, 0x61, 0x72,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e,
+	0x2e, 0x46, 0x61, 0x72, 0x65, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x22, 0x2a,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x24, 0x12, 0x22, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x65, 0x70, 0x61,
+	0x72, 0x74, 0x75, 0x72, 0x65, 0x73, 0x2f, 0x7b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72,
+	0x65, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x66, 0x61, 0x72, 0x65, 0x12, 0x4f, 0x0a, 0x08, 0x48, 0x6f,
+	0x6c, 0x64, 0x53, 0x65, 0x61, 0x74, 0x12, 0x1c, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x50,
+	0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x65, 0x61,
+	0x74, 0x48, 0x6f, 0x6c, 0x64, 0x22, 0x14, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x3a, 0x01, 0x2a,
+	0x22, 0x09, 0x2f, 0x76, 0x31, 0x2f, 0x68, 0x6f, 0x6c, 0x64, 0x73, 0x12, 0x5d, 0x0a, 0x0b, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x48, 0x6f, 0x6c, 0x64, 0x12, 0x12, 0x2e, 0x74, 0x72, 0x61,
+	0x69, 0x6e, 0x2e, 0x48, 0x6f, 0x6c, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14,
+	0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x63,
+	0x65, 0x69, 0x70, 0x74, 0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x3a, 0x01, 0x2a, 0x22,
+	0x19, 0x2f, 0x76, 0x31, 0x2f, 0x68, 0x6f, 0x6c, 0x64, 0x73, 0x2f, 0x7b, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x7d, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x12, 0x66, 0x0a, 0x0c, 0x43, 0x61,
+	0x6e, 0x63, 0x65, 0x6c, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x1a, 0x2e, 0x74, 0x72, 0x61,
+	0x69, 0x6e, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x43,
+	0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x25, 0x82, 0xd3, 0xe4,
+	0x93, 0x02, 0x1f, 0x3a, 0x01, 0x2a, 0x22, 0x1a, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x69, 0x63, 0x6b,
+	0x65, 0x74, 0x73, 0x2f, 0x7b, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x7d, 0x2f, 0x63, 0x61, 0x6e, 0x63,
+	0x65, 0x6c, 0x12, 0x7a, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x42, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67,
+	0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x1c, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e,
+	0x42, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x42, 0x6f,
+	0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x22, 0x30, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x2a, 0x12, 0x28, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e,
+	0x67, 0x73, 0x2f, 0x7b, 0x62, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x66, 0x65,
+	0x72, 0x65, 0x6e, 0x63, 0x65, 0x7d, 0x2f, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x55,
+	0x0a, 0x0c, 0x4a, 0x6f, 0x69, 0x6e, 0x57, 0x61, 0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x16,
+	0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x57, 0x61, 0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x57,
+	0x61, 0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x22, 0x17, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x11, 0x3a, 0x01, 0x2a, 0x22, 0x0c, 0x2f, 0x76, 0x31, 0x2f, 0x77, 0x61, 0x69,
+	0x74, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x63, 0x0a, 0x0d, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x57, 0x61,
+	0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1b, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x57,
+	0x61, 0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1f, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x19, 0x2a, 0x17, 0x2f, 0x76, 0x31, 0x2f, 0x77, 0x61, 0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x2f,
+	0x7b, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x69, 0x64, 0x7d, 0x12, 0x69, 0x0a, 0x13, 0x47, 0x65,
+	0x74, 0x57, 0x61, 0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x1b, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x57, 0x61, 0x69, 0x74, 0x6c, 0x69,
+	0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14,
+	0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x57, 0x61, 0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x22, 0x1f, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x19, 0x12, 0x17, 0x2f, 0x76,
+	0x31, 0x2f, 0x77, 0x61, 0x69, 0x74, 0x6c, 0x69, 0x73, 0x74, 0x2f, 0x7b, 0x65, 0x6e, 0x74, 0x72,
+	0x79, 0x5f, 0x69, 0x64, 0x7d, 0x12, 0x5d, 0x0a, 0x0f, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44,
+	0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1d, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e,
+	0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x22, 0x19, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x13, 0x3a, 0x01, 0x2a, 0x22, 0x0e, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74,
+	0x75, 0x72, 0x65, 0x73, 0x12, 0x65, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x70, 0x61,
+	0x72, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x1c, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x16, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x10, 0x12, 0x0e, 0x2f, 0x76, 0x31,
+	0x2f, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x6d, 0x0a, 0x0f, 0x43,
+	0x61, 0x6e, 0x63, 0x65, 0x6c, 0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x12, 0x17,
+	0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x2e,
+	0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x22, 0x2f, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x29, 0x3a, 0x01, 0x2a, 0x22, 0x24, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74,
+	0x75, 0x72, 0x65, 0x73, 0x2f, 0x7b, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x5f,
+	0x69, 0x64, 0x7d, 0x2f, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x42, 0x12, 0x5a, 0x10, 0x2e, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x3b, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -586,38 +4337,161 @@ func file_train_schema_proto_rawDescGZIP() []byte {
 	return file_train_schema_proto_rawDescData
 }
 
-var file_train_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_train_schema_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_train_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 44)
 var file_train_schema_proto_goTypes = []any{
-	(*User)(nil),                  // 0: train.User
-	(*SeatAllocation)(nil),        // 1: train.SeatAllocation
-	(*TicketReceipt)(nil),         // 2: train.TicketReceipt
-	(*PurchaseTicketRequest)(nil), // 3: train.PurchaseTicketRequest
-	(*UserRequest)(nil),           // 4: train.UserRequest
-	(*SectionRequest)(nil),        // 5: train.SectionRequest
-	(*UsersResponse)(nil),         // 6: train.UsersResponse
-	(*ModifySeatRequest)(nil),     // 7: train.ModifySeatRequest
-	(*EmptyResponse)(nil),         // 8: train.EmptyResponse
+	(PassengerType)(0),                 // 0: train.PassengerType
+	(PaymentStatus)(0),                 // 1: train.PaymentStatus
+	(SeatPosition)(0),                  // 2: train.SeatPosition
+	(SwapStatus)(0),                    // 3: train.SwapStatus
+	(SeatState)(0),                     // 4: train.SeatState
+	(BookingEventType)(0),              // 5: train.BookingEventType
+	(*User)(nil),                       // 6: train.User
+	(*SeatAllocation)(nil),             // 7: train.SeatAllocation
+	(*FareComponent)(nil),              // 8: train.FareComponent
+	(*FareBreakdown)(nil),              // 9: train.FareBreakdown
+	(*PaymentInfo)(nil),                // 10: train.PaymentInfo
+	(*TicketReceipt)(nil),              // 11: train.TicketReceipt
+	(*PurchaseTicketRequest)(nil),      // 12: train.PurchaseTicketRequest
+	(*GroupPassenger)(nil),             // 13: train.GroupPassenger
+	(*PurchaseGroupTicketRequest)(nil), // 14: train.PurchaseGroupTicketRequest
+	(*GroupReceipt)(nil),               // 15: train.GroupReceipt
+	(*SeatPreferences)(nil),            // 16: train.SeatPreferences
+	(*UserRequest)(nil),                // 17: train.UserRequest
+	(*SectionRequest)(nil),             // 18: train.SectionRequest
+	(*UsersResponse)(nil),              // 19: train.UsersResponse
+	(*TicketsResponse)(nil),            // 20: train.TicketsResponse
+	(*ModifySeatRequest)(nil),          // 21: train.ModifySeatRequest
+	(*SwapSeatsRequest)(nil),           // 22: train.SwapSeatsRequest
+	(*SwapSeatsResponse)(nil),          // 23: train.SwapSeatsResponse
+	(*EmptyResponse)(nil),              // 24: train.EmptyResponse
+	(*SeatAttributes)(nil),             // 25: train.SeatAttributes
+	(*SectionLayout)(nil),              // 26: train.SectionLayout
+	(*Departure)(nil),                  // 27: train.Departure
+	(*CreateDepartureRequest)(nil),     // 28: train.CreateDepartureRequest
+	(*ListDeparturesRequest)(nil),      // 29: train.ListDeparturesRequest
+	(*ListDeparturesResponse)(nil),     // 30: train.ListDeparturesResponse
+	(*DepartureRequest)(nil),           // 31: train.DepartureRequest
+	(*SeatStatus)(nil),                 // 32: train.SeatStatus
+	(*SectionMap)(nil),                 // 33: train.SectionMap
+	(*SeatHold)(nil),                   // 34: train.SeatHold
+	(*HoldRequest)(nil),                // 35: train.HoldRequest
+	(*CancelTicketRequest)(nil),        // 36: train.CancelTicketRequest
+	(*Cancellation)(nil),               // 37: train.Cancellation
+	(*WaitlistRequest)(nil),            // 38: train.WaitlistRequest
+	(*WaitlistEntry)(nil),              // 39: train.WaitlistEntry
+	(*WaitlistEntryRequest)(nil),       // 40: train.WaitlistEntryRequest
+	(*QuoteFareRequest)(nil),           // 41: train.QuoteFareRequest
+	(*SeatMapRequest)(nil),             // 42: train.SeatMapRequest
+	(*SeatMap)(nil),                    // 43: train.SeatMap
+	(*WatchAvailabilityRequest)(nil),   // 44: train.WatchAvailabilityRequest
+	(*SeatChange)(nil),                 // 45: train.SeatChange
+	(*AvailabilityEvent)(nil),          // 46: train.AvailabilityEvent
+	(*BookingEvent)(nil),               // 47: train.BookingEvent
+	(*BookingHistoryRequest)(nil),      // 48: train.BookingHistoryRequest
+	(*BookingHistory)(nil),             // 49: train.BookingHistory
+	(*timestamppb.Timestamp)(nil),      // 50: google.protobuf.Timestamp
 }
 var file_train_schema_proto_depIdxs = []int32{
-	0, // 0: train.TicketReceipt.user:type_name -> train.User
-	1, // 1: train.TicketReceipt.seat:type_name -> train.SeatAllocation
-	0, // 2: train.PurchaseTicketRequest.user:type_name -> train.User
-	0, // 3: train.UsersResponse.users:type_name -> train.User
-	3, // 4: train.TrainService.PurchaseTicket:input_type -> train.PurchaseTicketRequest
-	4, // 5: train.TrainService.GetReceipt:input_type -> train.UserRequest
-	5, // 6: train.TrainService.GetUsersBySection:input_type -> train.SectionRequest
-	4, // 7: train.TrainService.RemoveUser:input_type -> train.UserRequest
-	7, // 8: train.TrainService.ModifyUserSeat:input_type -> train.ModifySeatRequest
-	2, // 9: train.TrainService.PurchaseTicket:output_type -> train.TicketReceipt
-	2, // 10: train.TrainService.GetReceipt:output_type -> train.TicketReceipt
-	6, // 11: train.TrainService.GetUsersBySection:output_type -> train.UsersResponse
-	8, // 12: train.TrainService.RemoveUser:output_type -> train.EmptyResponse
-	2, // 13: train.TrainService.ModifyUserSeat:output_type -> train.TicketReceipt
-	9, // [9:14] is the sub-list for method output_type
-	4, // [4:9] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	8,  // 0: train.FareBreakdown.components:type_name -> train.FareComponent
+	1,  // 1: train.PaymentInfo.status:type_name -> train.PaymentStatus
+	6,  // 2: train.TicketReceipt.user:type_name -> train.User
+	7,  // 3: train.TicketReceipt.seat:type_name -> train.SeatAllocation
+	9,  // 4: train.TicketReceipt.fare:type_name -> train.FareBreakdown
+	0,  // 5: train.TicketReceipt.passenger_type:type_name -> train.PassengerType
+	10, // 6: train.TicketReceipt.payment:type_name -> train.PaymentInfo
+	37, // 7: train.TicketReceipt.pending_cancellation:type_name -> train.Cancellation
+	6,  // 8: train.PurchaseTicketRequest.user:type_name -> train.User
+	0,  // 9: train.PurchaseTicketRequest.passenger_type:type_name -> train.PassengerType
+	16, // 10: train.PurchaseTicketRequest.preferences:type_name -> train.SeatPreferences
+	6,  // 11: train.GroupPassenger.user:type_name -> train.User
+	0,  // 12: train.GroupPassenger.passenger_type:type_name -> train.PassengerType
+	13, // 13: train.PurchaseGroupTicketRequest.passengers:type_name -> train.GroupPassenger
+	16, // 14: train.PurchaseGroupTicketRequest.preferences:type_name -> train.SeatPreferences
+	11, // 15: train.GroupReceipt.tickets:type_name -> train.TicketReceipt
+	10, // 16: train.GroupReceipt.payment:type_name -> train.PaymentInfo
+	2,  // 17: train.SeatPreferences.position:type_name -> train.SeatPosition
+	6,  // 18: train.UsersResponse.users:type_name -> train.User
+	11, // 19: train.TicketsResponse.tickets:type_name -> train.TicketReceipt
+	3,  // 20: train.SwapSeatsResponse.status:type_name -> train.SwapStatus
+	11, // 21: train.SwapSeatsResponse.ticket:type_name -> train.TicketReceipt
+	50, // 22: train.SwapSeatsResponse.consent_expires:type_name -> google.protobuf.Timestamp
+	25, // 23: train.SectionLayout.seat_attributes:type_name -> train.SeatAttributes
+	26, // 24: train.Departure.sections:type_name -> train.SectionLayout
+	26, // 25: train.CreateDepartureRequest.sections:type_name -> train.SectionLayout
+	27, // 26: train.ListDeparturesResponse.departures:type_name -> train.Departure
+	4,  // 27: train.SeatStatus.state:type_name -> train.SeatState
+	32, // 28: train.SectionMap.seats:type_name -> train.SeatStatus
+	7,  // 29: train.SeatHold.seat:type_name -> train.SeatAllocation
+	50, // 30: train.SeatHold.expires_at:type_name -> google.protobuf.Timestamp
+	9,  // 31: train.SeatHold.fare:type_name -> train.FareBreakdown
+	6,  // 32: train.Cancellation.user:type_name -> train.User
+	50, // 33: train.Cancellation.cancelled_at:type_name -> google.protobuf.Timestamp
+	10, // 34: train.Cancellation.payment:type_name -> train.PaymentInfo
+	6,  // 35: train.WaitlistRequest.user:type_name -> train.User
+	0,  // 36: train.WaitlistRequest.passenger_type:type_name -> train.PassengerType
+	6,  // 37: train.WaitlistEntry.user:type_name -> train.User
+	50, // 38: train.WaitlistEntry.joined_at:type_name -> google.protobuf.Timestamp
+	34, // 39: train.WaitlistEntry.offered_hold:type_name -> train.SeatHold
+	0,  // 40: train.QuoteFareRequest.passenger_type:type_name -> train.PassengerType
+	33, // 41: train.SeatMap.sections:type_name -> train.SectionMap
+	4,  // 42: train.SeatChange.state:type_name -> train.SeatState
+	43, // 43: train.AvailabilityEvent.snapshot:type_name -> train.SeatMap
+	45, // 44: train.AvailabilityEvent.change:type_name -> train.SeatChange
+	5,  // 45: train.BookingEvent.type:type_name -> train.BookingEventType
+	50, // 46: train.BookingEvent.at:type_name -> google.protobuf.Timestamp
+	11, // 47: train.BookingEvent.before:type_name -> train.TicketReceipt
+	11, // 48: train.BookingEvent.after:type_name -> train.TicketReceipt
+	37, // 49: train.BookingEvent.cancellation:type_name -> train.Cancellation
+	27, // 50: train.BookingEvent.departure:type_name -> train.Departure
+	47, // 51: train.BookingHistory.events:type_name -> train.BookingEvent
+	12, // 52: train.TrainService.PurchaseTicket:input_type -> train.PurchaseTicketRequest
+	14, // 53: train.TrainService.PurchaseGroupTicket:input_type -> train.PurchaseGroupTicketRequest
+	17, // 54: train.TrainService.GetReceipt:input_type -> train.UserRequest
+	18, // 55: train.TrainService.GetUsersBySection:input_type -> train.SectionRequest
+	17, // 56: train.TrainService.RemoveUser:input_type -> train.UserRequest
+	21, // 57: train.TrainService.ModifyUserSeat:input_type -> train.ModifySeatRequest
+	22, // 58: train.TrainService.SwapSeats:input_type -> train.SwapSeatsRequest
+	42, // 59: train.TrainService.GetSeatMap:input_type -> train.SeatMapRequest
+	44, // 60: train.TrainService.WatchAvailability:input_type -> train.WatchAvailabilityRequest
+	17, // 61: train.TrainService.ListTicketsForUser:input_type -> train.UserRequest
+	41, // 62: train.TrainService.QuoteFare:input_type -> train.QuoteFareRequest
+	12, // 63: train.TrainService.HoldSeat:input_type -> train.PurchaseTicketRequest
+	35, // 64: train.TrainService.ConfirmHold:input_type -> train.HoldRequest
+	36, // 65: train.TrainService.CancelTicket:input_type -> train.CancelTicketRequest
+	48, // 66: train.TrainService.GetBookingHistory:input_type -> train.BookingHistoryRequest
+	38, // 67: train.TrainService.JoinWaitlist:input_type -> train.WaitlistRequest
+	40, // 68: train.TrainService.LeaveWaitlist:input_type -> train.WaitlistEntryRequest
+	40, // 69: train.TrainService.GetWaitlistPosition:input_type -> train.WaitlistEntryRequest
+	28, // 70: train.TrainService.CreateDeparture:input_type -> train.CreateDepartureRequest
+	29, // 71: train.TrainService.ListDepartures:input_type -> train.ListDeparturesRequest
+	31, // 72: train.TrainService.CancelDeparture:input_type -> train.DepartureRequest
+	11, // 73: train.TrainService.PurchaseTicket:output_type -> train.TicketReceipt
+	15, // 74: train.TrainService.PurchaseGroupTicket:output_type -> train.GroupReceipt
+	11, // 75: train.TrainService.GetReceipt:output_type -> train.TicketReceipt
+	19, // 76: train.TrainService.GetUsersBySection:output_type -> train.UsersResponse
+	24, // 77: train.TrainService.RemoveUser:output_type -> train.EmptyResponse
+	11, // 78: train.TrainService.ModifyUserSeat:output_type -> train.TicketReceipt
+	23, // 79: train.TrainService.SwapSeats:output_type -> train.SwapSeatsResponse
+	43, // 80: train.TrainService.GetSeatMap:output_type -> train.SeatMap
+	46, // 81: train.TrainService.WatchAvailability:output_type -> train.AvailabilityEvent
+	20, // 82: train.TrainService.ListTicketsForUser:output_type -> train.TicketsResponse
+	9,  // 83: train.TrainService.QuoteFare:output_type -> train.FareBreakdown
+	34, // 84: train.TrainService.HoldSeat:output_type -> train.SeatHold
+	11, // 85: train.TrainService.ConfirmHold:output_type -> train.TicketReceipt
+	37, // 86: train.TrainService.CancelTicket:output_type -> train.Cancellation
+	49, // 87: train.TrainService.GetBookingHistory:output_type -> train.BookingHistory
+	39, // 88: train.TrainService.JoinWaitlist:output_type -> train.WaitlistEntry
+	24, // 89: train.TrainService.LeaveWaitlist:output_type -> train.EmptyResponse
+	39, // 90: train.TrainService.GetWaitlistPosition:output_type -> train.WaitlistEntry
+	27, // 91: train.TrainService.CreateDeparture:output_type -> train.Departure
+	30, // 92: train.TrainService.ListDepartures:output_type -> train.ListDeparturesResponse
+	27, // 93: train.TrainService.CancelDeparture:output_type -> train.Departure
+	73, // [73:94] is the sub-list for method output_type
+	52, // [52:73] is the sub-list for method input_type
+	52, // [52:52] is the sub-list for extension type_name
+	52, // [52:52] is the sub-list for extension extendee
+	0,  // [0:52] is the sub-list for field type_name
 }
 
 func init() { file_train_schema_proto_init() }
@@ -625,18 +4499,23 @@ func file_train_schema_proto_init() {
 	if File_train_schema_proto != nil {
 		return
 	}
+	file_train_schema_proto_msgTypes[40].OneofWrappers = []any{
+		(*AvailabilityEvent_Snapshot)(nil),
+		(*AvailabilityEvent_Change)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_train_schema_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   9,
+			NumEnums:      6,
+			NumMessages:   44,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_train_schema_proto_goTypes,
 		DependencyIndexes: file_train_schema_proto_depIdxs,
+		EnumInfos:         file_train_schema_proto_enumTypes,
 		MessageInfos:      file_train_schema_proto_msgTypes,
 	}.Build()
 	File_train_schema_proto = out.File
@@ -19,22 +19,59 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TrainService_PurchaseTicket_FullMethodName    = "/train.TrainService/PurchaseTicket"
-	TrainService_GetReceipt_FullMethodName        = "/train.TrainService/GetReceipt"
-	TrainService_GetUsersBySection_FullMethodName = "/train.TrainService/GetUsersBySection"
-	TrainService_RemoveUser_FullMethodName        = "/train.TrainService/RemoveUser"
-	TrainService_ModifyUserSeat_FullMethodName    = "/train.TrainService/ModifyUserSeat"
+	TrainService_PurchaseTicket_FullMethodName      = "/train.TrainService/PurchaseTicket"
+	TrainService_PurchaseGroupTicket_FullMethodName = "/train.TrainService/PurchaseGroupTicket"
+	TrainService_GetReceipt_FullMethodName          = "/train.TrainService/GetReceipt"
+	TrainService_GetUsersBySection_FullMethodName   = "/train.TrainService/GetUsersBySection"
+	TrainService_RemoveUser_FullMethodName          = "/train.TrainService/RemoveUser"
+	TrainService_ModifyUserSeat_FullMethodName      = "/train.TrainService/ModifyUserSeat"
+	TrainService_SwapSeats_FullMethodName           = "/train.TrainService/SwapSeats"
+	TrainService_GetSeatMap_FullMethodName          = "/train.TrainService/GetSeatMap"
+	TrainService_WatchAvailability_FullMethodName   = "/train.TrainService/WatchAvailability"
+	TrainService_ListTicketsForUser_FullMethodName  = "/train.TrainService/ListTicketsForUser"
+	TrainService_QuoteFare_FullMethodName           = "/train.TrainService/QuoteFare"
+	TrainService_HoldSeat_FullMethodName            = "/train.TrainService/HoldSeat"
+	TrainService_ConfirmHold_FullMethodName         = "/train.TrainService/ConfirmHold"
+	TrainService_CancelTicket_FullMethodName        = "/train.TrainService/CancelTicket"
+	TrainService_GetBookingHistory_FullMethodName   = "/train.TrainService/GetBookingHistory"
+	TrainService_JoinWaitlist_FullMethodName        = "/train.TrainService/JoinWaitlist"
+	TrainService_LeaveWaitlist_FullMethodName       = "/train.TrainService/LeaveWaitlist"
+	TrainService_GetWaitlistPosition_FullMethodName = "/train.TrainService/GetWaitlistPosition"
+	TrainService_CreateDeparture_FullMethodName     = "/train.TrainService/CreateDeparture"
+	TrainService_ListDepartures_FullMethodName      = "/train.TrainService/ListDepartures"
+	TrainService_CancelDeparture_FullMethodName     = "/train.TrainService/CancelDeparture"
 )
 
 // TrainServiceClient is the client API for TrainService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// HTTP bindings are served as JSON by the gateway; see gateway/ and
+// openapi.json. Request fields not bound by the path or body are read from
+// the query string.
 type TrainServiceClient interface {
 	PurchaseTicket(ctx context.Context, in *PurchaseTicketRequest, opts ...grpc.CallOption) (*TicketReceipt, error)
+	PurchaseGroupTicket(ctx context.Context, in *PurchaseGroupTicketRequest, opts ...grpc.CallOption) (*GroupReceipt, error)
 	GetReceipt(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*TicketReceipt, error)
 	GetUsersBySection(ctx context.Context, in *SectionRequest, opts ...grpc.CallOption) (*UsersResponse, error)
 	RemoveUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
 	ModifyUserSeat(ctx context.Context, in *ModifySeatRequest, opts ...grpc.CallOption) (*TicketReceipt, error)
+	SwapSeats(ctx context.Context, in *SwapSeatsRequest, opts ...grpc.CallOption) (*SwapSeatsResponse, error)
+	GetSeatMap(ctx context.Context, in *SeatMapRequest, opts ...grpc.CallOption) (*SeatMap, error)
+	WatchAvailability(ctx context.Context, in *WatchAvailabilityRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AvailabilityEvent], error)
+	ListTicketsForUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*TicketsResponse, error)
+	QuoteFare(ctx context.Context, in *QuoteFareRequest, opts ...grpc.CallOption) (*FareBreakdown, error)
+	HoldSeat(ctx context.Context, in *PurchaseTicketRequest, opts ...grpc.CallOption) (*SeatHold, error)
+	ConfirmHold(ctx context.Context, in *HoldRequest, opts ...grpc.CallOption) (*TicketReceipt, error)
+	CancelTicket(ctx context.Context, in *CancelTicketRequest, opts ...grpc.CallOption) (*Cancellation, error)
+	GetBookingHistory(ctx context.Context, in *BookingHistoryRequest, opts ...grpc.CallOption) (*BookingHistory, error)
+	JoinWaitlist(ctx context.Context, in *WaitlistRequest, opts ...grpc.CallOption) (*WaitlistEntry, error)
+	LeaveWaitlist(ctx context.Context, in *WaitlistEntryRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	GetWaitlistPosition(ctx context.Context, in *WaitlistEntryRequest, opts ...grpc.CallOption) (*WaitlistEntry, error)
+	// Admin
+	CreateDeparture(ctx context.Context, in *CreateDepartureRequest, opts ...grpc.CallOption) (*Departure, error)
+	ListDepartures(ctx context.Context, in *ListDeparturesRequest, opts ...grpc.CallOption) (*ListDeparturesResponse, error)
+	CancelDeparture(ctx context.Context, in *DepartureRequest, opts ...grpc.CallOption) (*Departure, error)
 }
 
 type trainServiceClient struct {
@@ -55,6 +92,16 @@ func (c *trainServiceClient) PurchaseTicket(ctx context.Context, in *PurchaseTic
 	return out, nil
 }
 
+func (c *trainServiceClient) PurchaseGroupTicket(ctx context.Context, in *PurchaseGroupTicketRequest, opts ...grpc.CallOption) (*GroupReceipt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GroupReceipt)
+	err := c.cc.Invoke(ctx, TrainService_PurchaseGroupTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *trainServiceClient) GetReceipt(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*TicketReceipt, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TicketReceipt)
@@ -95,15 +142,195 @@ func (c *trainServiceClient) ModifyUserSeat(ctx context.Context, in *ModifySeatR
 	return out, nil
 }
 
+func (c *trainServiceClient) SwapSeats(ctx context.Context, in *SwapSeatsRequest, opts ...grpc.CallOption) (*SwapSeatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SwapSeatsResponse)
+	err := c.cc.Invoke(ctx, TrainService_SwapSeats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) GetSeatMap(ctx context.Context, in *SeatMapRequest, opts ...grpc.CallOption) (*SeatMap, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SeatMap)
+	err := c.cc.Invoke(ctx, TrainService_GetSeatMap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) WatchAvailability(ctx context.Context, in *WatchAvailabilityRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AvailabilityEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TrainService_ServiceDesc.Streams[0], TrainService_WatchAvailability_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchAvailabilityRequest, AvailabilityEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TrainService_WatchAvailabilityClient = grpc.ServerStreamingClient[AvailabilityEvent]
+
+func (c *trainServiceClient) ListTicketsForUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*TicketsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TicketsResponse)
+	err := c.cc.Invoke(ctx, TrainService_ListTicketsForUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) QuoteFare(ctx context.Context, in *QuoteFareRequest, opts ...grpc.CallOption) (*FareBreakdown, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FareBreakdown)
+	err := c.cc.Invoke(ctx, TrainService_QuoteFare_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) HoldSeat(ctx context.Context, in *PurchaseTicketRequest, opts ...grpc.CallOption) (*SeatHold, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SeatHold)
+	err := c.cc.Invoke(ctx, TrainService_HoldSeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) ConfirmHold(ctx context.Context, in *HoldRequest, opts ...grpc.CallOption) (*TicketReceipt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TicketReceipt)
+	err := c.cc.Invoke(ctx, TrainService_ConfirmHold_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) CancelTicket(ctx context.Context, in *CancelTicketRequest, opts ...grpc.CallOption) (*Cancellation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Cancellation)
+	err := c.cc.Invoke(ctx, TrainService_CancelTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) GetBookingHistory(ctx context.Context, in *BookingHistoryRequest, opts ...grpc.CallOption) (*BookingHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookingHistory)
+	err := c.cc.Invoke(ctx, TrainService_GetBookingHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) JoinWaitlist(ctx context.Context, in *WaitlistRequest, opts ...grpc.CallOption) (*WaitlistEntry, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WaitlistEntry)
+	err := c.cc.Invoke(ctx, TrainService_JoinWaitlist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) LeaveWaitlist(ctx context.Context, in *WaitlistEntryRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, TrainService_LeaveWaitlist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) GetWaitlistPosition(ctx context.Context, in *WaitlistEntryRequest, opts ...grpc.CallOption) (*WaitlistEntry, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WaitlistEntry)
+	err := c.cc.Invoke(ctx, TrainService_GetWaitlistPosition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) CreateDeparture(ctx context.Context, in *CreateDepartureRequest, opts ...grpc.CallOption) (*Departure, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Departure)
+	err := c.cc.Invoke(ctx, TrainService_CreateDeparture_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) ListDepartures(ctx context.Context, in *ListDeparturesRequest, opts ...grpc.CallOption) (*ListDeparturesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDeparturesResponse)
+	err := c.cc.Invoke(ctx, TrainService_ListDepartures_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trainServiceClient) CancelDeparture(ctx context.Context, in *DepartureRequest, opts ...grpc.CallOption) (*Departure, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Departure)
+	err := c.cc.Invoke(ctx, TrainService_CancelDeparture_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TrainServiceServer is the server API for TrainService service.
 // All implementations must embed UnimplementedTrainServiceServer
 // for forward compatibility.
+//
+// HTTP bindings are served as JSON by the gateway; see gateway/ and
+// openapi.json. Request fields not bound by the path or body are read from
+// the query string.
 type TrainServiceServer interface {
 	PurchaseTicket(context.Context, *PurchaseTicketRequest) (*TicketReceipt, error)
+	PurchaseGroupTicket(context.Context, *PurchaseGroupTicketRequest) (*GroupReceipt, error)
 	GetReceipt(context.Context, *UserRequest) (*TicketReceipt, error)
 	GetUsersBySection(context.Context, *SectionRequest) (*UsersResponse, error)
 	RemoveUser(context.Context, *UserRequest) (*EmptyResponse, error)
 	ModifyUserSeat(context.Context, *ModifySeatRequest) (*TicketReceipt, error)
+	SwapSeats(context.Context, *SwapSeatsRequest) (*SwapSeatsResponse, error)
+	GetSeatMap(context.Context, *SeatMapRequest) (*SeatMap, error)
+	WatchAvailability(*WatchAvailabilityRequest, grpc.ServerStreamingServer[AvailabilityEvent]) error
+	ListTicketsForUser(context.Context, *UserRequest) (*TicketsResponse, error)
+	QuoteFare(context.Context, *QuoteFareRequest) (*FareBreakdown, error)
+	HoldSeat(context.Context, *PurchaseTicketRequest) (*SeatHold, error)
+	ConfirmHold(context.Context, *HoldRequest) (*TicketReceipt, error)
+	CancelTicket(context.Context, *CancelTicketRequest) (*Cancellation, error)
+	GetBookingHistory(context.Context, *BookingHistoryRequest) (*BookingHistory, error)
+	JoinWaitlist(context.Context, *WaitlistRequest) (*WaitlistEntry, error)
+	LeaveWaitlist(context.Context, *WaitlistEntryRequest) (*EmptyResponse, error)
+	GetWaitlistPosition(context.Context, *WaitlistEntryRequest) (*WaitlistEntry, error)
+	// Admin
+	CreateDeparture(context.Context, *CreateDepartureRequest) (*Departure, error)
+	ListDepartures(context.Context, *ListDeparturesRequest) (*ListDeparturesResponse, error)
+	CancelDeparture(context.Context, *DepartureRequest) (*Departure, error)
 	mustEmbedUnimplementedTrainServiceServer()
 }
 
@@ -117,6 +344,9 @@ type UnimplementedTrainServiceServer struct{}
 func (UnimplementedTrainServiceServer) PurchaseTicket(context.Context, *PurchaseTicketRequest) (*TicketReceipt, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PurchaseTicket not implemented")
 }
+func (UnimplementedTrainServiceServer) PurchaseGroupTicket(context.Context, *PurchaseGroupTicketRequest) (*GroupReceipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurchaseGroupTicket not implemented")
+}
 func (UnimplementedTrainServiceServer) GetReceipt(context.Context, *UserRequest) (*TicketReceipt, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetReceipt not implemented")
 }
@@ -129,6 +359,51 @@ func (UnimplementedTrainServiceServer) RemoveUser(context.Context, *UserRequest)
 func (UnimplementedTrainServiceServer) ModifyUserSeat(context.Context, *ModifySeatRequest) (*TicketReceipt, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ModifyUserSeat not implemented")
 }
+func (UnimplementedTrainServiceServer) SwapSeats(context.Context, *SwapSeatsRequest) (*SwapSeatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SwapSeats not implemented")
+}
+func (UnimplementedTrainServiceServer) GetSeatMap(context.Context, *SeatMapRequest) (*SeatMap, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSeatMap not implemented")
+}
+func (UnimplementedTrainServiceServer) WatchAvailability(*WatchAvailabilityRequest, grpc.ServerStreamingServer[AvailabilityEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAvailability not implemented")
+}
+func (UnimplementedTrainServiceServer) ListTicketsForUser(context.Context, *UserRequest) (*TicketsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTicketsForUser not implemented")
+}
+func (UnimplementedTrainServiceServer) QuoteFare(context.Context, *QuoteFareRequest) (*FareBreakdown, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QuoteFare not implemented")
+}
+func (UnimplementedTrainServiceServer) HoldSeat(context.Context, *PurchaseTicketRequest) (*SeatHold, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HoldSeat not implemented")
+}
+func (UnimplementedTrainServiceServer) ConfirmHold(context.Context, *HoldRequest) (*TicketReceipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmHold not implemented")
+}
+func (UnimplementedTrainServiceServer) CancelTicket(context.Context, *CancelTicketRequest) (*Cancellation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelTicket not implemented")
+}
+func (UnimplementedTrainServiceServer) GetBookingHistory(context.Context, *BookingHistoryRequest) (*BookingHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBookingHistory not implemented")
+}
+func (UnimplementedTrainServiceServer) JoinWaitlist(context.Context, *WaitlistRequest) (*WaitlistEntry, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinWaitlist not implemented")
+}
+func (UnimplementedTrainServiceServer) LeaveWaitlist(context.Context, *WaitlistEntryRequest) (*EmptyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaveWaitlist not implemented")
+}
+func (UnimplementedTrainServiceServer) GetWaitlistPosition(context.Context, *WaitlistEntryRequest) (*WaitlistEntry, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWaitlistPosition not implemented")
+}
+func (UnimplementedTrainServiceServer) CreateDeparture(context.Context, *CreateDepartureRequest) (*Departure, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDeparture not implemented")
+}
+func (UnimplementedTrainServiceServer) ListDepartures(context.Context, *ListDeparturesRequest) (*ListDeparturesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDepartures not implemented")
+}
+func (UnimplementedTrainServiceServer) CancelDeparture(context.Context, *DepartureRequest) (*Departure, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelDeparture not implemented")
+}
 func (UnimplementedTrainServiceServer) mustEmbedUnimplementedTrainServiceServer() {}
 func (UnimplementedTrainServiceServer) testEmbeddedByValue()                      {}
 
@@ -168,6 +443,24 @@ func _TrainService_PurchaseTicket_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrainService_PurchaseGroupTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurchaseGroupTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).PurchaseGroupTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_PurchaseGroupTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).PurchaseGroupTicket(ctx, req.(*PurchaseGroupTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TrainService_GetReceipt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UserRequest)
 	if err := dec(in); err != nil {
@@ -240,6 +533,269 @@ func _TrainService_ModifyUserSeat_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrainService_SwapSeats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwapSeatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).SwapSeats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_SwapSeats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).SwapSeats(ctx, req.(*SwapSeatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_GetSeatMap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SeatMapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).GetSeatMap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_GetSeatMap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).GetSeatMap(ctx, req.(*SeatMapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_WatchAvailability_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAvailabilityRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TrainServiceServer).WatchAvailability(m, &grpc.GenericServerStream[WatchAvailabilityRequest, AvailabilityEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TrainService_WatchAvailabilityServer = grpc.ServerStreamingServer[AvailabilityEvent]
+
+func _TrainService_ListTicketsForUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).ListTicketsForUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_ListTicketsForUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).ListTicketsForUser(ctx, req.(*UserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_QuoteFare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuoteFareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).QuoteFare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_QuoteFare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).QuoteFare(ctx, req.(*QuoteFareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_HoldSeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurchaseTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).HoldSeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_HoldSeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).HoldSeat(ctx, req.(*PurchaseTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_ConfirmHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).ConfirmHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_ConfirmHold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).ConfirmHold(ctx, req.(*HoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_CancelTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).CancelTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_CancelTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).CancelTicket(ctx, req.(*CancelTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_GetBookingHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BookingHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).GetBookingHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_GetBookingHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).GetBookingHistory(ctx, req.(*BookingHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_JoinWaitlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).JoinWaitlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_JoinWaitlist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).JoinWaitlist(ctx, req.(*WaitlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_LeaveWaitlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitlistEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).LeaveWaitlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_LeaveWaitlist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).LeaveWaitlist(ctx, req.(*WaitlistEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_GetWaitlistPosition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitlistEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).GetWaitlistPosition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_GetWaitlistPosition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).GetWaitlistPosition(ctx, req.(*WaitlistEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_CreateDeparture_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDepartureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).CreateDeparture(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_CreateDeparture_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).CreateDeparture(ctx, req.(*CreateDepartureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_ListDepartures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeparturesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).ListDepartures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_ListDepartures_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).ListDepartures(ctx, req.(*ListDeparturesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrainService_CancelDeparture_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepartureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrainServiceServer).CancelDeparture(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrainService_CancelDeparture_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrainServiceServer).CancelDeparture(ctx, req.(*DepartureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TrainService_ServiceDesc is the grpc.ServiceDesc for TrainService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -251,6 +807,10 @@ var TrainService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PurchaseTicket",
 			Handler:    _TrainService_PurchaseTicket_Handler,
 		},
+		{
+			MethodName: "PurchaseGroupTicket",
+			Handler:    _TrainService_PurchaseGroupTicket_Handler,
+		},
 		{
 			MethodName: "GetReceipt",
 			Handler:    _TrainService_GetReceipt_Handler,
@@ -267,7 +827,69 @@ var TrainService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ModifyUserSeat",
 			Handler:    _TrainService_ModifyUserSeat_Handler,
 		},
+		{
+			MethodName: "SwapSeats",
+			Handler:    _TrainService_SwapSeats_Handler,
+		},
+		{
+			MethodName: "GetSeatMap",
+			Handler:    _TrainService_GetSeatMap_Handler,
+		},
+		{
+			MethodName: "ListTicketsForUser",
+			Handler:    _TrainService_ListTicketsForUser_Handler,
+		},
+		{
+			MethodName: "QuoteFare",
+			Handler:    _TrainService_QuoteFare_Handler,
+		},
+		{
+			MethodName: "HoldSeat",
+			Handler:    _TrainService_HoldSeat_Handler,
+		},
+		{
+			MethodName: "ConfirmHold",
+			Handler:    _TrainService_ConfirmHold_Handler,
+		},
+		{
+			MethodName: "CancelTicket",
+			Handler:    _TrainService_CancelTicket_Handler,
+		},
+		{
+			MethodName: "GetBookingHistory",
+			Handler:    _TrainService_GetBookingHistory_Handler,
+		},
+		{
+			MethodName: "JoinWaitlist",
+			Handler:    _TrainService_JoinWaitlist_Handler,
+		},
+		{
+			MethodName: "LeaveWaitlist",
+			Handler:    _TrainService_LeaveWaitlist_Handler,
+		},
+		{
+			MethodName: "GetWaitlistPosition",
+			Handler:    _TrainService_GetWaitlistPosition_Handler,
+		},
+		{
+			MethodName: "CreateDeparture",
+			Handler:    _TrainService_CreateDeparture_Handler,
+		},
+		{
+			MethodName: "ListDepartures",
+			Handler:    _TrainService_ListDepartures_Handler,
+		},
+		{
+			MethodName: "CancelDeparture",
+			Handler:    _TrainService_CancelDeparture_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAvailability",
+			Handler:       _TrainService_WatchAvailability_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "train_schema.proto",
 }
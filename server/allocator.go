@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+)
+
+// Allocator chooses seats for new bookings. Strategies are selected with
+// WithAllocator or the -allocator flag.
+type Allocator interface {
+	// Allocate picks req.Count free seats from inv, or returns nil if it
+	// cannot satisfy the request.
+	Allocate(inv *Inventory, req SeatRequest) []*pb.SeatAllocation
+}
+
+// Inventory is the free seats of a departure for one journey.
+type Inventory struct {
+	Departure *pb.Departure
+	Sections  []*SectionInventory // in layout order
+}
+
+// SectionInventory lists the free seats of one section in ascending order.
+type SectionInventory struct {
+	Layout     *pb.SectionLayout
+	Free       []int32
+	attributes map[int32]map[string]bool
+}
+
+func newSectionInventory(layout *pb.SectionLayout, free []int32) *SectionInventory {
+	attributes := make(map[int32]map[string]bool)
+	for _, seat := range layout.SeatAttributes {
+		attributes[seat.Seat] = make(map[string]bool)
+		for _, attr := range seat.Attributes {
+			attributes[seat.Seat][attr] = true
+		}
+	}
+	return &SectionInventory{Layout: layout, Free: free, attributes: attributes}
+}
+
+// HasAttribute reports whether the layout gives seat the named attribute.
+func (sec *SectionInventory) HasAttribute(seat int32, attr string) bool {
+	return sec.attributes[seat][attr]
+}
+
+// LoadFactor is the fraction of the section's seats already taken.
+func (sec *SectionInventory) LoadFactor() float64 {
+	return 1 - float64(len(sec.Free))/float64(sec.Layout.Seats)
+}
+
+// row returns the row of seat, or 0 when the row width is unknown.
+func (sec *SectionInventory) row(seat int32) int32 {
+	if sec.Layout.SeatsPerRow <= 0 {
+		return 0
+	}
+	return (seat - 1) / sec.Layout.SeatsPerRow
+}
+
+// SeatRequest describes the seats wanted for one booking.
+type SeatRequest struct {
+	Count       int
+	Preferences *pb.SeatPreferences
+	// Companions are seats already taken by the passenger's party on the
+	// same departure; new seats are placed as close to them as possible.
+	Companions []*pb.SeatAllocation
+}
+
+// allocators are the strategies selectable with the -allocator flag.
+var allocators = map[string]func() Allocator{
+	"first-free": func() Allocator { return FirstFreeAllocator{} },
+	"preference": func() Allocator { return NewPreferenceAllocator() },
+}
+
+// WithAllocator replaces the default PreferenceAllocator.
+func WithAllocator(allocator Allocator) Option {
+	return func(s *server) {
+		s.allocator = allocator
+	}
+}
+
+// FirstFreeAllocator assigns the lowest free seats in layout order and
+// ignores preferences.
+type FirstFreeAllocator struct{}
+
+func (FirstFreeAllocator) Allocate(inv *Inventory, req SeatRequest) []*pb.SeatAllocation {
+	var seats []*pb.SeatAllocation
+	for _, sec := range inv.Sections {
+		for _, seat := range sec.Free {
+			if len(seats) == req.Count {
+				return seats
+			}
+			seats = append(seats, &pb.SeatAllocation{Section: sec.Layout.Name, Seat: seat})
+		}
+	}
+	if len(seats) < req.Count {
+		return nil
+	}
+	return seats
+}
+
+// PreferenceAllocator seats a party together where it can, then as close as
+// possible to its companions, then on the seats that best match its
+// preferences, and finally in the least loaded section.
+type PreferenceAllocator struct {
+	// BalanceStep is the granularity at which section load factors are
+	// compared. Sections within the same step count as equally full, so
+	// consecutive bookings fill a coach a little before moving on rather
+	// than alternating between coaches.
+	BalanceStep float64
+}
+
+func NewPreferenceAllocator() PreferenceAllocator {
+	return PreferenceAllocator{BalanceStep: 0.1}
+}
+
+// blockScore ranks a candidate block of seats; lower is better, compared
+// field by field.
+type blockScore struct {
+	gaps      int32 // free seats skipped between party members
+	rowBreaks int32 // row changes within the block
+	distance  int32 // from the nearest companion
+	mismatch  int   // preferences not met, summed over the block
+	load      int   // section load factor in BalanceStep units
+	section   int   // layout order
+	seat      int32 // first seat of the block
+}
+
+func (b blockScore) less(o blockScore) bool {
+	switch {
+	case b.gaps != o.gaps:
+		return b.gaps < o.gaps
+	case b.rowBreaks != o.rowBreaks:
+		return b.rowBreaks < o.rowBreaks
+	case b.distance != o.distance:
+		return b.distance < o.distance
+	case b.mismatch != o.mismatch:
+		return b.mismatch < o.mismatch
+	case b.load != o.load:
+		return b.load < o.load
+	case b.section != o.section:
+		return b.section < o.section
+	default:
+		return b.seat < o.seat
+	}
+}
+
+// noCompanion is the distance of a block from companions seated elsewhere.
+const noCompanion = math.MaxInt32
+
+func (a PreferenceAllocator) Allocate(inv *Inventory, req SeatRequest) []*pb.SeatAllocation {
+	var (
+		best      []*pb.SeatAllocation
+		bestScore blockScore
+	)
+	candidates := make([][]int32, len(inv.Sections))
+	for i, sec := range inv.Sections {
+		candidates[i] = a.candidates(sec, req.Preferences)
+		free := candidates[i]
+		for start := 0; start+req.Count <= len(free); start++ {
+			block := free[start : start+req.Count]
+			score := a.score(i, sec, block, req)
+			if best == nil || score.less(bestScore) {
+				best, bestScore = seatAllocations(sec, block), score
+			}
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	// No section can take the whole party; split it, filling the sections
+	// with the most candidate seats first.
+	order := make([]int, len(inv.Sections))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(candidates[order[i]]) > len(candidates[order[j]])
+	})
+	var seats []*pb.SeatAllocation
+	for _, i := range order {
+		for _, seat := range candidates[i] {
+			if len(seats) == req.Count {
+				return seats
+			}
+			seats = append(seats, &pb.SeatAllocation{Section: inv.Sections[i].Layout.Name, Seat: seat})
+		}
+	}
+	if len(seats) < req.Count {
+		return nil
+	}
+	return seats
+}
+
+// candidates returns the free seats of sec the request may use: all of
+// them, or with strict preferences only the seats that match every one.
+func (a PreferenceAllocator) candidates(sec *SectionInventory, prefs *pb.SeatPreferences) []int32 {
+	if !prefs.GetStrict() {
+		return sec.Free
+	}
+	var free []int32
+	for _, seat := range sec.Free {
+		if mismatches(sec, seat, prefs) == 0 {
+			free = append(free, seat)
+		}
+	}
+	return free
+}
+
+func (a PreferenceAllocator) score(section int, sec *SectionInventory, block []int32, req SeatRequest) blockScore {
+	first, last := block[0], block[len(block)-1]
+	score := blockScore{
+		gaps:      last - first - int32(len(block)-1),
+		rowBreaks: sec.row(last) - sec.row(first),
+		section:   section,
+		seat:      first,
+	}
+	if len(req.Companions) > 0 {
+		score.distance = noCompanion
+		for _, companion := range req.Companions {
+			if companion.Section != sec.Layout.Name {
+				continue
+			}
+			for _, seat := range []int32{first, last} {
+				d := seat - companion.Seat
+				if d < 0 {
+					d = -d
+				}
+				score.distance = min(score.distance, d)
+			}
+		}
+	}
+	for _, seat := range block {
+		score.mismatch += mismatches(sec, seat, req.Preferences)
+	}
+	if a.BalanceStep > 0 {
+		score.load = int(sec.LoadFactor() / a.BalanceStep)
+	}
+	return score
+}
+
+// mismatches counts the preferences seat does not satisfy.
+func mismatches(sec *SectionInventory, seat int32, prefs *pb.SeatPreferences) int {
+	n := 0
+	switch prefs.GetPosition() {
+	case pb.SeatPosition_SEAT_POSITION_WINDOW:
+		if !sec.HasAttribute(seat, "window") {
+			n++
+		}
+	case pb.SeatPosition_SEAT_POSITION_AISLE:
+		if !sec.HasAttribute(seat, "aisle") {
+			n++
+		}
+	}
+	if prefs.GetForwardFacing() && !sec.HasAttribute(seat, "forward_facing") {
+		n++
+	}
+	if prefs.GetNearAccessibleToilet() && !sec.HasAttribute(seat, "near_toilet") {
+		n++
+	}
+	if prefs.GetQuietCoach() && !sec.Layout.Quiet {
+		n++
+	}
+	if prefs.GetSection() != "" && prefs.GetSection() != sec.Layout.Name {
+		n++
+	}
+	return n
+}
+
+func seatAllocations(sec *SectionInventory, seats []int32) []*pb.SeatAllocation {
+	allocations := make([]*pb.SeatAllocation, len(seats))
+	for i, seat := range seats {
+		allocations[i] = &pb.SeatAllocation{Section: sec.Layout.Name, Seat: seat}
+	}
+	return allocations
+}
+
+// inventory lists the seats of departure that are free for journey.
+func (s *server) inventory(departure *pb.Departure, journey leg) (*Inventory, error) {
+	inv := &Inventory{Departure: departure}
+	for _, section := range departure.Sections {
+		occupancy, err := s.sectionOccupancy(departure, section.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		var free []int32
+		for n := int32(1); n <= section.Seats; n++ {
+			if occupancy.free(n, journey) {
+				free = append(free, n)
+			}
+		}
+		inv.Sections = append(inv.Sections, newSectionInventory(section, free))
+	}
+	return inv, nil
+}
+
+// authorizeCompanion checks that the caller may read the companion booking
+// named in prefs, if any. Like findTicket, it reports a booking the caller
+// may not read as not found, so a stranger's booking cannot be probed or sat
+// beside.
+func (s *server) authorizeCompanion(ctx context.Context, prefs *pb.SeatPreferences) error {
+	reference := prefs.GetCompanionBookingReference()
+	if reference == "" {
+		return nil
+	}
+	tickets, err := s.store.TicketsByReference(reference)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(tickets, canRead(ctx)) {
+		return trainerr.NotFound(trainerr.ResourceBooking, reference, "booking %s not found", reference)
+	}
+	return nil
+}
+
+// companionSeats returns the seats held on departure by the companion
+// booking named in prefs, if any.
+func (s *server) companionSeats(departure *pb.Departure, prefs *pb.SeatPreferences) ([]*pb.SeatAllocation, error) {
+	reference := prefs.GetCompanionBookingReference()
+	if reference == "" {
+		return nil, nil
+	}
+	tickets, err := s.store.TicketsByReference(reference)
+	if err != nil {
+		return nil, err
+	}
+	var seats []*pb.SeatAllocation
+	for _, receipt := range tickets {
+		if receipt.DepartureId == departure.Id {
+			seats = append(seats, receipt.Seat)
+		}
+	}
+	if len(seats) == 0 {
+		return nil, trainerr.InvalidField("preferences.companion_booking_reference",
+			"booking %s has no tickets on departure %s", reference, departure.Id)
+	}
+	return seats, nil
+}
+
+// allocateSeats asks the allocator for count seats on departure for journey.
+func (s *server) allocateSeats(departure *pb.Departure, journey leg, count int, prefs *pb.SeatPreferences) ([]*pb.SeatAllocation, error) {
+	companions, err := s.companionSeats(departure, prefs)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := s.inventory(departure, journey)
+	if err != nil {
+		return nil, err
+	}
+
+	seats := s.allocator.Allocate(inv, SeatRequest{Count: count, Preferences: prefs, Companions: companions})
+	if len(seats) == count {
+		return seats, nil
+	}
+	free := 0
+	for _, sec := range inv.Sections {
+		free += len(sec.Free)
+	}
+	if free == 0 {
+		return nil, trainerr.ResourceExhausted(trainerr.ResourceDeparture, departure.Id, s.holdTTL, "Train is full")
+	}
+	if free < count {
+		return nil, trainerr.ResourceExhausted(trainerr.ResourceDeparture, departure.Id, s.holdTTL,
+			"only %d seats are free on departure %s", free, departure.Id)
+	}
+	return nil, trainerr.ResourceExhausted(trainerr.ResourceDeparture, departure.Id, 0,
+		"no free seat matches the seat preferences")
+}
+
+// parseAllocator returns the strategy registered under name.
+func parseAllocator(name string) (Allocator, error) {
+	newAllocator, ok := allocators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown allocator %q", name)
+	}
+	return newAllocator(), nil
+}
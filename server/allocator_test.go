@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+// testInventory builds an inventory of fully free sections.
+func testInventory(sections ...*pb.SectionLayout) *Inventory {
+	inv := &Inventory{Departure: &pb.Departure{Id: "test", Sections: sections}}
+	for _, section := range sections {
+		var free []int32
+		for n := int32(1); n <= section.Seats; n++ {
+			free = append(free, n)
+		}
+		inv.Sections = append(inv.Sections, newSectionInventory(section, free))
+	}
+	return inv
+}
+
+// take removes seats from a section's free list.
+func take(sec *SectionInventory, seats ...int32) {
+	taken := make(map[int32]bool)
+	for _, seat := range seats {
+		taken[seat] = true
+	}
+	var free []int32
+	for _, seat := range sec.Free {
+		if !taken[seat] {
+			free = append(free, seat)
+		}
+	}
+	sec.Free = free
+}
+
+// coach is a 4-abreast section: window, aisle, aisle, window.
+func coach(name string, rows int32) *pb.SectionLayout {
+	section := &pb.SectionLayout{Name: name, Seats: rows * 4, SeatClass: "standard", SeatsPerRow: 4}
+	for seat := int32(1); seat <= section.Seats; seat++ {
+		attr := "aisle"
+		if seat%4 == 1 || seat%4 == 0 {
+			attr = "window"
+		}
+		section.SeatAttributes = append(section.SeatAttributes, &pb.SeatAttributes{Seat: seat, Attributes: []string{attr}})
+	}
+	return section
+}
+
+func assertSeat(t *testing.T, section string, seat int32, got *pb.SeatAllocation, msgAndArgs ...interface{}) {
+	t.Helper()
+	assert.Equal(t, section, got.GetSection(), msgAndArgs...)
+	assert.Equal(t, seat, got.GetSeat(), msgAndArgs...)
+}
+
+func seatNumbers(seats []*pb.SeatAllocation) []int32 {
+	var numbers []int32
+	for _, seat := range seats {
+		numbers = append(numbers, seat.Seat)
+	}
+	return numbers
+}
+
+func TestPreferenceAllocatorHonoursPosition(t *testing.T) {
+	inv := testInventory(coach("A", 4))
+	take(inv.Sections[0], 1)
+
+	seats := NewPreferenceAllocator().Allocate(inv, SeatRequest{
+		Count:       1,
+		Preferences: &pb.SeatPreferences{Position: pb.SeatPosition_SEAT_POSITION_WINDOW},
+	})
+	assert.Equal(t, []int32{4}, seatNumbers(seats), "seat 4 is the lowest free window seat")
+
+	seats = NewPreferenceAllocator().Allocate(inv, SeatRequest{
+		Count:       1,
+		Preferences: &pb.SeatPreferences{Position: pb.SeatPosition_SEAT_POSITION_AISLE},
+	})
+	assert.Equal(t, []int32{2}, seatNumbers(seats))
+}
+
+func TestPreferenceAllocatorSectionAttributes(t *testing.T) {
+	quiet := &pb.SectionLayout{Name: "Q", Seats: 4, SeatClass: "standard", Quiet: true,
+		SeatAttributes: []*pb.SeatAttributes{{Seat: 3, Attributes: []string{"forward_facing", "near_toilet"}}}}
+	inv := testInventory(&pb.SectionLayout{Name: "A", Seats: 4, SeatClass: "standard"}, quiet)
+
+	seats := NewPreferenceAllocator().Allocate(inv, SeatRequest{Count: 1, Preferences: &pb.SeatPreferences{QuietCoach: true}})
+	assert.Equal(t, "Q", seats[0].Section)
+
+	seats = NewPreferenceAllocator().Allocate(inv, SeatRequest{Count: 1, Preferences: &pb.SeatPreferences{
+		ForwardFacing:        true,
+		NearAccessibleToilet: true,
+	}})
+	assertSeat(t, "Q", 3, seats[0])
+
+	seats = NewPreferenceAllocator().Allocate(inv, SeatRequest{Count: 1, Preferences: &pb.SeatPreferences{Section: "Q"}})
+	assert.Equal(t, "Q", seats[0].Section)
+}
+
+func TestPreferenceAllocatorStrict(t *testing.T) {
+	inv := testInventory(coach("A", 1))
+	take(inv.Sections[0], 1, 4)
+
+	prefs := &pb.SeatPreferences{Position: pb.SeatPosition_SEAT_POSITION_WINDOW}
+	seats := NewPreferenceAllocator().Allocate(inv, SeatRequest{Count: 1, Preferences: prefs})
+	assert.Len(t, seats, 1, "soft preferences fall back to any seat")
+
+	prefs.Strict = true
+	assert.Nil(t, NewPreferenceAllocator().Allocate(inv, SeatRequest{Count: 1, Preferences: prefs}),
+		"strict preferences must not fall back")
+}
+
+func TestPreferenceAllocatorSeatsGroupTogether(t *testing.T) {
+	inv := testInventory(coach("A", 3))
+	// Row 1 has seats 1 and 3 free, row 2 only seat 8: no three adjacent
+	// seats except in row 3.
+	take(inv.Sections[0], 2, 4, 5, 6, 7)
+
+	seats := NewPreferenceAllocator().Allocate(inv, SeatRequest{Count: 3})
+	assert.Equal(t, []int32{9, 10, 11}, seatNumbers(seats))
+
+	seats = NewPreferenceAllocator().Allocate(inv, SeatRequest{Count: 4})
+	assert.Equal(t, []int32{9, 10, 11, 12}, seatNumbers(seats), "a whole row beats a split party")
+}
+
+func TestPreferenceAllocatorSplitsWhenNoSectionFits(t *testing.T) {
+	inv := testInventory(
+		&pb.SectionLayout{Name: "A", Seats: 2, SeatClass: "standard"},
+		&pb.SectionLayout{Name: "B", Seats: 3, SeatClass: "standard"},
+	)
+	seats := NewPreferenceAllocator().Allocate(inv, SeatRequest{Count: 4})
+	require.Len(t, seats, 4)
+	assert.Equal(t, "B", seats[0].Section, "the roomiest section is filled first")
+
+	assert.Nil(t, NewPreferenceAllocator().Allocate(inv, SeatRequest{Count: 6}))
+}
+
+func TestPreferenceAllocatorSeatsNearCompanions(t *testing.T) {
+	inv := testInventory(coach("A", 4), coach("B", 4))
+	take(inv.Sections[1], 9)
+
+	seats := NewPreferenceAllocator().Allocate(inv, SeatRequest{
+		Count:      1,
+		Companions: []*pb.SeatAllocation{{Section: "B", Seat: 9}},
+	})
+	assertSeat(t, "B", 8, seats[0])
+}
+
+func TestPreferenceAllocatorBalancesLoad(t *testing.T) {
+	inv := testInventory(
+		&pb.SectionLayout{Name: "A", Seats: 10, SeatClass: "standard"},
+		&pb.SectionLayout{Name: "B", Seats: 10, SeatClass: "standard"},
+	)
+	take(inv.Sections[0], 1, 2, 3)
+
+	seats := NewPreferenceAllocator().Allocate(inv, SeatRequest{Count: 1})
+	assertSeat(t, "B", 1, seats[0], "A is fuller than B")
+
+	seats = FirstFreeAllocator{}.Allocate(inv, SeatRequest{Count: 1})
+	assertSeat(t, "A", 4, seats[0], "first-free ignores load")
+}
+
+func TestFreedSeatIsReallocated(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		first, err := server.PurchaseTicket(context.Background(), purchaseRequest("first@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+		second, err := server.PurchaseTicket(context.Background(), purchaseRequest("second@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+
+		_, err = server.RemoveUser(context.Background(), &pb.UserRequest{BookingReference: first.BookingReference})
+		require.NoError(t, err, "error removing user")
+
+		third, err := server.PurchaseTicket(context.Background(), purchaseRequest("third@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+		assert.Equal(t, first.Seat, third.Seat, "the freed seat should be reused")
+		assert.NotEqual(t, second.Seat, third.Seat, "an occupied seat must not be reissued")
+	})
+}
+
+func TestPurchaseTicketWithPreferences(t *testing.T) {
+	layouts, err := LoadLayouts("layouts.json")
+	require.NoError(t, err, "error loading sample layouts")
+	server := NewServer(WithLayouts(layouts))
+
+	req := purchaseRequest("quiet@example.com")
+	req.Preferences = &pb.SeatPreferences{QuietCoach: true, NearAccessibleToilet: true, Strict: true}
+	receipt, err := server.PurchaseTicket(context.Background(), req)
+	require.NoError(t, err, "error purchasing ticket")
+	assertSeat(t, "A", 43, receipt.Seat)
+
+	companion := purchaseRequest("friend@example.com")
+	companion.Preferences = &pb.SeatPreferences{CompanionBookingReference: receipt.BookingReference}
+	friend, err := server.PurchaseTicket(context.Background(), companion)
+	require.NoError(t, err, "error purchasing ticket")
+	assert.Equal(t, "A", friend.Seat.Section)
+	assert.Contains(t, []int32{42, 44}, friend.Seat.Seat, "companion should sit next to seat 43")
+
+	req = purchaseRequest("table@example.com")
+	req.Preferences = &pb.SeatPreferences{Section: "C", Strict: true}
+	_, err = server.PurchaseTicket(context.Background(), req)
+	assert.Error(t, err, "unknown section cannot match")
+}
+
+func TestStrictPreferencesExhausted(t *testing.T) {
+	server := NewServer()
+	req := purchaseRequest("window@example.com")
+	req.Preferences = &pb.SeatPreferences{Position: pb.SeatPosition_SEAT_POSITION_WINDOW, Strict: true}
+	_, err := server.PurchaseTicket(context.Background(), req)
+	assertCode(t, err, codes.ResourceExhausted, "the default layout has no window seats")
+}
+
+func TestFirstFreeAllocatorOption(t *testing.T) {
+	server := NewServer(WithAllocator(FirstFreeAllocator{}))
+	for i, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest(email))
+		require.NoError(t, err, "error purchasing ticket")
+		assertSeat(t, "A", int32(i+1), receipt.Seat)
+	}
+
+	_, err := parseAllocator("random")
+	assert.Error(t, err, "unknown strategy")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"test_train/auth"
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+)
+
+var (
+	everyone = []auth.Role{auth.RolePassenger, auth.RoleConductor, auth.RoleAdmin}
+	staff    = []auth.Role{auth.RoleConductor, auth.RoleAdmin}
+	admins   = []auth.Role{auth.RoleAdmin}
+)
+
+// rpcPolicy lists the roles allowed to call each method. Passengers are
+// further limited to their own tickets by the handlers; see authorizeUser.
+var rpcPolicy = auth.Policy{
+	pb.TrainService_PurchaseTicket_FullMethodName:      everyone,
+	pb.TrainService_PurchaseGroupTicket_FullMethodName: everyone,
+	pb.TrainService_GetReceipt_FullMethodName:          everyone,
+	pb.TrainService_GetUsersBySection_FullMethodName:   staff,
+	pb.TrainService_RemoveUser_FullMethodName:          admins,
+	pb.TrainService_ModifyUserSeat_FullMethodName:      everyone,
+	pb.TrainService_SwapSeats_FullMethodName:           everyone,
+	pb.TrainService_GetSeatMap_FullMethodName:          everyone,
+	pb.TrainService_WatchAvailability_FullMethodName:   everyone,
+	pb.TrainService_ListTicketsForUser_FullMethodName:  everyone,
+	pb.TrainService_QuoteFare_FullMethodName:           everyone,
+	pb.TrainService_HoldSeat_FullMethodName:            everyone,
+	pb.TrainService_ConfirmHold_FullMethodName:         everyone,
+	pb.TrainService_CancelTicket_FullMethodName:        everyone,
+	pb.TrainService_GetBookingHistory_FullMethodName:   everyone,
+	pb.TrainService_JoinWaitlist_FullMethodName:        everyone,
+	pb.TrainService_LeaveWaitlist_FullMethodName:       everyone,
+	pb.TrainService_GetWaitlistPosition_FullMethodName: everyone,
+	pb.TrainService_CreateDeparture_FullMethodName:     admins,
+	pb.TrainService_ListDepartures_FullMethodName:      everyone,
+	pb.TrainService_CancelDeparture_FullMethodName:     admins,
+}
+
+// WithKeySet requires every call to be authenticated and accepts bearer
+// tokens signed by keys.
+func WithKeySet(keys *auth.KeySet) Option {
+	return func(s *server) {
+		s.authenticator().Keys = keys
+	}
+}
+
+// WithClientCertIdentity requires every call to be authenticated and
+// accepts client certificates verified by the TLS layer in place of a
+// bearer token; see auth.ClaimsFromCertificate for how they map to roles.
+func WithClientCertIdentity() Option {
+	return func(s *server) {
+		s.authenticator().ClientCerts = true
+	}
+}
+
+func (s *server) authenticator() *auth.Authenticator {
+	if s.auth == nil {
+		s.auth = &auth.Authenticator{Policy: rpcPolicy, Now: time.Now}
+	}
+	return s.auth
+}
+
+// authorizeUser checks that the caller may act on the tickets of the
+// passenger with email. Admins may do anything and conductors may read any
+// passenger's bookings, but a passenger may only read or change their own.
+// Calls without claims are allowed: they come from a server running without
+// authentication, and the interceptor rejects them otherwise.
+func authorizeUser(ctx context.Context, email string, change bool) error {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	switch {
+	case claims.Role == auth.RoleAdmin:
+		return nil
+	case claims.Role == auth.RoleConductor && !change:
+		return nil
+	case claims.Role == auth.RolePassenger && claims.Email != "" && strings.EqualFold(claims.Email, email):
+		return nil
+	}
+	return trainerr.PermissionDenied("%s %s may not access bookings of %s", claims.Role, claims.Subject, email)
+}
+
+// canRead is a findTicket filter passing the tickets the caller may read;
+// see authorizeUser. Other passengers' tickets are then reported as not
+// found rather than forbidden, so callers cannot probe which booking
+// references exist. Changes still need authorizeUser once the ticket is
+// found.
+func canRead(ctx context.Context) func(*pb.TicketReceipt) bool {
+	return func(receipt *pb.TicketReceipt) bool {
+		return authorizeUser(ctx, receipt.User.GetEmail(), false) == nil
+	}
+}
+
+// authorizeAnyUser checks that the caller may act for at least one of
+// emails, as a passenger booking for a group they travel with may.
+func authorizeAnyUser(ctx context.Context, emails []string, change bool) error {
+	err := trainerr.PermissionDenied("no passenger to authorize")
+	for _, email := range emails {
+		if err = authorizeUser(ctx, email, change); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// authorizeBooking checks that the caller may read the booking recorded by
+// events, which it may if it could read any ticket on it.
+func authorizeBooking(ctx context.Context, events []*pb.BookingEvent) error {
+	var emails []string
+	for _, event := range events {
+		for _, receipt := range []*pb.TicketReceipt{event.Before, event.After} {
+			if receipt != nil {
+				emails = append(emails, receipt.User.GetEmail())
+			}
+		}
+	}
+	return authorizeAnyUser(ctx, emails, false)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"test_train/auth"
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func testKeySet() *auth.KeySet {
+	return &auth.KeySet{
+		Issuer:     "test-train",
+		SigningKey: "test",
+		Keys:       []auth.Key{{ID: "test", Secret: []byte(strings.Repeat("s", 32))}},
+	}
+}
+
+// bearer returns a call option authenticating as subject with role.
+func bearer(t *testing.T, keys *auth.KeySet, role auth.Role, subject, email string) grpc.CallOption {
+	t.Helper()
+	token, err := keys.Sign(auth.Claims{
+		Subject:   subject,
+		Email:     email,
+		Role:      role,
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err, "error signing token")
+	return grpc.PerRPCCredentials(auth.BearerToken(token))
+}
+
+func TestEveryMethodHasPolicy(t *testing.T) {
+	for _, method := range pb.TrainService_ServiceDesc.Methods {
+		assert.Contains(t, rpcPolicy, "/"+pb.TrainService_ServiceDesc.ServiceName+"/"+method.MethodName)
+	}
+	for _, stream := range pb.TrainService_ServiceDesc.Streams {
+		assert.Contains(t, rpcPolicy, "/"+pb.TrainService_ServiceDesc.ServiceName+"/"+stream.StreamName)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	keys := testKeySet()
+	client := dialTestServer(t, NewServer(WithKeySet(keys)))
+	ctx := context.Background()
+
+	_, err := client.ListDepartures(ctx, &pb.ListDeparturesRequest{})
+	assertCode(t, err, codes.Unauthenticated, "call without a token")
+
+	_, err = client.PurchaseTicket(ctx, &pb.PurchaseTicketRequest{}, grpc.PerRPCCredentials(auth.BearerToken("garbage")))
+	assertCode(t, err, codes.Unauthenticated, "invalid token should be rejected before validation")
+
+	forged := testKeySet()
+	forged.Keys[0].Secret = []byte(strings.Repeat("f", 32))
+	_, err = client.ListDepartures(ctx, &pb.ListDeparturesRequest{}, bearer(t, forged, auth.RoleAdmin, "mallory", ""))
+	assertCode(t, err, codes.Unauthenticated, "token signed with another key")
+
+	_, err = client.ListDepartures(ctx, &pb.ListDeparturesRequest{}, bearer(t, keys, auth.RolePassenger, "john", "john.doe@example.com"))
+	assert.NoError(t, err, "error listing departures")
+}
+
+func TestPassengersOnlySeeTheirOwnTickets(t *testing.T) {
+	keys := testKeySet()
+	client := dialTestServer(t, NewServer(WithKeySet(keys)))
+	ctx := context.Background()
+	john := bearer(t, keys, auth.RolePassenger, "john", "john.doe@example.com")
+	jane := bearer(t, keys, auth.RolePassenger, "jane", "jane.smith@example.com")
+
+	receipt, err := client.PurchaseTicket(ctx, purchaseRequest("john.doe@example.com"), john)
+	require.NoError(t, err, "error purchasing ticket")
+	_, err = client.PurchaseTicket(ctx, purchaseRequest("john.doe@example.com"), jane)
+	assertCode(t, err, codes.PermissionDenied, "buying a ticket for someone else")
+
+	byEmail := &pb.UserRequest{Email: "john.doe@example.com"}
+	_, err = client.GetReceipt(ctx, byEmail, john)
+	assert.NoError(t, err, "error fetching own receipt")
+	_, err = client.GetReceipt(ctx, byEmail, jane)
+	assertCode(t, err, codes.NotFound, "reading another passenger's receipt")
+	_, err = client.GetReceipt(ctx, &pb.UserRequest{BookingReference: receipt.BookingReference}, jane)
+	assertCode(t, err, codes.NotFound, "reading another passenger's receipt by reference")
+	_, err = client.ListTicketsForUser(ctx, byEmail, jane)
+	assertCode(t, err, codes.PermissionDenied, "listing another passenger's tickets")
+	_, err = client.GetBookingHistory(ctx, &pb.BookingHistoryRequest{BookingReference: receipt.BookingReference}, jane)
+	assertCode(t, err, codes.NotFound, "reading another passenger's booking history")
+
+	_, err = client.ModifyUserSeat(ctx, &pb.ModifySeatRequest{Email: "john.doe@example.com", NewSection: "B", NewSeat: 5}, jane)
+	assertCode(t, err, codes.NotFound, "moving another passenger")
+	_, err = client.CancelTicket(ctx, &pb.CancelTicketRequest{Email: "john.doe@example.com"}, jane)
+	assertCode(t, err, codes.NotFound, "cancelling another passenger's ticket")
+	_, err = client.RemoveUser(ctx, byEmail, john)
+	assertCode(t, err, codes.PermissionDenied, "passengers may not remove users")
+	_, err = client.GetUsersBySection(ctx, &pb.SectionRequest{Section: "A"}, john)
+	assertCode(t, err, codes.PermissionDenied, "passengers may not list sections")
+
+	moved, err := client.ModifyUserSeat(ctx, &pb.ModifySeatRequest{Email: "john.doe@example.com", NewSection: "B", NewSeat: 5}, john)
+	require.NoError(t, err, "error modifying own seat")
+	assert.Equal(t, "B", moved.Seat.Section)
+
+	history, err := client.GetBookingHistory(ctx, &pb.BookingHistoryRequest{BookingReference: receipt.BookingReference}, john)
+	require.NoError(t, err, "error fetching booking history")
+	assert.Equal(t, "passenger:john", history.Events[len(history.Events)-1].Actor, "ledger should name the authenticated caller")
+}
+
+func TestCompanionBookingMustBeReadable(t *testing.T) {
+	keys := testKeySet()
+	client := dialTestServer(t, NewServer(WithKeySet(keys)))
+	ctx := context.Background()
+	john := bearer(t, keys, auth.RolePassenger, "john", "john.doe@example.com")
+	jane := bearer(t, keys, auth.RolePassenger, "jane", "jane.smith@example.com")
+
+	receipt, err := client.PurchaseTicket(ctx, purchaseRequest("john.doe@example.com"), john)
+	require.NoError(t, err, "error purchasing ticket")
+	beside := &pb.SeatPreferences{CompanionBookingReference: receipt.BookingReference}
+
+	req := purchaseRequest("jane.smith@example.com")
+	req.Preferences = beside
+	_, err = client.PurchaseTicket(ctx, req, jane)
+	assertCode(t, err, codes.NotFound, "sitting beside another passenger's booking")
+	_, err = client.HoldSeat(ctx, req, jane)
+	assertCode(t, err, codes.NotFound, "holding a seat beside another passenger's booking")
+	group := groupRequest(2)
+	group.Passengers[0].User.Email = "jane.smith@example.com"
+	group.Preferences = beside
+	_, err = client.PurchaseGroupTicket(ctx, group, jane)
+	assertCode(t, err, codes.NotFound, "seating a group beside another passenger's booking")
+
+	req = purchaseRequest("john.doe@example.com")
+	req.Preferences = beside
+	_, err = client.PurchaseTicket(ctx, req, john)
+	assert.NoError(t, err, "error sitting beside own booking")
+}
+
+func TestStaffRoles(t *testing.T) {
+	keys := testKeySet()
+	client := dialTestServer(t, NewServer(WithKeySet(keys)))
+	ctx := context.Background()
+	john := bearer(t, keys, auth.RolePassenger, "john", "john.doe@example.com")
+	conductor := bearer(t, keys, auth.RoleConductor, "conductor-7", "")
+	admin := bearer(t, keys, auth.RoleAdmin, "ops", "")
+
+	_, err := client.PurchaseTicket(ctx, purchaseRequest("john.doe@example.com"), john)
+	require.NoError(t, err, "error purchasing ticket")
+
+	users, err := client.GetUsersBySection(ctx, &pb.SectionRequest{Section: "A"}, conductor)
+	require.NoError(t, err, "error listing section")
+	assert.Len(t, users.Users, 1)
+	_, err = client.GetReceipt(ctx, &pb.UserRequest{Email: "john.doe@example.com"}, conductor)
+	assert.NoError(t, err, "conductors may inspect tickets")
+	_, err = client.CancelTicket(ctx, &pb.CancelTicketRequest{Email: "john.doe@example.com"}, conductor)
+	assertCode(t, err, codes.PermissionDenied, "conductors may not cancel tickets")
+	_, err = client.RemoveUser(ctx, &pb.UserRequest{Email: "john.doe@example.com"}, conductor)
+	assertCode(t, err, codes.PermissionDenied, "conductors may not remove users")
+	_, err = client.CancelDeparture(ctx, &pb.DepartureRequest{DepartureId: defaultDepartureID}, conductor)
+	assertCode(t, err, codes.PermissionDenied, "conductors may not manage trains")
+
+	_, err = client.RemoveUser(ctx, &pb.UserRequest{Email: "john.doe@example.com"}, admin)
+	assert.NoError(t, err, "error removing user")
+	_, err = client.CancelDeparture(ctx, &pb.DepartureRequest{DepartureId: defaultDepartureID}, admin)
+	assert.NoError(t, err, "error cancelling departure")
+}
+
+func TestWatchAvailabilityRequiresToken(t *testing.T) {
+	keys := testKeySet()
+	client := dialTestServer(t, NewServer(WithKeySet(keys)))
+
+	stream, err := client.WatchAvailability(context.Background(), &pb.WatchAvailabilityRequest{})
+	require.NoError(t, err, "error opening stream")
+	_, err = stream.Recv()
+	assertCode(t, err, codes.Unauthenticated, "stream without a token")
+
+	stream, err = client.WatchAvailability(context.Background(), &pb.WatchAvailabilityRequest{},
+		bearer(t, keys, auth.RoleConductor, "conductor-7", ""))
+	require.NoError(t, err, "error opening stream")
+	event, err := stream.Recv()
+	require.NoError(t, err, "error receiving snapshot")
+	assert.NotNil(t, event.GetSnapshot())
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// availabilityBacklog is how many recent seat changes are kept so a
+	// subscriber can resume from a cursor without a new snapshot.
+	availabilityBacklog = 1024
+	// subscriberBuffer bounds the events queued for one subscriber. A
+	// subscriber that falls further behind is disconnected and resumes from
+	// the last cursor it received.
+	subscriberBuffer = 256
+)
+
+// seatChange records that the state of one seat may have changed. States
+// are worked out per subscriber, since each may watch a different leg.
+type seatChange struct {
+	cursor      uint64
+	departureID string
+	section     string
+	seat        int32
+	reason      string
+}
+
+type availabilitySubscriber struct {
+	departureID string
+	journey     leg
+	events      chan *pb.AvailabilityEvent
+}
+
+// availabilityFeed fans seat changes out to WatchAvailability streams.
+// Changes are published with their departure locked, so a subscriber that
+// holds the departure's read lock sees no change it would miss.
+type availabilityFeed struct {
+	mu          sync.Mutex
+	cursor      uint64
+	backlog     []seatChange // oldest first
+	subscribers map[*availabilitySubscriber]bool
+}
+
+// newAvailabilityFeed starts cursors at the current time in nanoseconds, so
+// a cursor from a previous run of the server is older than the backlog and
+// gets a fresh snapshot rather than a wrong replay.
+func newAvailabilityFeed() *availabilityFeed {
+	return &availabilityFeed{
+		cursor:      uint64(time.Now().UnixNano()),
+		subscribers: make(map[*availabilitySubscriber]bool),
+	}
+}
+
+// since returns the changes after cursor, and false if some of them have
+// already left the backlog.
+func (f *availabilityFeed) since(cursor uint64) ([]seatChange, bool) {
+	oldest := f.cursor - uint64(len(f.backlog))
+	if cursor < oldest || cursor > f.cursor {
+		return nil, false
+	}
+	return f.backlog[len(f.backlog)-int(f.cursor-cursor):], true
+}
+
+// seatChanged publishes a change to a seat's state to every subscriber
+// watching its departure. Subscribers whose buffer is full are dropped.
+func (s *server) seatChanged(departureID string, seat *pb.SeatAllocation, reason string) {
+	f := s.feed
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.cursor++
+	change := seatChange{
+		cursor:      f.cursor,
+		departureID: departureID,
+		section:     seat.GetSection(),
+		seat:        seat.GetSeat(),
+		reason:      reason,
+	}
+	f.backlog = append(f.backlog, change)
+	if len(f.backlog) > availabilityBacklog {
+		f.backlog = f.backlog[len(f.backlog)-availabilityBacklog:]
+	}
+
+	for sub := range f.subscribers {
+		if sub.departureID != departureID {
+			continue
+		}
+		event, err := s.changeEvent(sub, change)
+		if err != nil {
+			log.Printf("Availability event %d: %v", change.cursor, err)
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			delete(f.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// changeEvent describes change as seen by sub.
+func (s *server) changeEvent(sub *availabilitySubscriber, change seatChange) (*pb.AvailabilityEvent, error) {
+	departure, err := s.departure(change.departureID)
+	if err != nil {
+		return nil, err
+	}
+	sold, err := s.soldOccupancy(departure, change.section, "")
+	if err != nil {
+		return nil, err
+	}
+	held := s.heldOccupancy(departure.Id, change.section, false)
+	return &pb.AvailabilityEvent{
+		Cursor: change.cursor,
+		Event: &pb.AvailabilityEvent_Change{Change: &pb.SeatChange{
+			Section: change.section,
+			Seat:    change.seat,
+			State:   seatState(sold, held, change.seat, sub.journey),
+			Reason:  change.reason,
+		}},
+	}, nil
+}
+
+// subscribe registers a subscriber for req and returns the events to send
+// before live ones: the changes after req.Cursor, or a snapshot if they can
+// no longer be replayed.
+func (s *server) subscribe(req *pb.WatchAvailabilityRequest) (*availabilitySubscriber, []*pb.AvailabilityEvent, error) {
+	defer s.readLockDeparture(req.DepartureId)()
+
+	departure, err := s.departure(req.DepartureId)
+	if err != nil {
+		return nil, nil, err
+	}
+	journey := wholeRoute(departure)
+	if req.From != "" || req.To != "" {
+		if journey, err = resolveLeg(departure, req.From, req.To); err != nil {
+			return nil, nil, err
+		}
+	}
+	sub := &availabilitySubscriber{
+		departureID: departure.Id,
+		journey:     journey,
+		events:      make(chan *pb.AvailabilityEvent, subscriberBuffer),
+	}
+
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	var initial []*pb.AvailabilityEvent
+	changes, ok := s.feed.since(req.Cursor)
+	if req.Cursor != 0 && ok {
+		for _, change := range changes {
+			if change.departureID != departure.Id {
+				continue
+			}
+			event, err := s.changeEvent(sub, change)
+			if err != nil {
+				return nil, nil, err
+			}
+			initial = append(initial, event)
+		}
+	} else {
+		snapshot, err := s.seatMap(departure, journey)
+		if err != nil {
+			return nil, nil, err
+		}
+		initial = append(initial, &pb.AvailabilityEvent{
+			Cursor: s.feed.cursor,
+			Event:  &pb.AvailabilityEvent_Snapshot{Snapshot: snapshot},
+		})
+	}
+
+	s.feed.subscribers[sub] = true
+	return sub, initial, nil
+}
+
+func (s *server) unsubscribe(sub *availabilitySubscriber) {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	if s.feed.subscribers[sub] {
+		delete(s.feed.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// WatchAvailability streams a snapshot of a departure's seats, or the
+// changes missed since req.Cursor, followed by every later change.
+func (s *server) WatchAvailability(req *pb.WatchAvailabilityRequest, stream grpc.ServerStreamingServer[pb.AvailabilityEvent]) error {
+	sub, initial, err := s.subscribe(req)
+	if err != nil {
+		return err
+	}
+	defer s.unsubscribe(sub)
+
+	last := req.Cursor
+	for _, event := range initial {
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+		last = event.Cursor
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case event, ok := <-sub.events:
+			if !ok {
+				return trainerr.ResourceExhausted(trainerr.ResourceSubscription, sub.departureID, 0,
+					"subscriber fell behind; resume from cursor %d", last)
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+			last = event.Cursor
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func recvChange(t *testing.T, stream grpc.ServerStreamingClient[pb.AvailabilityEvent]) (*pb.SeatChange, uint64) {
+	t.Helper()
+	event, err := stream.Recv()
+	require.NoError(t, err, "error receiving availability event")
+	require.NotNil(t, event.GetChange(), "expected a seat change, got %v", event)
+	return event.GetChange(), event.Cursor
+}
+
+func TestWatchAvailabilitySnapshotAndChanges(t *testing.T) {
+	server := NewServer()
+	client := dialTestServer(t, server)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchAvailability(ctx, &pb.WatchAvailabilityRequest{})
+	require.NoError(t, err, "error watching availability")
+	first, err := stream.Recv()
+	require.NoError(t, err, "error receiving snapshot")
+	snapshot := first.GetSnapshot()
+	require.NotNil(t, snapshot, "stream should start with a snapshot")
+	assert.Equal(t, defaultDepartureID, snapshot.DepartureId)
+	assert.Len(t, snapshot.Sections, 2)
+
+	receipt, err := client.PurchaseTicket(ctx, purchaseRequest("john@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	change, cursor := recvChange(t, stream)
+	assert.Greater(t, cursor, first.Cursor)
+	assert.Equal(t, receipt.Seat.Seat, change.Seat)
+	assert.Equal(t, pb.SeatState_SEAT_STATE_SOLD, change.State)
+	assert.Equal(t, "purchased", change.Reason)
+
+	_, err = client.ModifyUserSeat(ctx, &pb.ModifySeatRequest{BookingReference: receipt.BookingReference, NewSection: "B", NewSeat: 7})
+	require.NoError(t, err, "error modifying seat")
+	change, _ = recvChange(t, stream)
+	assert.Equal(t, pb.SeatState_SEAT_STATE_FREE, change.State, "old seat is released")
+	change, _ = recvChange(t, stream)
+	assert.Equal(t, "B", change.Section)
+	assert.Equal(t, pb.SeatState_SEAT_STATE_SOLD, change.State, "new seat is taken")
+
+	_, err = client.HoldSeat(ctx, purchaseRequest("jane@example.com"))
+	require.NoError(t, err, "error holding seat")
+	change, _ = recvChange(t, stream)
+	assert.Equal(t, pb.SeatState_SEAT_STATE_HELD, change.State)
+
+	_, err = client.RemoveUser(ctx, &pb.UserRequest{BookingReference: receipt.BookingReference})
+	require.NoError(t, err, "error removing user")
+	change, _ = recvChange(t, stream)
+	assert.Equal(t, pb.SeatState_SEAT_STATE_FREE, change.State)
+	assert.Equal(t, "cancelled", change.Reason)
+}
+
+func TestWatchAvailabilityReportsWatchedLeg(t *testing.T) {
+	server := NewServer()
+	departure := newRouteDeparture(t, server)
+	client := dialTestServer(t, server)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchAvailability(ctx, &pb.WatchAvailabilityRequest{DepartureId: departure.Id, From: "London", To: "Paris"})
+	require.NoError(t, err, "error watching availability")
+	_, err = stream.Recv()
+	require.NoError(t, err, "error receiving snapshot")
+
+	_, err = buyLeg(server, departure.Id, "later@example.com", "Paris", "Brussels")
+	require.NoError(t, err, "error purchasing ticket")
+	change, _ := recvChange(t, stream)
+	assert.Equal(t, pb.SeatState_SEAT_STATE_FREE, change.State, "a Paris-Brussels sale leaves London-Paris free")
+
+	_, err = buyLeg(server, departure.Id, "early@example.com", "London", "Paris")
+	require.NoError(t, err, "error purchasing ticket")
+	change, _ = recvChange(t, stream)
+	assert.Equal(t, pb.SeatState_SEAT_STATE_SOLD, change.State)
+}
+
+func TestWatchAvailabilityResumes(t *testing.T) {
+	server := NewServer()
+	client := dialTestServer(t, server)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchAvailability(ctx, &pb.WatchAvailabilityRequest{})
+	require.NoError(t, err, "error watching availability")
+	first, err := stream.Recv()
+	require.NoError(t, err, "error receiving snapshot")
+
+	// Changes made while disconnected are replayed after the cursor.
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		_, err := server.PurchaseTicket(ctx, purchaseRequest(email))
+		require.NoError(t, err, "error purchasing ticket")
+	}
+	resumed, err := client.WatchAvailability(ctx, &pb.WatchAvailabilityRequest{Cursor: first.Cursor})
+	require.NoError(t, err, "error resuming")
+	_, cursor := recvChange(t, resumed)
+	assert.Equal(t, first.Cursor+1, cursor)
+	_, cursor = recvChange(t, resumed)
+	assert.Equal(t, first.Cursor+2, cursor)
+
+	// A cursor that can no longer be replayed starts over with a snapshot.
+	restarted, err := client.WatchAvailability(ctx, &pb.WatchAvailabilityRequest{Cursor: 1})
+	require.NoError(t, err, "error resuming")
+	event, err := restarted.Recv()
+	require.NoError(t, err, "error receiving snapshot")
+	assert.NotNil(t, event.GetSnapshot())
+	assert.Equal(t, first.Cursor+2, event.Cursor)
+}
+
+func TestWatchAvailabilityDropsSlowSubscriber(t *testing.T) {
+	server := NewServer()
+	sub, _, err := server.subscribe(&pb.WatchAvailabilityRequest{})
+	require.NoError(t, err, "error subscribing")
+
+	unlock := server.lockDepartures(defaultDepartureID)
+	for i := 0; i <= subscriberBuffer; i++ {
+		server.seatChanged(defaultDepartureID, &pb.SeatAllocation{Section: "A", Seat: 1}, "test")
+	}
+	unlock()
+
+	received := 0
+	for range sub.events {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received, "buffered events are delivered before the stream ends")
+	assert.NotContains(t, server.feed.subscribers, sub)
+	server.unsubscribe(sub) // must not close the channel twice
+}
+
+func TestWatchAvailabilityBacklogIsBounded(t *testing.T) {
+	server := NewServer()
+	start := server.feed.cursor
+	unlock := server.lockDepartures(defaultDepartureID)
+	for i := 0; i < availabilityBacklog+10; i++ {
+		server.seatChanged(defaultDepartureID, &pb.SeatAllocation{Section: "A", Seat: 1}, "test")
+	}
+	unlock()
+
+	assert.Len(t, server.feed.backlog, availabilityBacklog)
+	_, ok := server.feed.since(start)
+	assert.False(t, ok, "changes beyond the backlog cannot be replayed")
+	changes, ok := server.feed.since(server.feed.cursor - 5)
+	assert.True(t, ok)
+	assert.Len(t, changes, 5)
+}
+
+func TestWatchAvailabilityValidatesRequest(t *testing.T) {
+	client := dialTestServer(t, NewServer())
+	stream, err := client.WatchAvailability(context.Background(), &pb.WatchAvailabilityRequest{From: "London"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assertCode(t, err, codes.InvalidArgument, "from without to")
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"slices"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+)
+
+// referenceAlphabet omits characters that are easily confused when a
+// booking reference is read out over the phone (0/O, 1/I/L).
+const referenceAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// randomString returns n characters drawn uniformly from alphabet.
+func randomString(alphabet string, n int) string {
+	max := big.NewInt(int64(len(alphabet)))
+	b := make([]byte, n)
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = alphabet[idx.Int64()]
+	}
+	return string(b)
+}
+
+// newBookingReference returns a six-character PNR never used before.
+// References of cancelled bookings stay taken, since their cancellations
+// and history are still looked up by reference.
+func (s *server) newBookingReference() (string, error) {
+	for {
+		reference := randomString(referenceAlphabet, 6)
+		used, err := s.referenceUsed(reference)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return reference, nil
+		}
+	}
+}
+
+// referenceUsed reports whether any ticket, cancellation or ledger event
+// carries reference.
+func (s *server) referenceUsed(reference string) (bool, error) {
+	tickets, err := s.store.TicketsByReference(reference)
+	if err != nil || len(tickets) > 0 {
+		return len(tickets) > 0, err
+	}
+	cancellations, err := s.store.CancellationsByReference(reference)
+	if err != nil || len(cancellations) > 0 {
+		return len(cancellations) > 0, err
+	}
+	events, err := s.store.EventsByReference(reference)
+	return len(events) > 0, err
+}
+
+// newTicketID returns a ticket id not yet in use.
+func (s *server) newTicketID() (string, error) {
+	for {
+		id := "TKT-" + randomString("0123456789ABCDEF", 12)
+		_, err := s.store.GetTicket(id)
+		if errors.Is(err, ErrNotFound) {
+			return id, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// checkVersion fails with Aborted unless receipt is at the expected
+// version, so a change made from a stale read is refused rather than
+// overwriting someone else's. Zero expects any version.
+func checkVersion(receipt *pb.TicketReceipt, expected int64) error {
+	if expected != 0 && receipt.Version != expected {
+		return trainerr.Aborted("VERSION_MISMATCH", receipt.TicketId,
+			"ticket %s is at version %d, not %d; read it again before changing it", receipt.TicketId, receipt.Version, expected)
+	}
+	return nil
+}
+
+// checkNotCancelling refuses changes to a ticket whose cancellation was
+// interrupted. Cancelling it again completes the cancellation.
+func checkNotCancelling(receipt *pb.TicketReceipt) error {
+	if receipt.PendingCancellation != nil {
+		return trainerr.FailedPrecondition("CANCELLATION_PENDING", receipt.TicketId,
+			"ticket %s is being cancelled; cancel it again to finish", receipt.TicketId)
+	}
+	return nil
+}
+
+// findTicket resolves the single ticket identified by a booking reference
+// and/or email. The email narrows a booking down to one passenger; on its
+// own it is only accepted while the user holds exactly one ticket. Tickets
+// that visible rejects are treated as if they did not exist; nil sees all.
+func (s *server) findTicket(reference, email string, visible func(*pb.TicketReceipt) bool) (*pb.TicketReceipt, error) {
+	var (
+		tickets []*pb.TicketReceipt
+		err     error
+	)
+	if reference != "" {
+		tickets, err = s.store.TicketsByReference(reference)
+	} else {
+		tickets, err = s.store.TicketsByUser(email)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if reference != "" && email != "" {
+		var matched []*pb.TicketReceipt
+		for _, receipt := range tickets {
+			if receipt.User.GetEmail() == email {
+				matched = append(matched, receipt)
+			}
+		}
+		tickets = matched
+	}
+	if visible != nil {
+		tickets = slices.DeleteFunc(tickets, func(receipt *pb.TicketReceipt) bool { return !visible(receipt) })
+	}
+
+	switch {
+	case len(tickets) == 1:
+		return tickets[0], nil
+	case len(tickets) == 0 && reference != "":
+		return nil, trainerr.NotFound(trainerr.ResourceBooking, reference, "booking %s not found", reference)
+	case len(tickets) == 0:
+		return nil, trainerr.NotFound(trainerr.ResourceUser, email, "user not found")
+	case reference != "":
+		return nil, trainerr.InvalidField("email", "booking %s has %d passengers; specify an email", reference, len(tickets))
+	default:
+		return nil, trainerr.InvalidField("booking_reference", "user %s holds %d tickets; specify a booking reference", email, len(tickets))
+	}
+}
+
+func (s *server) ListTicketsForUser(ctx context.Context, req *pb.UserRequest) (*pb.TicketsResponse, error) {
+	if err := authorizeUser(ctx, req.Email, false); err != nil {
+		return nil, err
+	}
+
+	tickets, err := s.store.TicketsByUser(req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.TicketsResponse{Tickets: tickets}, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestSameUserCanHoldManyTickets(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		user := &pb.User{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"}
+		req := &pb.PurchaseTicketRequest{User: user, From: "London", To: "France"}
+
+		first, err := server.PurchaseTicket(context.Background(), req)
+		require.NoError(t, err, "error purchasing first ticket")
+		second, err := server.PurchaseTicket(context.Background(), req)
+		require.NoError(t, err, "error purchasing second ticket")
+
+		assert.Len(t, first.BookingReference, 6, "expected a six-character booking reference")
+		assert.NotEqual(t, first.BookingReference, second.BookingReference, "each purchase is a new booking")
+		assert.NotEqual(t, first.TicketId, second.TicketId, "each ticket has its own id")
+		assert.NotEqual(t, first.Seat.Seat, second.Seat.Seat, "tickets must not share a seat")
+
+		resp, err := server.ListTicketsForUser(context.Background(), &pb.UserRequest{Email: user.Email})
+		require.NoError(t, err, "error listing tickets")
+		require.Len(t, resp.Tickets, 2, "expected both tickets")
+		assert.Equal(t, first.TicketId, resp.Tickets[0].TicketId, "tickets should be listed oldest first")
+
+		_, err = server.GetReceipt(context.Background(), &pb.UserRequest{Email: user.Email})
+		assertCode(t, err, codes.InvalidArgument, "email alone is ambiguous with two tickets")
+
+		receipt, err := server.GetReceipt(context.Background(), &pb.UserRequest{BookingReference: second.BookingReference})
+		require.NoError(t, err, "error fetching receipt by reference")
+		assert.Equal(t, second.TicketId, receipt.TicketId)
+	})
+}
+
+func TestOperationsByBookingReference(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		user := &pb.User{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"}
+		req := &pb.PurchaseTicketRequest{User: user, From: "London", To: "France"}
+
+		first, err := server.PurchaseTicket(context.Background(), req)
+		require.NoError(t, err, "error purchasing first ticket")
+		second, err := server.PurchaseTicket(context.Background(), req)
+		require.NoError(t, err, "error purchasing second ticket")
+
+		moved, err := server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+			BookingReference: first.BookingReference,
+			NewSection:       "B",
+			NewSeat:          3,
+		})
+		require.NoError(t, err, "error modifying seat by reference")
+		assert.Equal(t, first.TicketId, moved.TicketId)
+		assert.Equal(t, "B", moved.Seat.Section)
+
+		_, err = server.RemoveUser(context.Background(), &pb.UserRequest{BookingReference: second.BookingReference})
+		require.NoError(t, err, "error removing by reference")
+
+		// Only the moved ticket remains, so the email is unambiguous again
+		receipt, err := server.GetReceipt(context.Background(), &pb.UserRequest{Email: user.Email})
+		require.NoError(t, err, "error fetching receipt by email")
+		assert.Equal(t, first.TicketId, receipt.TicketId)
+
+		_, err = server.GetReceipt(context.Background(), &pb.UserRequest{BookingReference: "ZZZZZZ"})
+		assertCode(t, err, codes.NotFound, "unknown booking reference should be rejected")
+	})
+}
+
+func TestTicketVersionGuardsChanges(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("john.doe@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+		assert.Equal(t, int64(1), receipt.Version, "new tickets start at version 1")
+
+		moved, err := server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+			BookingReference: receipt.BookingReference, NewSection: "B", AnySeat: true, ExpectedVersion: 1,
+		})
+		require.NoError(t, err, "error moving at the current version")
+		assert.Equal(t, int64(2), moved.Version, "a move should bump the version")
+
+		// A second agent still holding version 1 must not overwrite the move
+		_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+			BookingReference: receipt.BookingReference, NewSection: "A", AnySeat: true, ExpectedVersion: 1,
+		})
+		assertCode(t, err, codes.Aborted, "a stale version should be refused")
+		_, err = server.CancelTicket(context.Background(), &pb.CancelTicketRequest{BookingReference: receipt.BookingReference, ExpectedVersion: 1})
+		assertCode(t, err, codes.Aborted, "a stale version should not cancel")
+		_, err = server.RemoveUser(context.Background(), &pb.UserRequest{BookingReference: receipt.BookingReference, ExpectedVersion: 1})
+		assertCode(t, err, codes.Aborted, "a stale version should not remove")
+
+		current, err := server.GetReceipt(context.Background(), &pb.UserRequest{BookingReference: receipt.BookingReference})
+		require.NoError(t, err, "error getting receipt")
+		assert.Equal(t, int64(2), current.Version)
+		assert.Equal(t, "B", current.Seat.Section, "refused changes must not apply")
+
+		_, err = server.CancelTicket(context.Background(), &pb.CancelTicketRequest{BookingReference: receipt.BookingReference, ExpectedVersion: 2})
+		assert.NoError(t, err, "error cancelling at the current version")
+	})
+}
+
+func TestSwapSeatsBumpsBothVersions(t *testing.T) {
+	server := NewServer()
+	first, err := server.PurchaseTicket(context.Background(), purchaseRequest("first@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	second, err := server.PurchaseTicket(context.Background(), purchaseRequest("second@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+
+	_, err = server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+		BookingReference: first.BookingReference, OtherBookingReference: second.BookingReference, ExpectedVersion: 2,
+	})
+	assertCode(t, err, codes.Aborted, "consent at the wrong version should be refused")
+
+	_, err = server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+		BookingReference: first.BookingReference, OtherBookingReference: second.BookingReference, ExpectedVersion: 1,
+	})
+	require.NoError(t, err, "error consenting to swap")
+	resp, err := server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+		BookingReference: second.BookingReference, OtherBookingReference: first.BookingReference,
+	})
+	require.NoError(t, err, "error completing swap")
+	assert.Equal(t, int64(2), resp.Ticket.Version)
+
+	swapped, err := server.GetReceipt(context.Background(), &pb.UserRequest{BookingReference: first.BookingReference})
+	require.NoError(t, err, "error getting receipt")
+	assert.Equal(t, int64(2), swapped.Version)
+}
+
+func TestExpectedVersionIsValidated(t *testing.T) {
+	client := dialTestServer(t, NewServer())
+
+	_, err := client.CancelTicket(context.Background(), &pb.CancelTicketRequest{Email: "john.doe@example.com", ExpectedVersion: -1})
+	assertCode(t, err, codes.InvalidArgument, "negative versions should be rejected")
+	assert.Equal(t, []string{"expected_version"}, violatedFields(err))
+}
+
+func TestCancelledReferencesAreNotReused(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("john.doe@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+		_, err = server.CancelTicket(context.Background(), &pb.CancelTicketRequest{BookingReference: receipt.BookingReference})
+		require.NoError(t, err, "error cancelling ticket")
+
+		used, err := server.referenceUsed(receipt.BookingReference)
+		require.NoError(t, err)
+		assert.True(t, used, "a cancelled booking's reference must stay taken")
+
+		used, err = server.referenceUsed("ZZZZZZ")
+		require.NoError(t, err)
+		assert.False(t, used)
+	})
+}
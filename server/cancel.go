@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "test_train/protobuf"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RefundPolicy decides how much of the fare a cancellation returns:
+// everything up to FullRefundHours before departure, PartialRefundPercent
+// after that, and nothing once the train has left. Tickets on a departure
+// the operator cancelled are always refunded in full.
+type RefundPolicy struct {
+	FullRefundHours      int
+	PartialRefundPercent int
+}
+
+func defaultRefundPolicy() RefundPolicy {
+	return RefundPolicy{FullRefundHours: 24, PartialRefundPercent: 50}
+}
+
+// WithRefundPolicy sets the policy applied by CancelTicket and RemoveUser.
+func WithRefundPolicy(policy RefundPolicy) Option {
+	return func(s *server) {
+		s.refunds = policy
+	}
+}
+
+// refund returns the amount to refund of paid and the rule that applied.
+func (p RefundPolicy) refund(paid int64, departure *pb.Departure, now time.Time) (int64, string) {
+	if departure.Cancelled {
+		return paid, "departure cancelled by operator"
+	}
+	leaves, ok := departureTime(departure)
+	if !ok {
+		return paid, "open ticket"
+	}
+	switch until := leaves.Sub(now); {
+	case until <= 0:
+		return 0, "after departure"
+	case until >= time.Duration(p.FullRefundHours)*time.Hour:
+		return paid, fmt.Sprintf("%d or more hours before departure", p.FullRefundHours)
+	default:
+		return paid * int64(p.PartialRefundPercent) / 100, fmt.Sprintf("within %d hours of departure", p.FullRefundHours)
+	}
+}
+
+// cancelTicket refunds receipt according to the refund policy, records the
+// cancellation and frees the seat. The cancellation is stored on the ticket
+// as pending before the refund is made, and the refund is keyed by the
+// ticket, so after a failure at any step cancelling again completes the
+// same cancellation and never refunds twice.
+func (s *server) cancelTicket(ctx context.Context, receipt *pb.TicketReceipt, reason string) (*pb.Cancellation, error) {
+	cancellation := receipt.PendingCancellation
+	if cancellation == nil {
+		departure, err := s.departure(receipt.DepartureId)
+		if err != nil {
+			return nil, err
+		}
+		var paid int64
+		if receipt.Payment != nil {
+			paid = receipt.Payment.AmountCents - receipt.Payment.RefundedCents
+		}
+		amount, policy := s.refunds.refund(paid, departure, s.now())
+		cancellation = &pb.Cancellation{
+			TicketId:         receipt.TicketId,
+			BookingReference: receipt.BookingReference,
+			User:             receipt.User,
+			DepartureId:      receipt.DepartureId,
+			CancelledAt:      timestamppb.New(s.now()),
+			Reason:           reason,
+			Policy:           policy,
+			RefundCents:      amount,
+			Currency:         receipt.Fare.GetCurrency(),
+		}
+		if amount > 0 {
+			receipt.PendingCancellation = cancellation
+			if err := s.store.PutTicket(receipt); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if cancellation.RefundCents > 0 {
+		if err := s.refundPayment(ctx, receipt.Payment, cancellation.RefundCents, receipt.TicketId+"/cancelled"); err != nil {
+			return nil, err
+		}
+	}
+
+	receipt.PendingCancellation = nil
+	cancellation.Payment = receipt.Payment
+	// The refund cannot be undone, so the cancellation is recorded before it
+	// is stored; see record
+	if err := s.record(ctx, cancellationEvent(receipt, cancellation)); err != nil {
+		return nil, err
+	}
+	if err := s.store.PutCancellation(cancellation); err != nil {
+		return nil, err
+	}
+	if err := s.store.DeleteTicket(receipt.TicketId); err != nil {
+		return nil, err
+	}
+
+	s.seatChanged(receipt.DepartureId, receipt.Seat, "cancelled")
+	log.Printf("Ticket cancelled: %s refund %d (%s)", receipt.TicketId, cancellation.RefundCents, cancellation.Policy)
+	s.promoteWaitlist(receipt.DepartureId)
+	return cancellation, nil
+}
+
+func (s *server) CancelTicket(ctx context.Context, req *pb.CancelTicketRequest) (*pb.Cancellation, error) {
+	receipt, unlock, err := s.lockTicket(req.BookingReference, req.Email, canRead(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := authorizeUser(ctx, receipt.User.GetEmail(), true); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(receipt, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	return s.cancelTicket(ctx, receipt, req.Reason)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+// newTimedDeparture creates a departure leaving at 2026-10-10 09:00 UTC.
+func newTimedDeparture(t testing.TB, server *server) *pb.Departure {
+	departure, err := server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+		TrainId:     "EUR9300",
+		Date:        "2026-10-10",
+		Time:        "09:00",
+		Origin:      "London",
+		Destination: "Paris",
+	})
+	require.NoError(t, err, "error creating departure")
+	return departure
+}
+
+func TestCancelTicketRefundPolicy(t *testing.T) {
+	leaves := time.Date(2026, 10, 10, 9, 0, 0, 0, time.UTC)
+	for name, tc := range map[string]struct {
+		now    time.Time
+		refund int64
+	}{
+		"well before departure": {now: leaves.Add(-48 * time.Hour), refund: 2000},
+		"close to departure":    {now: leaves.Add(-2 * time.Hour), refund: 1000},
+		"after departure":       {now: leaves.Add(time.Hour), refund: 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			server := NewServer(WithClock(clock.Now))
+			departure := newTimedDeparture(t, server)
+
+			req := purchaseRequest("a@example.com")
+			req.DepartureId = departure.Id
+			req.To = "Paris"
+			receipt, err := server.PurchaseTicket(context.Background(), req)
+			require.NoError(t, err, "error purchasing ticket")
+
+			clock.now = tc.now
+			cancellation, err := server.CancelTicket(context.Background(), &pb.CancelTicketRequest{
+				BookingReference: receipt.BookingReference,
+				Reason:           "change of plans",
+			})
+			require.NoError(t, err, "error cancelling ticket")
+			assert.Equal(t, tc.refund, cancellation.RefundCents)
+			assert.Equal(t, "change of plans", cancellation.Reason)
+			assert.NotEmpty(t, cancellation.Policy)
+			assert.Equal(t, tc.refund, cancellation.Payment.RefundedCents)
+
+			_, err = server.GetReceipt(context.Background(), &pb.UserRequest{BookingReference: receipt.BookingReference})
+			assertCode(t, err, codes.NotFound, "cancelled ticket should be gone")
+		})
+	}
+}
+
+func TestCancelledDepartureRefundsInFull(t *testing.T) {
+	clock := newFakeClock()
+	server := NewServer(WithClock(clock.Now))
+	departure := newTimedDeparture(t, server)
+
+	req := purchaseRequest("a@example.com")
+	req.DepartureId = departure.Id
+	req.To = "Paris"
+	receipt, err := server.PurchaseTicket(context.Background(), req)
+	require.NoError(t, err, "error purchasing ticket")
+
+	_, err = server.CancelDeparture(context.Background(), &pb.DepartureRequest{DepartureId: departure.Id})
+	require.NoError(t, err, "error cancelling departure")
+
+	clock.now = time.Date(2026, 10, 10, 8, 0, 0, 0, time.UTC)
+	cancellation, err := server.CancelTicket(context.Background(), &pb.CancelTicketRequest{BookingReference: receipt.BookingReference})
+	require.NoError(t, err, "error cancelling ticket")
+	assert.Equal(t, receipt.Fare.TotalCents, cancellation.RefundCents)
+	assert.Equal(t, pb.PaymentStatus_PAYMENT_STATUS_REFUNDED, cancellation.Payment.Status)
+}
+
+func TestRemoveUserRefundsAndCleansUp(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("a@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+
+		_, err = server.RemoveUser(context.Background(), &pb.UserRequest{Email: "a@example.com"})
+		require.NoError(t, err, "error removing user")
+
+		cancellations, err := server.store.CancellationsByReference(receipt.BookingReference)
+		require.NoError(t, err, "error reading cancellations")
+		require.Len(t, cancellations, 1, "removal should be recorded as a cancellation")
+		assert.Equal(t, receipt.Fare.TotalCents, cancellations[0].RefundCents, "open tickets are refunded in full")
+
+		tickets, err := server.store.TicketsByUser("a@example.com")
+		require.NoError(t, err)
+		assert.Empty(t, tickets, "user index should be empty")
+		users, err := server.store.UsersBySection(defaultDepartureID, receipt.Seat.Section)
+		require.NoError(t, err)
+		assert.Empty(t, users, "section index should be empty")
+	})
+}
+
+// lossyGateway makes refunds but can report one as failed, as when the
+// provider's reply is lost.
+type lossyGateway struct {
+	*FakeGateway
+	lose bool
+}
+
+func (g *lossyGateway) Refund(ctx context.Context, transactionID string, amountCents int64, idempotencyKey string) (string, error) {
+	id, err := g.FakeGateway.Refund(ctx, transactionID, amountCents, idempotencyKey)
+	if g.lose {
+		g.lose = false
+		return "", errors.New("connection reset")
+	}
+	return id, err
+}
+
+// flakyStore can fail one ticket deletion.
+type flakyStore struct {
+	Store
+	failDelete bool
+}
+
+func (s *flakyStore) DeleteTicket(id string) error {
+	if s.failDelete {
+		s.failDelete = false
+		return errors.New("disk full")
+	}
+	return s.Store.DeleteTicket(id)
+}
+
+func TestInterruptedCancellationRefundsOnce(t *testing.T) {
+	gateway := &lossyGateway{FakeGateway: NewFakeGateway(FakeApprove), lose: true}
+	store := &flakyStore{Store: NewMemoryStore()}
+	server, err := NewServerWithStore(store, WithPaymentProvider(gateway, time.Second))
+	require.NoError(t, err, "error creating server")
+	ctx := context.Background()
+	receipt, err := server.PurchaseTicket(ctx, purchaseRequest("a@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	cancel := &pb.CancelTicketRequest{BookingReference: receipt.BookingReference}
+
+	_, err = server.CancelTicket(ctx, cancel)
+	assertCode(t, err, codes.Unavailable, "refund reply lost")
+	pending, err := server.GetReceipt(ctx, &pb.UserRequest{BookingReference: receipt.BookingReference})
+	require.NoError(t, err, "error fetching receipt")
+	require.NotNil(t, pending.PendingCancellation, "the cancellation should be recorded before the refund")
+	_, err = server.ModifyUserSeat(ctx, &pb.ModifySeatRequest{BookingReference: receipt.BookingReference, NewSection: "B", NewSeat: 5})
+	assertCode(t, err, codes.FailedPrecondition, "moving a ticket being cancelled")
+
+	store.failDelete = true
+	_, err = server.CancelTicket(ctx, cancel)
+	assert.Error(t, err, "ticket deletion should fail")
+
+	cancellation, err := server.CancelTicket(ctx, cancel)
+	require.NoError(t, err, "error completing cancellation")
+	assert.Equal(t, receipt.Fare.TotalCents, cancellation.RefundCents)
+	assert.Equal(t, receipt.Fare.TotalCents, cancellation.Payment.RefundedCents)
+	assert.Len(t, cancellation.Payment.RefundIds, 1)
+	assert.Equal(t, receipt.Fare.TotalCents, gateway.transactions[receipt.Payment.TransactionId].refunded, "the fare should be refunded once")
+	cancellations, err := server.store.CancellationsByReference(receipt.BookingReference)
+	require.NoError(t, err, "error reading cancellations")
+	assert.Len(t, cancellations, 1, "the cancellation should be recorded once")
+}
+
+func TestNonRefundableCancellation(t *testing.T) {
+	clock := newFakeClock()
+	server := NewServer(WithClock(clock.Now), WithRefundPolicy(RefundPolicy{FullRefundHours: 24, PartialRefundPercent: 0}))
+	departure := newTimedDeparture(t, server)
+	receipt, err := buyLeg(server, departure.Id, "a@example.com", "London", "Paris")
+	require.NoError(t, err, "error purchasing ticket")
+
+	clock.now = time.Date(2026, 10, 10, 7, 0, 0, 0, time.UTC)
+	cancellation, err := server.CancelTicket(context.Background(), &pb.CancelTicketRequest{BookingReference: receipt.BookingReference})
+	require.NoError(t, err, "a non-refundable ticket should still cancel")
+	assert.Zero(t, cancellation.RefundCents)
+	assert.Empty(t, cancellation.Payment.RefundIds)
+	assert.Equal(t, pb.PaymentStatus_PAYMENT_STATUS_CAPTURED, cancellation.Payment.Status)
+
+	_, err = server.GetReceipt(context.Background(), &pb.UserRequest{BookingReference: receipt.BookingReference})
+	assertCode(t, err, codes.NotFound, "cancelled ticket should be gone")
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// assertNoDoubleBooking checks that no two tickets on a departure share a
+// seat and returns how many tickets it has.
+func assertNoDoubleBooking(t *testing.T, server *server, departureID string) int {
+	t.Helper()
+	departure, err := server.departure(departureID)
+	require.NoError(t, err, "error getting departure")
+	sold := 0
+	for _, section := range departure.Sections {
+		tickets, err := server.store.SectionTickets(departure.Id, section.Name)
+		require.NoError(t, err, "error listing tickets")
+		seats := make(map[int32]string)
+		for _, ticket := range tickets {
+			if other, ok := seats[ticket.Seat.Seat]; ok {
+				t.Errorf("seat %s%d on %s is sold to both %s and %s", section.Name, ticket.Seat.Seat, departure.Id, other, ticket.TicketId)
+			}
+			seats[ticket.Seat.Seat] = ticket.TicketId
+		}
+		sold += len(tickets)
+	}
+	return sold
+}
+
+func TestConcurrentPurchasesFillExactlyTheCapacity(t *testing.T) {
+	server := NewServer()
+	const capacity = 100 // both sections of the default layout
+
+	const buyers = 3 * capacity
+	var sold, full atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := server.PurchaseTicket(context.Background(), purchaseRequest(fmt.Sprintf("buyer%d@example.com", i)))
+			switch status.Code(err) {
+			case codes.OK:
+				sold.Add(1)
+			case codes.ResourceExhausted:
+				full.Add(1)
+			default:
+				t.Errorf("unexpected error purchasing ticket: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(capacity), sold.Load(), "every seat should be sold once")
+	assert.Equal(t, int32(buyers-capacity), full.Load(), "the rest should find the train full")
+	assert.Equal(t, capacity, assertNoDoubleBooking(t, server, defaultDepartureID))
+}
+
+// TestConcurrentChangesAcrossDepartures runs purchases, moves between
+// departures, holds, cancellations, waitlist calls and reads at once. Run
+// with -race; moves lock two departures, so a lock ordering bug deadlocks.
+// Both departures call at London and Paris, and the route departure's one
+// seat keeps it full most of the time.
+func TestConcurrentChangesAcrossDepartures(t *testing.T) {
+	server := NewServer(WithHoldTTL(time.Minute))
+	departures := []string{newTimedDeparture(t, server).Id, newRouteDeparture(t, server).Id}
+	ctx := context.Background()
+
+	const workers = 16
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				home := departures[(w+i)%len(departures)]
+				away := departures[(w+i+1)%len(departures)]
+				email := fmt.Sprintf("w%d-%d@example.com", w, i)
+
+				receipt, err := buyLeg(server, home, email, "London", "Paris")
+				if status.Code(err) == codes.ResourceExhausted {
+					req := waitlistRequest(email)
+					req.DepartureId, req.To = home, "Paris"
+					entry, err := server.JoinWaitlist(ctx, req)
+					if err == nil {
+						_, err = server.LeaveWaitlist(ctx, &pb.WaitlistEntryRequest{EntryId: entry.EntryId})
+					}
+					if err != nil && status.Code(err) != codes.FailedPrecondition && status.Code(err) != codes.NotFound {
+						t.Errorf("unexpected waitlist error: %v", err)
+					}
+					continue
+				}
+				if !assert.NoError(t, err, "error purchasing ticket") {
+					return
+				}
+
+				_, err = server.ModifyUserSeat(ctx, &pb.ModifySeatRequest{BookingReference: receipt.BookingReference, DepartureId: away, NewSection: "A", AnySeat: true})
+				if err != nil && status.Code(err) != codes.ResourceExhausted {
+					t.Errorf("unexpected error moving ticket: %v", err)
+				}
+				if _, err := server.GetSeatMap(ctx, &pb.SeatMapRequest{DepartureId: away}); err != nil {
+					t.Errorf("error reading seat map: %v", err)
+				}
+				if hold, err := server.HoldSeat(ctx, legRequest(away, "holder-"+email, "London", "Paris")); err == nil {
+					if _, err := server.ConfirmHold(ctx, &pb.HoldRequest{Token: hold.Token}); err != nil {
+						t.Errorf("error confirming hold: %v", err)
+					}
+				}
+				if _, err := server.CancelTicket(ctx, &pb.CancelTicketRequest{BookingReference: receipt.BookingReference}); err != nil {
+					t.Errorf("error cancelling ticket: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, id := range departures {
+		assertNoDoubleBooking(t, server, id)
+	}
+}
+
+func TestUnknownDeparturesLeaveNoLockState(t *testing.T) {
+	server := NewServer()
+	ctx := context.Background()
+	_, err := server.PurchaseTicket(ctx, purchaseRequest("john.doe@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	states := len(server.states.byID)
+
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("NOPE-%d", i)
+		_, err := buyLeg(server, id, "jane.smith@example.com", "London", "France")
+		assertCode(t, err, codes.NotFound, "purchase on an unknown departure")
+		_, err = server.HoldSeat(ctx, legRequest(id, "jane.smith@example.com", "London", "France"))
+		assertCode(t, err, codes.NotFound, "hold on an unknown departure")
+		req := waitlistRequest("jane.smith@example.com")
+		req.DepartureId = id
+		_, err = server.JoinWaitlist(ctx, req)
+		assertCode(t, err, codes.NotFound, "waitlist on an unknown departure")
+		_, err = server.CancelDeparture(ctx, &pb.DepartureRequest{DepartureId: id})
+		assertCode(t, err, codes.NotFound, "cancelling an unknown departure")
+		_, err = server.GetSeatMap(ctx, &pb.SeatMapRequest{DepartureId: id})
+		assertCode(t, err, codes.NotFound, "seat map of an unknown departure")
+	}
+	assert.Len(t, server.states.byID, states, "unknown departures should not keep lock state")
+}
+
+// The benchmarks below measure bookings on one departure and spread over
+// two, and reads beside a writer:
+//
+//	go test -run '^$' -bench . -cpu 1,2,4,8 ./server
+//
+// Every store change takes the store's single lock, so bookings on separate
+// departures are no faster than bookings on one.
+
+// quietLog discards the server's log for the rest of b, since writing it
+// would dominate the timings.
+func quietLog(b *testing.B) {
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+}
+
+// benchmarkPurchases buys and removes a ticket per iteration, spreading
+// the goroutines over the departures of requests.
+func benchmarkPurchases(b *testing.B, server *server, requests ...*pb.PurchaseTicketRequest) {
+	var next atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(p *testing.PB) {
+		worker := next.Add(1)
+		req := requests[int(worker)%len(requests)]
+		ctx := context.Background()
+		for p.Next() {
+			receipt, err := server.PurchaseTicket(ctx, req)
+			if err != nil {
+				b.Errorf("error purchasing ticket: %v", err)
+				return
+			}
+			if _, err := server.RemoveUser(ctx, &pb.UserRequest{BookingReference: receipt.BookingReference}); err != nil {
+				b.Errorf("error removing ticket: %v", err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkPurchase(b *testing.B) {
+	quietLog(b)
+	b.Run("OneDeparture", func(b *testing.B) {
+		server := NewServer()
+		benchmarkPurchases(b, server, purchaseRequest("bench@example.com"))
+	})
+	b.Run("TwoDepartures", func(b *testing.B) {
+		server := NewServer()
+		timed := newTimedDeparture(b, server)
+		benchmarkPurchases(b, server, purchaseRequest("bench@example.com"), legRequest(timed.Id, "bench@example.com", "London", "Paris"))
+	})
+}
+
+// BenchmarkReads reads a departure's seat map and receipts while another
+// goroutine keeps booking and cancelling on it.
+func BenchmarkReads(b *testing.B) {
+	quietLog(b)
+	server := NewServer()
+	ctx := context.Background()
+	var references []string
+	for i := 0; i < 50; i++ {
+		receipt, err := server.PurchaseTicket(ctx, purchaseRequest(fmt.Sprintf("reader%d@example.com", i)))
+		require.NoError(b, err, "error purchasing ticket")
+		references = append(references, receipt.BookingReference)
+	}
+
+	stop := make(chan struct{})
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			receipt, err := server.PurchaseTicket(ctx, purchaseRequest("writer@example.com"))
+			if err == nil {
+				server.RemoveUser(ctx, &pb.UserRequest{BookingReference: receipt.BookingReference})
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		writer.Wait()
+	}()
+
+	b.Run("SeatMap", func(b *testing.B) {
+		b.RunParallel(func(p *testing.PB) {
+			for p.Next() {
+				if _, err := server.GetSeatMap(ctx, &pb.SeatMapRequest{}); err != nil {
+					b.Errorf("error reading seat map: %v", err)
+					return
+				}
+			}
+		})
+	})
+	b.Run("Receipt", func(b *testing.B) {
+		var i atomic.Int64
+		b.RunParallel(func(p *testing.PB) {
+			for p.Next() {
+				reference := references[int(i.Add(1))%len(references)]
+				if _, err := server.GetReceipt(ctx, &pb.UserRequest{BookingReference: reference}); err != nil {
+					b.Errorf("error reading receipt: %v", err)
+					return
+				}
+			}
+		})
+	})
+}
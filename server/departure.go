@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+)
+
+// defaultDepartureID is used by requests that do not name a departure, so
+// clients written against the single-train API keep working.
+const defaultDepartureID = "default"
+
+// defaultLayout returns the two 50-seat sections every train had before
+// layouts were configurable.
+func defaultLayout() []*pb.SectionLayout {
+	return []*pb.SectionLayout{
+		{Name: "A", Seats: 50, SeatClass: "standard"},
+		{Name: "B", Seats: 50, SeatClass: "standard"},
+	}
+}
+
+func defaultDeparture(sections []*pb.SectionLayout) *pb.Departure {
+	return &pb.Departure{
+		Id:          defaultDepartureID,
+		Origin:      "London",
+		Destination: "France",
+		Sections:    sections,
+		Stations:    []string{"London", "France"},
+	}
+}
+
+// departure resolves id, falling back to the default departure when empty.
+func (s *server) departure(id string) (*pb.Departure, error) {
+	if id == "" {
+		id = defaultDepartureID
+	}
+	departure, err := s.store.GetDeparture(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, trainerr.NotFound(trainerr.ResourceDeparture, id, "departure %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return departure, nil
+}
+
+// departureTime returns when a departure leaves its origin. Departures with
+// no date have no known time; a date without a time counts from midnight UTC.
+func departureTime(departure *pb.Departure) (time.Time, bool) {
+	date, err := time.Parse(time.DateOnly, departure.Date)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if clock, err := time.Parse("15:04", departure.Time); err == nil {
+		date = date.Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute)
+	}
+	return date, true
+}
+
+// bookableDeparture is like departure but rejects cancelled departures.
+func (s *server) bookableDeparture(id string) (*pb.Departure, error) {
+	departure, err := s.departure(id)
+	if err != nil {
+		return nil, err
+	}
+	if departure.Cancelled {
+		return nil, trainerr.FailedPrecondition("DEPARTURE_CANCELLED", departure.Id, "departure %s is cancelled", departure.Id)
+	}
+	return departure, nil
+}
+
+// departureID names a departure after its train and date, and its time
+// when it has one, so a train can run more than one service a day.
+func departureID(trainID, date, clock string) string {
+	id := trainID + "-" + date
+	if clock != "" {
+		id += "-" + strings.ReplaceAll(clock, ":", "")
+	}
+	return id
+}
+
+func (s *server) CreateDeparture(ctx context.Context, req *pb.CreateDepartureRequest) (*pb.Departure, error) {
+	if req.TrainId == "" {
+		return nil, trainerr.InvalidField("train_id", "train id is required")
+	}
+	if _, err := time.Parse(time.DateOnly, req.Date); err != nil {
+		return nil, trainerr.InvalidField("date", "date must be YYYY-MM-DD")
+	}
+	if req.Time != "" {
+		if _, err := time.Parse("15:04", req.Time); err != nil {
+			return nil, trainerr.InvalidField("time", "time must be HH:MM")
+		}
+	}
+	if req.Origin == "" {
+		return nil, trainerr.InvalidField("origin", "origin is required")
+	}
+	if req.Destination == "" {
+		return nil, trainerr.InvalidField("destination", "destination is required")
+	}
+
+	sections := req.Sections
+	if len(sections) == 0 {
+		name := req.Layout
+		if name == "" {
+			name = s.layouts.Default
+		}
+		layout, ok := s.layouts.ByName[name]
+		if !ok {
+			return nil, trainerr.InvalidField("layout", "layout %s not found", name)
+		}
+		sections = layout
+	}
+	if err := validateLayout(sections); err != nil {
+		return nil, trainerr.InvalidField("sections", "%v", err)
+	}
+
+	stations := append(append([]string{req.Origin}, req.Via...), req.Destination)
+	called := make(map[string]bool)
+	for _, station := range stations {
+		if station == "" || called[station] {
+			return nil, trainerr.InvalidField("via", "route stations must be non-empty and distinct")
+		}
+		called[station] = true
+	}
+	if len(req.SegmentKm) > 0 && len(req.SegmentKm) != len(stations)-1 {
+		return nil, trainerr.InvalidField("segment_km", "expected %d segment lengths, got %d", len(stations)-1, len(req.SegmentKm))
+	}
+	for _, km := range req.SegmentKm {
+		if km <= 0 {
+			return nil, trainerr.InvalidField("segment_km", "segment lengths must be positive")
+		}
+	}
+
+	id := departureID(req.TrainId, req.Date, req.Time)
+	defer s.lockDepartures(id)()
+	if _, err := s.store.GetDeparture(id); err == nil {
+		return nil, trainerr.AlreadyExists(trainerr.ResourceDeparture, id, "departure %s already exists", id)
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	departure := &pb.Departure{
+		Id:          id,
+		TrainId:     req.TrainId,
+		Date:        req.Date,
+		Origin:      req.Origin,
+		Destination: req.Destination,
+		Sections:    sections,
+		Stations:    stations,
+		SegmentKm:   req.SegmentKm,
+		Time:        req.Time,
+	}
+	if err := s.record(ctx, departureEvent(pb.BookingEventType_BOOKING_EVENT_TYPE_DEPARTURE_CREATED, departure)); err != nil {
+		return nil, err
+	}
+	if err := s.store.PutDeparture(departure); err != nil {
+		return nil, err
+	}
+	return departure, nil
+}
+
+func (s *server) ListDepartures(ctx context.Context, req *pb.ListDeparturesRequest) (*pb.ListDeparturesResponse, error) {
+	all, err := s.store.ListDepartures()
+	if err != nil {
+		return nil, err
+	}
+
+	departures := []*pb.Departure{}
+	for _, departure := range all {
+		if req.TrainId != "" && departure.TrainId != req.TrainId {
+			continue
+		}
+		if req.Date != "" && departure.Date != req.Date {
+			continue
+		}
+		if departure.Cancelled && !req.IncludeCancelled {
+			continue
+		}
+		departures = append(departures, departure)
+	}
+	sort.Slice(departures, func(i, j int) bool {
+		if departures[i].Date != departures[j].Date {
+			return departures[i].Date < departures[j].Date
+		}
+		if departures[i].Time != departures[j].Time {
+			return departures[i].Time < departures[j].Time
+		}
+		return departures[i].Id < departures[j].Id
+	})
+
+	return &pb.ListDeparturesResponse{Departures: departures}, nil
+}
+
+func (s *server) CancelDeparture(ctx context.Context, req *pb.DepartureRequest) (*pb.Departure, error) {
+	defer s.lockDepartures(req.DepartureId)()
+
+	departure, err := s.departure(req.DepartureId)
+	if err != nil {
+		return nil, err
+	}
+	if departure.Cancelled {
+		return nil, trainerr.FailedPrecondition("DEPARTURE_CANCELLED", departure.Id, "departure %s is already cancelled", departure.Id)
+	}
+
+	departure.Cancelled = true
+	if err := s.store.PutDeparture(departure); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, departureEvent(pb.BookingEventType_BOOKING_EVENT_TYPE_DEPARTURE_CANCELLED, departure)); err != nil {
+		departure.Cancelled = false
+		if restoreErr := s.store.PutDeparture(departure); restoreErr != nil {
+			log.Printf("Failed to restore departure %s: %v", departure.Id, restoreErr)
+		}
+		return nil, err
+	}
+	return departure, nil
+}
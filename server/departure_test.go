@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateAndListDepartures(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		for _, date := range []string{"2026-11-02", "2026-11-01"} {
+			_, err := server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+				TrainId:     "EUR9001",
+				Date:        date,
+				Origin:      "London",
+				Destination: "Paris",
+			})
+			require.NoError(t, err, "error creating departure")
+		}
+
+		_, err := server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+			TrainId:     "EUR9001",
+			Date:        "2026-11-01",
+			Origin:      "London",
+			Destination: "Paris",
+		})
+		assertCode(t, err, codes.AlreadyExists, "duplicate departure should be rejected")
+
+		_, err = server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+			TrainId:     "EUR9001",
+			Date:        "01/11/2026",
+			Origin:      "London",
+			Destination: "Paris",
+		})
+		assertCode(t, err, codes.InvalidArgument, "malformed date should be rejected")
+
+		resp, err := server.ListDepartures(context.Background(), &pb.ListDeparturesRequest{TrainId: "EUR9001"})
+		require.NoError(t, err, "error listing departures")
+		require.Len(t, resp.Departures, 2, "expected 2 departures")
+		assert.Equal(t, "EUR9001-2026-11-01", resp.Departures[0].Id, "departures should be ordered by date")
+		assert.Len(t, resp.Departures[0].Sections, 2, "expected the default layout")
+	})
+}
+
+func TestTrainRunsSeveralServicesADay(t *testing.T) {
+	server := NewServer()
+	create := func(clock string) (*pb.Departure, error) {
+		return server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+			TrainId:     "EUR9001",
+			Date:        "2026-11-01",
+			Time:        clock,
+			Origin:      "London",
+			Destination: "Paris",
+		})
+	}
+
+	evening, err := create("18:30")
+	require.NoError(t, err, "error creating evening service")
+	morning, err := create("07:15")
+	require.NoError(t, err, "error creating morning service")
+	assert.Equal(t, "EUR9001-2026-11-01-0715", morning.Id)
+	_, err = create("07:15")
+	assertCode(t, err, codes.AlreadyExists, "a second service at the same time should be rejected")
+
+	resp, err := server.ListDepartures(context.Background(), &pb.ListDeparturesRequest{TrainId: "EUR9001"})
+	require.NoError(t, err, "error listing departures")
+	require.Len(t, resp.Departures, 2)
+	assert.Equal(t, []string{morning.Id, evening.Id}, []string{resp.Departures[0].Id, resp.Departures[1].Id}, "services should be ordered by time")
+}
+
+func TestSeatInventoryIsPerDeparture(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		departure, err := server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+			TrainId:     "EUR9002",
+			Date:        "2026-11-01",
+			Origin:      "London",
+			Destination: "Paris",
+			Sections:    []*pb.SectionLayout{{Name: "C", Seats: 1}},
+		})
+		require.NoError(t, err, "error creating departure")
+
+		user := &pb.User{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"}
+		receipt, err := server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+			User:        user,
+			From:        "London",
+			To:          "Paris",
+			DepartureId: departure.Id,
+		})
+		require.NoError(t, err, "error purchasing ticket")
+		assert.Equal(t, departure.Id, receipt.DepartureId, "receipt should name the departure")
+		assert.Equal(t, "C", receipt.Seat.Section, "expected section C")
+		assert.Equal(t, int32(1), receipt.Seat.Seat, "expected seat 1")
+
+		_, err = server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+			User:        &pb.User{FirstName: "Jane", LastName: "Smith", Email: "jane.smith@example.com"},
+			From:        "London",
+			To:          "Paris",
+			DepartureId: departure.Id,
+		})
+		assertCode(t, err, codes.ResourceExhausted, "single-seat departure should be full")
+
+		// The default departure has its own inventory
+		receipt, err = server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+			User: &pb.User{FirstName: "Jane", LastName: "Smith", Email: "jane.smith@example.com"},
+			From: "London",
+			To:   "France",
+		})
+		require.NoError(t, err, "error purchasing ticket on default departure")
+		assert.Equal(t, defaultDepartureID, receipt.DepartureId)
+		assert.Equal(t, int32(1), receipt.Seat.Seat, "expected seat 1")
+
+		resp, err := server.GetUsersBySection(context.Background(), &pb.SectionRequest{Section: "C", DepartureId: departure.Id})
+		require.NoError(t, err, "error fetching users by section")
+		assert.Len(t, resp.Users, 1, "expected 1 user in section C")
+	})
+}
+
+func TestCreateDepartureValidatesSections(t *testing.T) {
+	server := NewServer()
+	for name, sections := range map[string][]*pb.SectionLayout{
+		"no seats":          {{Name: "A", Seats: 0}},
+		"negative seats":    {{Name: "A", Seats: -4}},
+		"duplicate section": {{Name: "A", Seats: 2}, {Name: "A", Seats: 2}},
+		"unknown attribute": {{Name: "A", Seats: 2, SeatAttributes: []*pb.SeatAttributes{{Seat: 1, Attributes: []string{"sunroof"}}}}},
+		"seat out of range": {{Name: "A", Seats: 2, SeatAttributes: []*pb.SeatAttributes{{Seat: 3, Attributes: []string{"window"}}}}},
+		"seat listed twice": {{Name: "A", Seats: 2, SeatAttributes: []*pb.SeatAttributes{{Seat: 1, Attributes: []string{"window"}}, {Seat: 1, Attributes: []string{"table"}}}}},
+		"row too wide":      {{Name: "A", Seats: 2, SeatsPerRow: 3}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := &pb.CreateDepartureRequest{TrainId: "EUR9004", Date: "2026-11-01", Origin: "London", Destination: "Paris", Sections: sections}
+			_, err := server.CreateDeparture(context.Background(), req)
+			assertCode(t, err, codes.InvalidArgument, "the handler should reject the sections")
+			assert.Equal(t, []string{"sections"}, violatedFields(server.validate(context.Background(), req)), "so should request validation")
+		})
+	}
+}
+
+func TestCancelDeparture(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		departure, err := server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+			TrainId:     "EUR9003",
+			Date:        "2026-11-01",
+			Origin:      "London",
+			Destination: "Paris",
+		})
+		require.NoError(t, err, "error creating departure")
+
+		cancelled, err := server.CancelDeparture(context.Background(), &pb.DepartureRequest{DepartureId: departure.Id})
+		require.NoError(t, err, "error cancelling departure")
+		assert.True(t, cancelled.Cancelled, "departure should be cancelled")
+
+		_, err = server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+			User:        &pb.User{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"},
+			From:        "London",
+			To:          "Paris",
+			DepartureId: departure.Id,
+		})
+		assertCode(t, err, codes.FailedPrecondition, "cancelled departure should not be bookable")
+
+		resp, err := server.ListDepartures(context.Background(), &pb.ListDeparturesRequest{TrainId: "EUR9003"})
+		require.NoError(t, err, "error listing departures")
+		assert.Empty(t, resp.Departures, "cancelled departures are hidden by default")
+
+		resp, err = server.ListDepartures(context.Background(), &pb.ListDeparturesRequest{TrainId: "EUR9003", IncludeCancelled: true})
+		require.NoError(t, err, "error listing departures")
+		assert.Len(t, resp.Departures, 1, "expected the cancelled departure")
+	})
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"test_train/gateway"
+	pb "test_train/protobuf"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// openAPIInfo describes the service in the generated OpenAPI document.
+var openAPIInfo = gateway.Info{
+	Title:       "Train booking API",
+	Description: "HTTP/JSON bindings of train.TrainService. Errors are google.rpc.Status objects.",
+	Version:     "1.0.0",
+}
+
+// openAPIDocument returns the OpenAPI document for the HTTP gateway, as
+// committed to openapi.json at the repository root.
+func openAPIDocument() ([]byte, error) {
+	return gateway.OpenAPI(pb.File_train_schema_proto.Services().ByName("TrainService"), openAPIInfo)
+}
+
+// pipeListener is an in-memory net.Listener. The HTTP gateway reaches the
+// gRPC service through one so it needs no network port or certificates of
+// its own.
+type pipeListener struct {
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{conns: make(chan net.Conn), done: make(chan struct{})}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr { return pipeAddr{} }
+
+// DialContext connects to the listener.
+func (l *pipeListener) DialContext(ctx context.Context, _ string) (net.Conn, error) {
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.done:
+		return nil, errors.New("gateway listener closed")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "gateway" }
+
+// newGateway returns an HTTP handler for the service's HTTP bindings and
+// its OpenAPI document at /openapi.json. Calls go through an in-process gRPC
+// server with the same interceptors as the public one, which takes the
+// verified client certificate of an HTTPS request as the call's own. stop
+// shuts it down.
+func newGateway(trainServer *server) (handler http.Handler, stop func(), err error) {
+	doc, err := openAPIDocument()
+	if err != nil {
+		return nil, nil, err
+	}
+	listener := newPipeListener()
+	grpcServer := newGRPCServer(trainServer,
+		grpc.ChainUnaryInterceptor(gateway.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(gateway.StreamServerInterceptor()),
+	)
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///gateway",
+		grpc.WithContextDialer(listener.DialContext),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		grpcServer.Stop()
+		return nil, nil, err
+	}
+	stop = func() {
+		conn.Close()
+		grpcServer.GracefulStop()
+	}
+	gw, err := gateway.New(pb.File_train_schema_proto.Services().ByName("TrainService"), conn)
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /openapi.json", gateway.ServeOpenAPI(doc))
+	mux.Handle("/", gw)
+	return mux, stop, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"test_train/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveGateway serves server's HTTP gateway until the test ends and returns
+// its base URL.
+func serveGateway(t *testing.T, server *server) string {
+	t.Helper()
+	handler, stop, err := newGateway(server)
+	require.NoError(t, err, "error starting gateway")
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(func() {
+		httpServer.Close()
+		stop()
+	})
+	return httpServer.URL
+}
+
+// call makes an HTTP request and decodes the JSON response into a map.
+func call(t *testing.T, method, url, body string, header ...string) (int, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err, "error building request")
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "error calling gateway")
+	defer resp.Body.Close()
+	var decoded map[string]any
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "error reading response")
+	require.NoError(t, json.Unmarshal(data, &decoded), "response is not JSON: %s", data)
+	return resp.StatusCode, decoded
+}
+
+const johnJSON = `{"user": {"firstName": "John", "lastName": "Doe", "email": "john.doe@example.com"}, "from": "London", "to": "France"}`
+
+func TestGatewayBooking(t *testing.T) {
+	url := serveGateway(t, NewServer())
+
+	code, receipt := call(t, "POST", url+"/v1/tickets", johnJSON)
+	require.Equal(t, http.StatusOK, code, "error purchasing ticket: %v", receipt)
+	assert.Equal(t, map[string]any{"section": "A", "seat": float64(1)}, receipt["seat"])
+	reference := receipt["bookingReference"].(string)
+
+	code, fetched := call(t, "GET", url+"/v1/tickets/john.doe@example.com", "")
+	require.Equal(t, http.StatusOK, code, "error fetching receipt: %v", fetched)
+	assert.Equal(t, reference, fetched["bookingReference"])
+	code, fetched = call(t, "GET", url+"/v1/bookings/"+reference+"/ticket", "")
+	require.Equal(t, http.StatusOK, code, "error fetching receipt by reference: %v", fetched)
+	assert.Equal(t, receipt["ticketId"], fetched["ticketId"])
+
+	code, users := call(t, "GET", url+"/v1/sections/A/users", "")
+	require.Equal(t, http.StatusOK, code, "error listing section: %v", users)
+	assert.Len(t, users["users"], 1)
+
+	code, moved := call(t, "PATCH", url+"/v1/tickets/john.doe@example.com/seat", `{"newSection": "B", "newSeat": 5}`)
+	require.Equal(t, http.StatusOK, code, "error modifying seat: %v", moved)
+	assert.Equal(t, map[string]any{"section": "B", "seat": float64(5)}, moved["seat"])
+
+	code, quote := call(t, "GET", url+"/v1/departures/"+defaultDepartureID+"/fare?from=London&to=France&passenger_type=PASSENGER_TYPE_CHILD", "")
+	require.Equal(t, http.StatusOK, code, "error quoting fare: %v", quote)
+	assert.NotEmpty(t, quote["totalCents"])
+
+	code, _ = call(t, "DELETE", url+"/v1/tickets/john.doe@example.com", "")
+	require.Equal(t, http.StatusOK, code, "error removing user")
+	code, _ = call(t, "GET", url+"/v1/tickets/john.doe@example.com", "")
+	assert.Equal(t, http.StatusNotFound, code, "user should not exist after removal")
+}
+
+func TestGatewayErrors(t *testing.T) {
+	url := serveGateway(t, NewServer())
+
+	code, body := call(t, "POST", url+"/v1/tickets", `{"user": {"email": "not-an-email"}}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, float64(3), body["code"], "body should be a google.rpc.Status")
+	assert.NotEmpty(t, body["details"], "field violations should be included")
+
+	code, _ = call(t, "POST", url+"/v1/tickets", `{"seat": `)
+	assert.Equal(t, http.StatusBadRequest, code, "malformed JSON")
+	code, _ = call(t, "GET", url+"/v1/sections/A/users?no_such_field=1", "")
+	assert.Equal(t, http.StatusBadRequest, code, "unknown query parameter")
+
+	code, _ = call(t, "POST", url+"/v1/tickets", johnJSON)
+	require.Equal(t, http.StatusOK, code, "error purchasing ticket")
+	code, _ = call(t, "PATCH", url+"/v1/tickets/jane@example.com/seat", `{"newSection": "B", "newSeat": 5}`)
+	assert.Equal(t, http.StatusNotFound, code, "unknown passenger")
+
+	resp, err := http.Get(url + "/openapi.json")
+	require.NoError(t, err, "error fetching OpenAPI document")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGatewayForwardsCredentials(t *testing.T) {
+	keys := testKeySet()
+	url := serveGateway(t, NewServer(WithKeySet(keys)))
+	token, err := keys.Sign(auth.Claims{Subject: "john", Email: "john.doe@example.com", Role: auth.RolePassenger, ExpiresAt: 1 << 40})
+	require.NoError(t, err, "error signing token")
+
+	resp, err := http.Get(url + "/v1/departures")
+	require.NoError(t, err, "error calling gateway")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+
+	code, _ := call(t, "POST", url+"/v1/tickets", johnJSON, "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, code, "error purchasing ticket with a token")
+	code, _ = call(t, "GET", url+"/v1/sections/A/users", "", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusForbidden, code, "passengers may not list sections")
+}
+
+func TestGatewayStreamsAvailability(t *testing.T) {
+	url := serveGateway(t, NewServer())
+
+	resp, err := http.Get(url + "/v1/departures/" + defaultDepartureID + "/availability")
+	require.NoError(t, err, "error opening stream")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(resp.Body)
+	require.True(t, lines.Scan(), "expected a snapshot line")
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(lines.Bytes(), &event))
+	assert.Contains(t, event, "snapshot")
+
+	code, _ := call(t, "POST", url+"/v1/tickets", johnJSON)
+	require.Equal(t, http.StatusOK, code, "error purchasing ticket")
+	require.True(t, lines.Scan(), "expected a change line")
+	require.NoError(t, json.Unmarshal(lines.Bytes(), &event))
+	assert.Contains(t, event, "change")
+
+	resp, err = http.Get(url + "/v1/departures/NOPE/availability")
+	require.NoError(t, err, "error opening stream")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "errors before the first message keep their status")
+}
+
+func TestOpenAPIDocumentIsCurrent(t *testing.T) {
+	committed, err := os.ReadFile("../openapi.json")
+	require.NoError(t, err, "error reading openapi.json")
+	doc, err := openAPIDocument()
+	require.NoError(t, err, "error generating OpenAPI document")
+	assert.Equal(t, string(doc)+"\n", string(committed), "openapi.json is stale; regenerate it with go run ./server -openapi=openapi.json")
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// PurchaseGroupTicket books every passenger in req on one journey under a
+// single booking reference. Seats are allocated together, the group is
+// charged once, and if any step fails nothing is booked.
+func (s *server) PurchaseGroupTicket(ctx context.Context, req *pb.PurchaseGroupTicketRequest) (*pb.GroupReceipt, error) {
+	if len(req.Passengers) == 0 {
+		return nil, trainerr.InvalidField("passengers", "at least one passenger is required")
+	}
+	var emails []string
+	for i, passenger := range req.Passengers {
+		if passenger.User == nil {
+			return nil, trainerr.InvalidField(fmt.Sprintf("passengers[%d].user", i), "is required")
+		}
+		emails = append(emails, passenger.User.Email)
+	}
+	if err := authorizeAnyUser(ctx, emails, true); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeCompanion(ctx, req.Preferences); err != nil {
+		return nil, err
+	}
+
+	group, holds, err := s.reserveGroup(req)
+	if err != nil {
+		return nil, err
+	}
+	// The seats stay reserved while the payment runs unlocked
+	payment, err := s.charge(ctx, group.BookingReference, group.TotalCents, group.Currency, req.PaymentToken)
+
+	defer s.lockDepartures(group.DepartureId)()
+	for _, hold := range holds {
+		s.releaseHold(hold, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	group.Payment = payment
+	// Each ticket records its share of the one transaction so it can be
+	// cancelled and refunded on its own.
+	for _, receipt := range group.Tickets {
+		share := proto.Clone(payment).(*pb.PaymentInfo)
+		share.AmountCents = receipt.Fare.TotalCents
+		receipt.Payment = share
+	}
+
+	if _, err := s.bookableDeparture(group.DepartureId); err != nil {
+		// refundPayment logs its own failure for reconciliation
+		s.refundPayment(ctx, payment, payment.AmountCents, group.BookingReference+"/unbooked")
+		return nil, err
+	}
+	// unbook gives up the booking after some of its tickets were stored
+	unbook := func(stored []*pb.TicketReceipt) {
+		s.unstoreTickets(stored...)
+		// refundPayment logs its own failure for reconciliation
+		s.refundPayment(ctx, payment, payment.AmountCents, group.BookingReference+"/unbooked")
+	}
+	events := make([]*pb.BookingEvent, 0, len(group.Tickets))
+	for _, receipt := range group.Tickets {
+		if err := s.store.PutTicket(receipt); err != nil {
+			unbook(group.Tickets[:len(events)])
+			return nil, err
+		}
+		events = append(events, purchaseEvent(receipt))
+	}
+	if err := s.record(ctx, events...); err != nil {
+		unbook(group.Tickets)
+		return nil, err
+	}
+
+	for _, receipt := range group.Tickets {
+		s.seatChanged(receipt.DepartureId, receipt.Seat, "purchased")
+	}
+	log.Printf("Group booking %s purchased: %d tickets, %d %s", group.BookingReference, len(group.Tickets), group.TotalCents, group.Currency)
+	return group, nil
+}
+
+// reserveGroup allocates and prices seats for every passenger of req and
+// reserves them until the returned holds are released.
+func (s *server) reserveGroup(req *pb.PurchaseGroupTicketRequest) (*pb.GroupReceipt, []*seatHold, error) {
+	defer s.lockDepartures(req.DepartureId)()
+
+	departure, err := s.bookableDeparture(req.DepartureId)
+	if err != nil {
+		return nil, nil, err
+	}
+	journey, err := resolveLeg(departure, req.From, req.To)
+	if err != nil {
+		return nil, nil, err
+	}
+	seats, err := s.allocateSeats(departure, journey, len(req.Passengers), req.Preferences)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reference, err := s.newBookingReference()
+	if err != nil {
+		return nil, nil, err
+	}
+	group := &pb.GroupReceipt{BookingReference: reference, DepartureId: departure.Id}
+	for i, passenger := range req.Passengers {
+		layout := findSection(departure, seats[i].Section)
+		in, err := s.fareInput(departure, journey, layout.SeatClass, passenger.PassengerType)
+		if err != nil {
+			return nil, nil, err
+		}
+		fare := s.pricing.quote(in)
+		ticketID, err := s.newTicketID()
+		if err != nil {
+			return nil, nil, err
+		}
+		group.Tickets = append(group.Tickets, &pb.TicketReceipt{
+			User:             passenger.User,
+			From:             req.From,
+			To:               req.To,
+			Price:            wholeUnits(fare.TotalCents),
+			Seat:             seats[i],
+			DepartureId:      departure.Id,
+			BookingReference: reference,
+			TicketId:         ticketID,
+			Fare:             fare,
+			PassengerType:    passenger.PassengerType,
+			Version:          1,
+		})
+		group.TotalCents += fare.TotalCents
+		group.Currency = fare.Currency
+	}
+
+	var holds []*seatHold
+	for _, receipt := range group.Tickets {
+		hold := s.reserve(nil, &allocatedSeat{departureID: departure.Id, seat: receipt.Seat, fare: receipt.Fare}, journey)
+		hold.reservation, hold.charging = true, true
+		holds = append(holds, hold)
+	}
+	return group, holds, nil
+}
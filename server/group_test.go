@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func groupRequest(size int) *pb.PurchaseGroupTicketRequest {
+	req := &pb.PurchaseGroupTicketRequest{From: "London", To: "France"}
+	for i := 0; i < size; i++ {
+		req.Passengers = append(req.Passengers, &pb.GroupPassenger{
+			User: &pb.User{FirstName: "Member", LastName: fmt.Sprint(i), Email: fmt.Sprintf("member%d@example.com", i)},
+		})
+	}
+	return req
+}
+
+func TestPurchaseGroupTicket(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		req := groupRequest(4)
+		req.Passengers[3].PassengerType = pb.PassengerType_PASSENGER_TYPE_CHILD
+		group, err := server.PurchaseGroupTicket(context.Background(), req)
+		require.NoError(t, err, "error purchasing group ticket")
+
+		require.Len(t, group.Tickets, 4)
+		var total int64
+		for i, receipt := range group.Tickets {
+			assert.Equal(t, group.BookingReference, receipt.BookingReference, "one booking reference")
+			assert.Equal(t, req.Passengers[i].User.Email, receipt.User.Email, "tickets follow request order")
+			assert.Equal(t, group.Tickets[0].Seat.Section, receipt.Seat.Section, "group seated in one section")
+			assert.Equal(t, group.Tickets[0].Seat.Seat+int32(i), receipt.Seat.Seat, "group seated side by side")
+			assert.Equal(t, group.Payment.TransactionId, receipt.Payment.TransactionId, "one payment")
+			assert.Equal(t, int64(1), receipt.Version, "each ticket has its own version")
+			total += receipt.Fare.TotalCents
+		}
+		assert.Equal(t, total, group.TotalCents)
+		assert.Equal(t, total, group.Payment.AmountCents)
+
+		tickets, err := server.store.TicketsByReference(group.BookingReference)
+		require.NoError(t, err, "error listing booking")
+		assert.Len(t, tickets, 4)
+
+		// Each member can still be managed on their own.
+		cancellation, err := server.CancelTicket(context.Background(), &pb.CancelTicketRequest{
+			BookingReference: group.BookingReference,
+			Email:            "member3@example.com",
+		})
+		require.NoError(t, err, "error cancelling one group member")
+		assert.Equal(t, group.Tickets[3].Fare.TotalCents, cancellation.RefundCents)
+	})
+}
+
+func TestPurchaseGroupTicketIsAllOrNothing(t *testing.T) {
+	server := newSingleSeatServer()
+	_, err := server.PurchaseGroupTicket(context.Background(), groupRequest(2))
+	assertCode(t, err, codes.ResourceExhausted, "two passengers cannot share one seat")
+
+	tickets, err := server.store.TicketsByUser("member0@example.com")
+	require.NoError(t, err)
+	assert.Empty(t, tickets, "no member may be booked when the group does not fit")
+}
+
+func TestPurchaseGroupTicketDeclined(t *testing.T) {
+	gateway := NewFakeGateway(FakeDecline)
+	server := NewServer(WithPaymentProvider(gateway, defaultPaymentTimeout))
+	_, err := server.PurchaseGroupTicket(context.Background(), groupRequest(3))
+	assertCode(t, err, codes.FailedPrecondition)
+
+	for i := 0; i < 3; i++ {
+		tickets, err := server.store.TicketsByUser(fmt.Sprintf("member%d@example.com", i))
+		require.NoError(t, err)
+		assert.Empty(t, tickets, "declined group must not be booked")
+	}
+}
+
+func TestSlowGroupPaymentKeepsSeatsWithoutBlocking(t *testing.T) {
+	gateway := &blockingGateway{NewFakeGateway(FakeApprove), make(chan struct{}), make(chan struct{})}
+	server := NewServer(WithPaymentProvider(gateway, time.Minute))
+
+	req := groupRequest(2)
+	req.PaymentToken = "block"
+	purchased := make(chan *pb.GroupReceipt)
+	go func() {
+		group, err := server.PurchaseGroupTicket(context.Background(), req)
+		assert.NoError(t, err, "error purchasing group ticket")
+		purchased <- group
+	}()
+	<-gateway.blocked
+
+	finished := make(chan *pb.TicketReceipt)
+	go func() {
+		_, err := server.GetSeatMap(context.Background(), &pb.SeatMapRequest{})
+		assert.NoError(t, err, "error reading seat map")
+		receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("single@example.com"))
+		assert.NoError(t, err, "error purchasing ticket")
+		finished <- receipt
+	}()
+	var single *pb.TicketReceipt
+	select {
+	case single = <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("bookings waited for the group's payment")
+	}
+
+	close(gateway.release)
+	group := <-purchased
+	require.NotNil(t, group)
+	require.NotNil(t, single)
+	for _, receipt := range group.Tickets {
+		assert.NotEqual(t, receipt.Seat.Seat, single.Seat.Seat, "the group's seats should stay reserved during its payment")
+	}
+}
+
+func TestValidateGroupPassengers(t *testing.T) {
+	server := NewServer()
+	req := groupRequest(3)
+	req.Passengers[1].User.Email = req.Passengers[0].User.Email
+	req.Passengers[2].User = nil
+	err := server.validate(context.Background(), req)
+	assertCode(t, err, codes.InvalidArgument)
+	assert.Equal(t, []string{"passengers[1].user.email", "passengers[2].user"}, violatedFields(err))
+
+	assertCode(t, server.validate(context.Background(), &pb.PurchaseGroupTicketRequest{From: "London", To: "France"}), codes.InvalidArgument)
+	assert.NoError(t, server.validate(context.Background(), groupRequest(2)))
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultHoldTTL is how long a seat stays reserved while the customer pays.
+const defaultHoldTTL = 10 * time.Minute
+
+// seatHold is a seat reserved for a pending purchase. Holds live only in
+// memory: they are short-lived and a restart simply releases them.
+type seatHold struct {
+	token   string
+	request *pb.PurchaseTicketRequest // nil for group reservations
+	seat    *allocatedSeat
+	journey leg
+	expires time.Time
+	// waitlistEntry is set when the hold was offered to a waitlisted customer.
+	waitlistEntry string
+	// charging is set while the hold's purchase is paid for with the
+	// departure unlocked. The hold then neither expires nor can be
+	// confirmed again.
+	charging bool
+	// reservation marks a hold placed by a purchase just for the length of
+	// its charge. It blocks other bookings but is not shown as held, since
+	// watchers hear of the seat once it is sold.
+	reservation bool
+}
+
+func (h *seatHold) proto() *pb.SeatHold {
+	return &pb.SeatHold{
+		Token:       h.token,
+		DepartureId: h.seat.departureID,
+		Seat:        proto.Clone(h.seat.seat).(*pb.SeatAllocation),
+		ExpiresAt:   timestamppb.New(h.expires),
+		Fare:        proto.Clone(h.seat.fare).(*pb.FareBreakdown),
+	}
+}
+
+// WithHoldTTL sets how long HoldSeat reserves a seat.
+func WithHoldTTL(ttl time.Duration) Option {
+	return func(s *server) {
+		s.holdTTL = ttl
+	}
+}
+
+// live reports whether hold still keeps its seat: it has not expired, or
+// its purchase is being charged.
+func (h *seatHold) live(now time.Time) bool {
+	return h.charging || now.Before(h.expires)
+}
+
+// activeHold returns the live hold for token on a departure, or nil.
+func (s *server) activeHold(departureID, token string) *seatHold {
+	hold, ok := s.state(departureID).holds[token]
+	if !ok || !hold.live(s.now()) {
+		return nil
+	}
+	return hold
+}
+
+// heldOccupancy returns the legs held on each seat of a departure's section.
+// Expired holds that the reaper has not collected yet do not count.
+// Reservations count only with reservations set: they block allocation but
+// are not shown in seat maps or availability events.
+func (s *server) heldOccupancy(departureID, section string, reservations bool) seatOccupancy {
+	occupancy := make(seatOccupancy)
+	now := s.now()
+	for _, hold := range s.state(departureID).holds {
+		if hold.seat.seat.Section != section || !hold.live(now) || (hold.reservation && !reservations) {
+			continue
+		}
+		occupancy[hold.seat.seat.Seat] = append(occupancy[hold.seat.seat.Seat], hold.journey)
+	}
+	return occupancy
+}
+
+// releaseHold drops a hold and tells watchers its seat is free again.
+func (s *server) releaseHold(hold *seatHold, reason string) {
+	delete(s.state(hold.seat.departureID).holds, hold.token)
+	s.index(s.states.holds, hold.token, "")
+	if reason != "" {
+		s.seatChanged(hold.seat.departureID, hold.seat.seat, reason)
+	}
+}
+
+func (s *server) HoldSeat(ctx context.Context, req *pb.PurchaseTicketRequest) (*pb.SeatHold, error) {
+	if err := authorizeUser(ctx, req.User.GetEmail(), true); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeCompanion(ctx, req.Preferences); err != nil {
+		return nil, err
+	}
+
+	defer s.lockDepartures(req.DepartureId)()
+
+	hold, err := s.placeHold(req)
+	if err != nil {
+		return nil, err
+	}
+	return hold.proto(), nil
+}
+
+// placeHold allocates a seat for req, reserves it for the hold TTL and
+// announces it as held.
+func (s *server) placeHold(req *pb.PurchaseTicketRequest) (*seatHold, error) {
+	hold, err := s.reserveSeat(req)
+	if err != nil {
+		return nil, err
+	}
+	s.index(s.states.holds, hold.token, hold.seat.departureID)
+	s.seatChanged(hold.seat.departureID, hold.seat.seat, "held")
+	return hold, nil
+}
+
+// reserveSeat allocates a seat for req and keeps it from other bookings
+// until the returned hold is released or expires.
+func (s *server) reserveSeat(req *pb.PurchaseTicketRequest) (*seatHold, error) {
+	seat, err := s.allocate(req)
+	if err != nil {
+		return nil, err
+	}
+	departure, err := s.departure(seat.departureID)
+	if err != nil {
+		return nil, err
+	}
+	journey, err := resolveLeg(departure, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	return s.reserve(proto.Clone(req).(*pb.PurchaseTicketRequest), seat, journey), nil
+}
+
+// reserve records a hold on seat for journey.
+func (s *server) reserve(req *pb.PurchaseTicketRequest, seat *allocatedSeat, journey leg) *seatHold {
+	hold := &seatHold{
+		token:   "HOLD-" + randomString("0123456789ABCDEF", 20),
+		request: req,
+		seat:    seat,
+		journey: journey,
+		expires: s.now().Add(s.holdTTL),
+	}
+	s.state(seat.departureID).holds[hold.token] = hold
+	return hold
+}
+
+func (s *server) ConfirmHold(ctx context.Context, req *pb.HoldRequest) (*pb.TicketReceipt, error) {
+	hold, err := s.claimHold(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	paymentToken := req.PaymentToken
+	if paymentToken == "" {
+		paymentToken = hold.request.PaymentToken
+	}
+	receipt, err := s.purchaseHold(ctx, hold, paymentToken)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Ticket purchased: %+v", receipt)
+	return receipt, nil
+}
+
+// claimHold marks the hold with token as being charged, so it cannot
+// expire or be confirmed twice while its payment runs.
+func (s *server) claimHold(ctx context.Context, token string) (*seatHold, error) {
+	departureID, ok := s.holdDeparture(token)
+	var hold *seatHold
+	if ok {
+		defer s.lockDepartures(departureID)()
+		hold = s.activeHold(departureID, token)
+	}
+	if hold == nil {
+		return nil, trainerr.NotFound(trainerr.ResourceHold, token, "hold %s not found or expired", token)
+	}
+	if err := authorizeUser(ctx, hold.request.User.GetEmail(), true); err != nil {
+		return nil, err
+	}
+	if hold.charging {
+		return nil, trainerr.FailedPrecondition("HOLD_CONFIRMING", token, "hold %s is already being confirmed", token)
+	}
+	if _, err := s.bookableDeparture(departureID); err != nil {
+		return nil, err
+	}
+	hold.charging = true
+	return hold, nil
+}
+
+// purchaseHold pays for the seat of a hold being charged and issues its
+// ticket. The payment can take up to the payment timeout, so it runs with
+// the departure unlocked while the hold keeps the seat; the caller must not
+// hold the departure's lock. A customer's hold survives a failed payment so
+// they can try another card, while a reservation is released.
+func (s *server) purchaseHold(ctx context.Context, hold *seatHold, paymentToken string) (*pb.TicketReceipt, error) {
+	receipt, err := s.newReceipt(hold.request, hold.seat)
+	if err == nil {
+		receipt.Payment, err = s.chargeTicket(ctx, receipt, paymentToken)
+	}
+
+	defer s.lockDepartures(hold.seat.departureID)()
+	hold.charging = false
+	if err == nil {
+		err = s.issueTicket(ctx, receipt)
+	}
+	if err != nil {
+		if hold.reservation {
+			s.releaseHold(hold, "")
+		}
+		return nil, err
+	}
+	s.releaseHold(hold, "")
+	if hold.waitlistEntry != "" {
+		s.removeWaitlistEntry(hold.seat.departureID, hold.waitlistEntry)
+	}
+	s.seatChanged(receipt.DepartureId, receipt.Seat, "purchased")
+	return receipt, nil
+}
+
+// reapExpiredHolds releases every hold past its expiry and returns how many
+// were released. Waitlisted customers who let an offer lapse leave the
+// queue, and the freed seats are offered to whoever is next. Departures are
+// reaped one at a time, so bookings elsewhere carry on meanwhile.
+func (s *server) reapExpiredHolds() int {
+	s.states.mu.Lock()
+	departureIDs := make([]string, 0, len(s.states.byID))
+	for id := range s.states.byID {
+		departureIDs = append(departureIDs, id)
+	}
+	s.states.mu.Unlock()
+
+	released := 0
+	for _, departureID := range departureIDs {
+		released += s.reapDeparture(departureID)
+	}
+	return released
+}
+
+// reapDeparture releases the expired holds of one departure.
+func (s *server) reapDeparture(departureID string) int {
+	defer s.lockDepartures(departureID)()
+
+	released := 0
+	now := s.now()
+	for _, hold := range s.state(departureID).holds {
+		if hold.live(now) {
+			continue
+		}
+		s.releaseHold(hold, "hold_expired")
+		released++
+		if entry := s.waitlistEntry(departureID, hold.waitlistEntry); entry != nil {
+			s.notifier.Notify(entry.user, fmt.Sprintf("Your waitlist offer for departure %s has expired", entry.departureID))
+			s.removeWaitlistEntry(departureID, entry.id)
+		}
+	}
+	if released > 0 {
+		s.promoteWaitlist(departureID)
+	}
+	return released
+}
+
+// runHoldReaper releases expired holds every interval until ctx is done.
+func (s *server) runHoldReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := s.reapExpiredHolds(); n > 0 {
+				log.Printf("Released %d expired seat hold(s)", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+// fakeClock is a manually advanced time source for WithClock.
+type fakeClock struct {
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func purchaseRequest(email string) *pb.PurchaseTicketRequest {
+	return &pb.PurchaseTicketRequest{
+		User: &pb.User{FirstName: "Test", LastName: "User", Email: email},
+		From: "London",
+		To:   "France",
+	}
+}
+
+func TestHoldSeatBlocksAllocation(t *testing.T) {
+	clock := newFakeClock()
+	server := NewServer(WithClock(clock.Now), WithHoldTTL(time.Minute))
+
+	hold, err := server.HoldSeat(context.Background(), purchaseRequest("a@example.com"))
+	require.NoError(t, err, "error holding seat")
+	assert.NotEmpty(t, hold.Token)
+	assert.Equal(t, int32(1), hold.Seat.Seat, "expected seat 1 to be held")
+	assert.Equal(t, clock.now.Add(time.Minute), hold.ExpiresAt.AsTime())
+
+	receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("b@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	assert.Equal(t, int32(2), receipt.Seat.Seat, "held seat should be skipped")
+
+	seatMap, err := server.GetSeatMap(context.Background(), &pb.SeatMapRequest{})
+	require.NoError(t, err, "error fetching seat map")
+	assert.Equal(t, pb.SeatState_SEAT_STATE_HELD, seatMap.Sections[0].Seats[0].State)
+	assert.Equal(t, pb.SeatState_SEAT_STATE_SOLD, seatMap.Sections[0].Seats[1].State)
+
+	confirmed, err := server.ConfirmHold(context.Background(), &pb.HoldRequest{Token: hold.Token})
+	require.NoError(t, err, "error confirming hold")
+	assert.Equal(t, hold.Seat.Seat, confirmed.Seat.Seat, "confirmed ticket should keep the held seat")
+	assert.Equal(t, hold.Fare.TotalCents, confirmed.Fare.TotalCents, "confirmed ticket should keep the held fare")
+	assert.Equal(t, "a@example.com", confirmed.User.Email)
+
+	_, err = server.ConfirmHold(context.Background(), &pb.HoldRequest{Token: hold.Token})
+	assertCode(t, err, codes.NotFound, "a hold can only be confirmed once")
+}
+
+func TestExpiredHoldIsReleased(t *testing.T) {
+	clock := newFakeClock()
+	server := NewServer(WithClock(clock.Now), WithHoldTTL(time.Minute))
+
+	hold, err := server.HoldSeat(context.Background(), purchaseRequest("a@example.com"))
+	require.NoError(t, err, "error holding seat")
+
+	clock.Advance(time.Minute)
+	_, err = server.ConfirmHold(context.Background(), &pb.HoldRequest{Token: hold.Token})
+	assertCode(t, err, codes.NotFound, "expired hold should not be confirmable")
+
+	receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("b@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	assert.Equal(t, hold.Seat.Seat, receipt.Seat.Seat, "expired hold should free its seat")
+
+	assert.Equal(t, 1, server.reapExpiredHolds(), "reaper should release the expired hold")
+	assert.Empty(t, server.state(defaultDepartureID).holds)
+}
+
+func TestModifyUserSeatRespectsHolds(t *testing.T) {
+	clock := newFakeClock()
+	server := NewServer(WithClock(clock.Now))
+
+	receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("a@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	hold, err := server.HoldSeat(context.Background(), purchaseRequest("b@example.com"))
+	require.NoError(t, err, "error holding seat")
+
+	_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+		BookingReference: receipt.BookingReference,
+		NewSection:       hold.Seat.Section,
+		NewSeat:          hold.Seat.Seat,
+	})
+	assertCode(t, err, codes.AlreadyExists, "moving onto a held seat should be rejected")
+}
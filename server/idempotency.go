@@ -0,0 +1,282 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"test_train/auth"
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// idempotencyKeyHeader is the metadata key carrying a call's
+	// idempotency key; trainclient sends one with every change.
+	idempotencyKeyHeader = "idempotency-key"
+	// idempotencyKeyField is the request field that may carry it instead.
+	idempotencyKeyField = "idempotency_key"
+	// replayHeader is set on responses replayed for a repeated key.
+	replayHeader = "idempotent-replay"
+
+	maxIdempotencyKeyLength  = 128
+	defaultIdempotencyWindow = 24 * time.Hour
+	defaultIdempotencyLimit  = 100000
+	idempotencySweepInterval = time.Minute
+)
+
+// idempotentMethods are the RPCs that change bookings and so honour
+// idempotency keys. Reads need none: repeating them is harmless.
+var idempotentMethods = map[string]bool{
+	pb.TrainService_PurchaseTicket_FullMethodName:      true,
+	pb.TrainService_PurchaseGroupTicket_FullMethodName: true,
+	pb.TrainService_RemoveUser_FullMethodName:          true,
+	pb.TrainService_ModifyUserSeat_FullMethodName:      true,
+	pb.TrainService_SwapSeats_FullMethodName:           true,
+	pb.TrainService_HoldSeat_FullMethodName:            true,
+	pb.TrainService_ConfirmHold_FullMethodName:         true,
+	pb.TrainService_CancelTicket_FullMethodName:        true,
+	pb.TrainService_JoinWaitlist_FullMethodName:        true,
+	pb.TrainService_LeaveWaitlist_FullMethodName:       true,
+	pb.TrainService_CreateDeparture_FullMethodName:     true,
+	pb.TrainService_CancelDeparture_FullMethodName:     true,
+}
+
+// WithIdempotencyWindow sets how long the response to a change is kept for
+// replay to calls repeating its idempotency key. Zero ignores keys.
+func WithIdempotencyWindow(window time.Duration) Option {
+	return func(s *server) {
+		s.idempotency.window = window
+	}
+}
+
+// WithIdempotencyLimit caps how many responses are kept for replay. Past
+// it the least recently used are forgotten first, even within the window.
+func WithIdempotencyLimit(limit int) Option {
+	return func(s *server) {
+		s.idempotency.limit = limit
+	}
+}
+
+// idempotencyCache remembers the first successful response to each
+// idempotency key, up to limit responses. Keys are scoped to the
+// authenticated caller, or without authentication to the caller's address,
+// so one caller cannot replay another's response. Failed calls are
+// forgotten: they changed nothing, so repeating them runs them again.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	window  time.Duration
+	limit   int
+	calls   map[idempotencyScope]*list.Element // of *idempotentCall
+	recent  *list.List                         // most recently used first
+	sweptAt time.Time
+}
+
+type idempotencyScope struct {
+	caller string
+	key    string
+}
+
+// idempotentCall is the first call made with a key. done is closed when
+// it finishes, after response is set if it succeeded.
+type idempotentCall struct {
+	scope    idempotencyScope
+	method   string
+	digest   [sha256.Size]byte
+	done     chan struct{}
+	response proto.Message
+	expires  time.Time
+}
+
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{
+		window: defaultIdempotencyWindow,
+		limit:  defaultIdempotencyLimit,
+		calls:  make(map[idempotencyScope]*list.Element),
+		recent: list.New(),
+	}
+}
+
+// begin returns the call already made with scope's key, or registers a new
+// one and reports that the caller should make it.
+func (c *idempotencyCache) begin(scope idempotencyScope, method string, digest [sha256.Size]byte, now time.Time) (*idempotentCall, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.sweptAt) >= idempotencySweepInterval {
+		for _, element := range c.calls {
+			if call := element.Value.(*idempotentCall); call.response != nil && !now.Before(call.expires) {
+				c.remove(element)
+			}
+		}
+		c.sweptAt = now
+	}
+	if element, ok := c.calls[scope]; ok {
+		if call := element.Value.(*idempotentCall); call.response == nil || now.Before(call.expires) {
+			c.recent.MoveToFront(element)
+			return call, false
+		}
+		c.remove(element)
+	}
+	call := &idempotentCall{scope: scope, method: method, digest: digest, done: make(chan struct{})}
+	c.calls[scope] = c.recent.PushFront(call)
+	c.evict()
+	return call, true
+}
+
+// evict forgets the least recently used responses beyond the limit. Calls
+// still running are kept, since duplicates are waiting on them.
+func (c *idempotencyCache) evict() {
+	for element := c.recent.Back(); element != nil && len(c.calls) > c.limit; {
+		prev := element.Prev()
+		if element.Value.(*idempotentCall).response != nil {
+			c.remove(element)
+		}
+		element = prev
+	}
+}
+
+func (c *idempotencyCache) remove(element *list.Element) {
+	delete(c.calls, element.Value.(*idempotentCall).scope)
+	c.recent.Remove(element)
+}
+
+// finish records the outcome of call, keeping a successful response for
+// the window and forgetting a failure.
+func (c *idempotencyCache) finish(scope idempotencyScope, call *idempotentCall, response any, err error, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, current := c.calls[scope]
+	current = current && element.Value.(*idempotentCall) == call
+	if err == nil {
+		call.response = response.(proto.Message)
+		call.expires = now.Add(c.window)
+		if current {
+			c.evict()
+		}
+	} else if current {
+		c.remove(element)
+	}
+	close(call.done)
+}
+
+// validIdempotencyKey checks a key from either the header or the request.
+func validIdempotencyKey(key string) string {
+	if len(key) > maxIdempotencyKeyLength {
+		return fmt.Sprintf("must be at most %d characters", maxIdempotencyKeyLength)
+	}
+	for _, r := range key {
+		if r < ' ' || r > '~' {
+			return "must be printable ASCII"
+		}
+	}
+	return ""
+}
+
+// callIdempotencyKey returns the key of a call, from the request field or
+// the metadata header; the two must agree if both are given. The field is
+// checked by validation.
+func callIdempotencyKey(ctx context.Context, req proto.Message) (string, error) {
+	var key string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(idempotencyKeyHeader); len(values) > 0 {
+			key = values[0]
+			if problem := validIdempotencyKey(key); problem != "" {
+				return "", trainerr.InvalidArgument(trainerr.FieldViolation(idempotencyKeyHeader, "%s", problem))
+			}
+		}
+	}
+	msg := req.ProtoReflect()
+	if fd := msg.Descriptor().Fields().ByName(idempotencyKeyField); fd != nil {
+		if field := msg.Get(fd).String(); field != "" {
+			if key != "" && key != field {
+				return "", trainerr.InvalidField(idempotencyKeyField, "differs from the %s header", idempotencyKeyHeader)
+			}
+			key = field
+		}
+	}
+	return key, nil
+}
+
+// idempotencyCaller names whose keys a call's key is scoped to: the
+// authenticated caller or, without authentication, the host it calls from.
+func idempotencyCaller(ctx context.Context) string {
+	if claims, ok := auth.FromContext(ctx); ok {
+		return string(claims.Role) + ":" + claims.Subject
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "peer:" + addr
+}
+
+// requestDigest fingerprints a request apart from its key, to tell a retry
+// from a different request reusing the key.
+func requestDigest(req proto.Message) [sha256.Size]byte {
+	msg := proto.Clone(req).ProtoReflect()
+	if fd := msg.Descriptor().Fields().ByName(idempotencyKeyField); fd != nil {
+		msg.Clear(fd)
+	}
+	data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(msg.Interface())
+	return sha256.Sum256(data)
+}
+
+// idempotencyInterceptor replays the response to a change made earlier
+// with the same idempotency key instead of making it again. A repeat that
+// arrives while the first call is still running waits for its outcome.
+func (s *server) idempotencyInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		msg, ok := req.(proto.Message)
+		if !ok || !idempotentMethods[info.FullMethod] || s.idempotency.window <= 0 {
+			return handler(ctx, req)
+		}
+		key, err := callIdempotencyKey(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			return handler(ctx, req)
+		}
+
+		scope := idempotencyScope{caller: idempotencyCaller(ctx), key: key}
+		digest := requestDigest(msg)
+		for {
+			call, first := s.idempotency.begin(scope, info.FullMethod, digest, s.now())
+			if first {
+				resp, err := handler(ctx, req)
+				s.idempotency.finish(scope, call, resp, err, s.now())
+				return resp, err
+			}
+			if call.method != info.FullMethod || call.digest != digest {
+				return nil, trainerr.InvalidField(idempotencyKeyField, "key %q was already used for a different request", key)
+			}
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if call.response != nil {
+				log.Printf("Replaying %s for idempotency key %q", info.FullMethod, key)
+				grpc.SetHeader(ctx, metadata.Pairs(replayHeader, "true"))
+				return proto.Clone(call.response), nil
+			}
+			// The first call failed and was forgotten; make this one
+		}
+	}
+}
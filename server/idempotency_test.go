@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"test_train/auth"
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), idempotencyKeyHeader, key)
+}
+
+func TestIdempotentPurchaseIsReplayed(t *testing.T) {
+	client := dialTestServer(t, NewServer())
+
+	first, err := client.PurchaseTicket(withKey("order-1"), purchaseRequest("john.doe@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+
+	var header metadata.MD
+	again, err := client.PurchaseTicket(withKey("order-1"), purchaseRequest("john.doe@example.com"), grpc.Header(&header))
+	require.NoError(t, err, "error repeating purchase")
+	assert.True(t, proto.Equal(first, again), "the retry should get the first receipt")
+	assert.Equal(t, []string{"true"}, header.Get(replayHeader))
+
+	tickets, err := client.ListTicketsForUser(context.Background(), &pb.UserRequest{Email: "john.doe@example.com"})
+	require.NoError(t, err, "error listing tickets")
+	assert.Len(t, tickets.Tickets, 1, "the retry should not book again")
+}
+
+func TestIdempotencyKeyField(t *testing.T) {
+	client := dialTestServer(t, NewServer())
+
+	req := purchaseRequest("john.doe@example.com")
+	req.IdempotencyKey = "order-1"
+	first, err := client.PurchaseTicket(context.Background(), req)
+	require.NoError(t, err, "error purchasing ticket")
+
+	// The header and the field name the same key
+	req.IdempotencyKey = ""
+	again, err := client.PurchaseTicket(withKey("order-1"), req)
+	require.NoError(t, err, "error repeating purchase")
+	assert.Equal(t, first.BookingReference, again.BookingReference)
+
+	req.IdempotencyKey = "order-2"
+	_, err = client.PurchaseTicket(withKey("order-1"), req)
+	assertCode(t, err, codes.InvalidArgument, "header and field should agree")
+
+	req.IdempotencyKey = strings.Repeat("k", maxIdempotencyKeyLength+1)
+	_, err = client.PurchaseTicket(context.Background(), req)
+	assertCode(t, err, codes.InvalidArgument, "long keys should be rejected")
+	assert.Equal(t, []string{idempotencyKeyField}, violatedFields(err))
+}
+
+func TestIdempotencyKeyReusedForDifferentRequest(t *testing.T) {
+	client := dialTestServer(t, NewServer())
+
+	_, err := client.PurchaseTicket(withKey("order-1"), purchaseRequest("john.doe@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+
+	_, err = client.PurchaseTicket(withKey("order-1"), purchaseRequest("jane.smith@example.com"))
+	assertCode(t, err, codes.InvalidArgument, "a different payload should be rejected")
+	assert.Equal(t, []string{idempotencyKeyField}, violatedFields(err))
+
+	_, err = client.CancelTicket(withKey("order-1"), &pb.CancelTicketRequest{Email: "john.doe@example.com"})
+	assertCode(t, err, codes.InvalidArgument, "a different method should be rejected")
+
+	_, err = client.PurchaseTicket(withKey("order-2"), purchaseRequest("jane.smith@example.com"))
+	assert.NoError(t, err, "error purchasing with a new key")
+}
+
+func TestIdempotencyWindow(t *testing.T) {
+	clock := newFakeClock()
+	client := dialTestServer(t, NewServer(WithClock(clock.Now), WithIdempotencyWindow(time.Hour)))
+
+	first, err := client.PurchaseTicket(withKey("order-1"), purchaseRequest("john.doe@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	_, err = client.CancelTicket(context.Background(), &pb.CancelTicketRequest{BookingReference: first.BookingReference})
+	require.NoError(t, err, "error cancelling ticket")
+
+	clock.Advance(59 * time.Minute)
+	again, err := client.PurchaseTicket(withKey("order-1"), purchaseRequest("john.doe@example.com"))
+	require.NoError(t, err, "error repeating purchase")
+	assert.Equal(t, first.BookingReference, again.BookingReference, "the key should be remembered within the window")
+
+	clock.Advance(2 * time.Minute)
+	later, err := client.PurchaseTicket(withKey("order-1"), purchaseRequest("john.doe@example.com"))
+	require.NoError(t, err, "error purchasing after the window")
+	assert.NotEqual(t, first.BookingReference, later.BookingReference, "an expired key should book again")
+}
+
+func TestFailedCallsAreNotRemembered(t *testing.T) {
+	client := dialTestServer(t, NewServer())
+
+	req := &pb.CancelTicketRequest{Email: "john.doe@example.com"}
+	_, err := client.CancelTicket(withKey("cancel-1"), req)
+	assertCode(t, err, codes.NotFound, "no ticket to cancel yet")
+
+	_, err = client.PurchaseTicket(context.Background(), purchaseRequest("john.doe@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	_, err = client.CancelTicket(withKey("cancel-1"), req)
+	assert.NoError(t, err, "the retry should run again after a failure")
+}
+
+func TestConcurrentDuplicatesBookOnce(t *testing.T) {
+	client := dialTestServer(t, NewServer())
+
+	const callers = 8
+	references := make([]string, callers)
+	var wg sync.WaitGroup
+	for i := range references {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			receipt, err := client.PurchaseTicket(withKey("order-1"), purchaseRequest("john.doe@example.com"))
+			if assert.NoError(t, err, "error purchasing ticket") {
+				references[i] = receipt.BookingReference
+			}
+		}()
+	}
+	wg.Wait()
+	for _, reference := range references {
+		assert.Equal(t, references[0], reference, "every duplicate should get the first receipt")
+	}
+}
+
+func TestIdempotencyKeysAreScopedToCaller(t *testing.T) {
+	keys := testKeySet()
+	client := dialTestServer(t, NewServer(WithKeySet(keys)))
+	john := bearer(t, keys, auth.RolePassenger, "john", "john.doe@example.com")
+	jane := bearer(t, keys, auth.RolePassenger, "jane", "jane.smith@example.com")
+
+	johns, err := client.PurchaseTicket(withKey("order-1"), purchaseRequest("john.doe@example.com"), john)
+	require.NoError(t, err, "error purchasing ticket")
+	janes, err := client.PurchaseTicket(withKey("order-1"), purchaseRequest("jane.smith@example.com"), jane)
+	require.NoError(t, err, "another caller's key should not clash")
+	assert.NotEqual(t, johns.BookingReference, janes.BookingReference)
+}
+
+func TestAnonymousIdempotencyKeysAreScopedToHost(t *testing.T) {
+	server := NewServer()
+	intercept := server.idempotencyInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: pb.TrainService_PurchaseTicket_FullMethodName}
+	purchase := func(ctx context.Context, req any) (any, error) {
+		return server.PurchaseTicket(ctx, req.(*pb.PurchaseTicketRequest))
+	}
+	buyFrom := func(host string, port int) string {
+		t.Helper()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotencyKeyHeader, "order-1"))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(host), Port: port}})
+		resp, err := intercept(ctx, purchaseRequest("john.doe@example.com"), info, purchase)
+		require.NoError(t, err, "error purchasing ticket")
+		return resp.(*pb.TicketReceipt).BookingReference
+	}
+
+	first := buyFrom("192.0.2.1", 1000)
+	assert.Equal(t, first, buyFrom("192.0.2.1", 2000), "a retry on a new connection should be replayed")
+	assert.NotEqual(t, first, buyFrom("192.0.2.2", 1000), "another host's key should not clash")
+}
+
+func TestIdempotencyCacheIsBounded(t *testing.T) {
+	server := NewServer(WithIdempotencyLimit(2))
+	client := dialTestServer(t, server)
+	buy := func(key string) string {
+		t.Helper()
+		receipt, err := client.PurchaseTicket(withKey(key), purchaseRequest("john.doe@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+		return receipt.BookingReference
+	}
+
+	first := buy("order-1")
+	second := buy("order-2")
+	assert.Equal(t, first, buy("order-1"), "order-1 should be replayed")
+	buy("order-3")
+	assert.Len(t, server.idempotency.calls, 2)
+	assert.Equal(t, first, buy("order-1"), "the recently used key should be kept")
+	assert.NotEqual(t, second, buy("order-2"), "the least recently used key should be forgotten")
+}
+
+func TestGatewayIdempotencyKey(t *testing.T) {
+	url := serveGateway(t, NewServer())
+
+	code, first := call(t, "POST", url+"/v1/tickets", johnJSON, "Idempotency-Key", "order-1")
+	require.Equal(t, 200, code, "error purchasing ticket: %v", first)
+	code, again := call(t, "POST", url+"/v1/tickets", johnJSON, "Idempotency-Key", "order-1")
+	require.Equal(t, 200, code, "error repeating purchase: %v", again)
+	assert.Equal(t, first["bookingReference"], again["bookingReference"])
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+)
+
+// seatAttributeNames lists the attributes a layout file may assign to seats.
+var seatAttributeNames = map[string]bool{
+	"window":         true,
+	"aisle":          true,
+	"middle":         true,
+	"table":          true,
+	"accessible":     true,
+	"forward_facing": true,
+	"rear_facing":    true,
+	"near_toilet":    true, // close to the accessible toilet
+}
+
+// layoutFile is the on-disk format of the -layouts file.
+type layoutFile struct {
+	// Default names the layout used for departures that do not pick one.
+	Default string         `json:"default"`
+	Layouts []layoutConfig `json:"layouts"`
+}
+
+type layoutConfig struct {
+	Name     string          `json:"name"`
+	Sections []sectionConfig `json:"sections"`
+}
+
+type sectionConfig struct {
+	Name  string `json:"name"`
+	Class string `json:"class"`
+	Seats int32  `json:"seats"`
+	Quiet bool   `json:"quiet"`
+	// RowPattern assigns attributes by position in the row: seat n gets
+	// RowPattern[(n-1) % len(RowPattern)]. Its length is the row width.
+	RowPattern []string `json:"row_pattern"`
+	// SeatAttributes adds an attribute to individual seats.
+	SeatAttributes map[string][]int32 `json:"seat_attributes"`
+}
+
+// Layouts holds the named section layouts departures can be created from.
+type Layouts struct {
+	Default string
+	ByName  map[string][]*pb.SectionLayout
+}
+
+// defaultLayouts is used when the server is started without a layout file.
+func defaultLayouts() Layouts {
+	return Layouts{
+		Default: "standard",
+		ByName:  map[string][]*pb.SectionLayout{"standard": defaultLayout()},
+	}
+}
+
+// LoadLayouts reads and validates a layout file.
+func LoadLayouts(path string) (Layouts, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Layouts{}, err
+	}
+	var file layoutFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Layouts{}, fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	layouts := Layouts{Default: file.Default, ByName: make(map[string][]*pb.SectionLayout)}
+	for _, layout := range file.Layouts {
+		if _, ok := layouts.ByName[layout.Name]; ok || layout.Name == "" {
+			return Layouts{}, fmt.Errorf("layout %q must have a unique name", layout.Name)
+		}
+		var sections []*pb.SectionLayout
+		for _, section := range layout.Sections {
+			resolved, err := section.resolve()
+			if err != nil {
+				return Layouts{}, fmt.Errorf("layout %s: %w", layout.Name, err)
+			}
+			sections = append(sections, resolved)
+		}
+		if err := validateLayout(sections); err != nil {
+			return Layouts{}, fmt.Errorf("layout %s: %w", layout.Name, err)
+		}
+		layouts.ByName[layout.Name] = sections
+	}
+	if _, ok := layouts.ByName[layouts.Default]; !ok {
+		return Layouts{}, fmt.Errorf("default layout %q is not defined", layouts.Default)
+	}
+	return layouts, nil
+}
+
+// resolve expands the row pattern and per-seat attributes of a section.
+func (c sectionConfig) resolve() (*pb.SectionLayout, error) {
+	attributes := make(map[int32][]string)
+	if len(c.RowPattern) > 0 {
+		for seat := int32(1); seat <= c.Seats; seat++ {
+			attr := c.RowPattern[(seat-1)%int32(len(c.RowPattern))]
+			if attr != "" {
+				attributes[seat] = append(attributes[seat], attr)
+			}
+		}
+	}
+	for attr, seats := range c.SeatAttributes {
+		for _, seat := range seats {
+			if seat < 1 || seat > c.Seats {
+				return nil, fmt.Errorf("section %s: %s seat %d is out of range", c.Name, attr, seat)
+			}
+			attributes[seat] = append(attributes[seat], attr)
+		}
+	}
+
+	class := c.Class
+	if class == "" {
+		class = "standard"
+	}
+	section := &pb.SectionLayout{
+		Name:        c.Name,
+		Seats:       c.Seats,
+		SeatClass:   class,
+		Quiet:       c.Quiet,
+		SeatsPerRow: int32(len(c.RowPattern)),
+	}
+	for seat, attrs := range attributes {
+		sort.Strings(attrs)
+		section.SeatAttributes = append(section.SeatAttributes, &pb.SeatAttributes{Seat: seat, Attributes: attrs})
+	}
+	sort.Slice(section.SeatAttributes, func(i, j int) bool {
+		return section.SeatAttributes[i].Seat < section.SeatAttributes[j].Seat
+	})
+	return section, nil
+}
+
+// validateLayout checks that a layout has uniquely named, valid sections.
+// Layouts from the -layouts file and sections given to CreateDeparture both
+// go through it.
+func validateLayout(sections []*pb.SectionLayout) error {
+	if len(sections) == 0 {
+		return fmt.Errorf("at least one section is required")
+	}
+	seen := make(map[string]bool)
+	for _, section := range sections {
+		if err := validateSection(section); err != nil {
+			return err
+		}
+		if seen[section.Name] {
+			return fmt.Errorf("section %s is defined twice", section.Name)
+		}
+		seen[section.Name] = true
+	}
+	return nil
+}
+
+// validateSection checks one section: a name, at least one seat, a row no
+// wider than the section, and known attributes on seats that exist, each
+// seat listed once.
+func validateSection(section *pb.SectionLayout) error {
+	if section.Name == "" || section.Seats <= 0 {
+		return fmt.Errorf("section %q must have a name and at least one seat", section.Name)
+	}
+	if section.SeatsPerRow < 0 || section.SeatsPerRow > section.Seats {
+		return fmt.Errorf("section %s: seats per row must be between 0 and %d", section.Name, section.Seats)
+	}
+	listed := make(map[int32]bool)
+	for _, seat := range section.SeatAttributes {
+		if seat.Seat < 1 || seat.Seat > section.Seats {
+			return fmt.Errorf("section %s: seat %d is out of range", section.Name, seat.Seat)
+		}
+		if listed[seat.Seat] {
+			return fmt.Errorf("section %s: seat %d is listed twice", section.Name, seat.Seat)
+		}
+		listed[seat.Seat] = true
+		for _, attr := range seat.Attributes {
+			if !seatAttributeNames[attr] {
+				return fmt.Errorf("section %s: unknown seat attribute %q", section.Name, attr)
+			}
+		}
+	}
+	return nil
+}
+
+// findSection returns the named section of a departure, or nil.
+func findSection(departure *pb.Departure, name string) *pb.SectionLayout {
+	for _, section := range departure.Sections {
+		if section.Name == name {
+			return section
+		}
+	}
+	return nil
+}
+
+// validateSeat checks that section and seat exist on departure, reporting
+// violations against the named request fields.
+func validateSeat(departure *pb.Departure, sectionField, section, seatField string, seat int32) error {
+	layout := findSection(departure, section)
+	if layout == nil {
+		return trainerr.InvalidField(sectionField, "section %s does not exist on departure %s", section, departure.Id)
+	}
+	if seat < 1 || seat > layout.Seats {
+		return trainerr.InvalidField(seatField, "seat %d is out of range for section %s (1-%d)", seat, section, layout.Seats)
+	}
+	return nil
+}
+
+func (s *server) GetSeatMap(ctx context.Context, req *pb.SeatMapRequest) (*pb.SeatMap, error) {
+	defer s.readLockDeparture(req.DepartureId)()
+
+	departure, err := s.departure(req.DepartureId)
+	if err != nil {
+		return nil, err
+	}
+	journey := wholeRoute(departure)
+	if req.From != "" || req.To != "" {
+		if journey, err = resolveLeg(departure, req.From, req.To); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.seatMap(departure, journey)
+}
+
+// seatMap reports the layout of departure with each seat's state on journey.
+func (s *server) seatMap(departure *pb.Departure, journey leg) (*pb.SeatMap, error) {
+	seatMap := &pb.SeatMap{DepartureId: departure.Id}
+	for _, section := range departure.Sections {
+		sold, err := s.soldOccupancy(departure, section.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		held := s.heldOccupancy(departure.Id, section.Name, false)
+		attributes := make(map[int32][]string, len(section.SeatAttributes))
+		for _, attrs := range section.SeatAttributes {
+			attributes[attrs.Seat] = attrs.Attributes
+		}
+
+		sectionMap := &pb.SectionMap{Name: section.Name, SeatClass: section.SeatClass}
+		for seat := int32(1); seat <= section.Seats; seat++ {
+			status := &pb.SeatStatus{Seat: seat, Attributes: attributes[seat], State: seatState(sold, held, seat, journey)}
+			sectionMap.Seats = append(sectionMap.Seats, status)
+		}
+		seatMap.Sections = append(seatMap.Sections, sectionMap)
+	}
+	return seatMap, nil
+}
+
+// seatState classifies seat on journey; a sale outranks a hold.
+func seatState(sold, held seatOccupancy, seat int32, journey leg) pb.SeatState {
+	switch {
+	case !sold.free(seat, journey):
+		return pb.SeatState_SEAT_STATE_SOLD
+	case !held.free(seat, journey):
+		return pb.SeatState_SEAT_STATE_HELD
+	default:
+		return pb.SeatState_SEAT_STATE_FREE
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestLoadLayouts(t *testing.T) {
+	layouts, err := LoadLayouts("layouts.json")
+	require.NoError(t, err, "error loading sample layouts")
+	assert.Equal(t, "standard", layouts.Default)
+
+	standard := layouts.ByName["standard"]
+	require.Len(t, standard, 2, "expected 2 sections")
+	assert.Equal(t, "first", standard[0].SeatClass)
+	assert.Equal(t, int32(1), standard[0].SeatAttributes[0].Seat)
+	assert.Equal(t, []string{"table", "window"}, standard[0].SeatAttributes[0].Attributes)
+	assert.True(t, standard[0].Quiet, "section A is the quiet coach")
+	assert.Equal(t, int32(3), standard[0].SeatsPerRow, "row width comes from the row pattern")
+}
+
+func TestLoadLayoutsRejectsBadConfig(t *testing.T) {
+	for name, config := range map[string]string{
+		"unknown attribute": `{"default":"x","layouts":[{"name":"x","sections":[{"name":"A","seats":2,"row_pattern":["sunroof"]}]}]}`,
+		"seat out of range": `{"default":"x","layouts":[{"name":"x","sections":[{"name":"A","seats":2,"seat_attributes":{"table":[3]}}]}]}`,
+		"missing default":   `{"default":"y","layouts":[{"name":"x","sections":[{"name":"A","seats":2}]}]}`,
+		"duplicate section": `{"default":"x","layouts":[{"name":"x","sections":[{"name":"A","seats":2},{"name":"A","seats":2}]}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "layouts.json")
+			require.NoError(t, os.WriteFile(path, []byte(config), 0o644))
+			_, err := LoadLayouts(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCapacityFollowsLayout(t *testing.T) {
+	server := NewServer(WithLayouts(Layouts{
+		Default: "tiny",
+		ByName: map[string][]*pb.SectionLayout{"tiny": {
+			{Name: "A", Seats: 1, SeatClass: "standard"},
+			{Name: "B", Seats: 1, SeatClass: "standard"},
+		}},
+	}))
+
+	var sections []string
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		receipt, err := server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+			User: &pb.User{FirstName: "Test", LastName: "User", Email: email},
+			From: "London",
+			To:   "France",
+		})
+		require.NoError(t, err, "error purchasing ticket")
+		sections = append(sections, receipt.Seat.Section)
+	}
+	assert.Equal(t, []string{"A", "B"}, sections, "expected one seat in each section")
+
+	_, err := server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+		User: &pb.User{FirstName: "Test", LastName: "User", Email: "c@example.com"},
+		From: "London",
+		To:   "France",
+	})
+	assertCode(t, err, codes.ResourceExhausted, "train should be full once section B is full")
+}
+
+func TestModifyUserSeatValidatesLayout(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		_, err := server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+			User: &pb.User{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"},
+			From: "London",
+			To:   "France",
+		})
+		require.NoError(t, err, "error purchasing ticket")
+
+		_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+			Email:      "john.doe@example.com",
+			NewSection: "Z",
+			NewSeat:    1,
+		})
+		assertCode(t, err, codes.InvalidArgument, "unknown section should be rejected")
+
+		_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+			Email:      "john.doe@example.com",
+			NewSection: "B",
+			NewSeat:    51,
+		})
+		assertCode(t, err, codes.InvalidArgument, "seat beyond the section size should be rejected")
+	})
+}
+
+func TestGetSeatMap(t *testing.T) {
+	layouts, err := LoadLayouts("layouts.json")
+	require.NoError(t, err, "error loading sample layouts")
+	server := NewServer(WithLayouts(layouts))
+
+	_, err = server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+		User: &pb.User{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"},
+		From: "London",
+		To:   "France",
+	})
+	require.NoError(t, err, "error purchasing ticket")
+
+	seatMap, err := server.GetSeatMap(context.Background(), &pb.SeatMapRequest{})
+	require.NoError(t, err, "error fetching seat map")
+	assert.Equal(t, defaultDepartureID, seatMap.DepartureId)
+	require.Len(t, seatMap.Sections, 2, "expected 2 sections")
+
+	first := seatMap.Sections[0]
+	assert.Equal(t, "first", first.SeatClass)
+	assert.Len(t, first.Seats, 48, "expected a status for every seat")
+	assert.Equal(t, pb.SeatState_SEAT_STATE_SOLD, first.Seats[0].State, "seat A1 should be sold")
+	assert.Equal(t, pb.SeatState_SEAT_STATE_FREE, first.Seats[1].State, "seat A2 should be free")
+	assert.Contains(t, first.Seats[46].Attributes, "accessible")
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"test_train/auth"
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// systemActor is recorded for changes the server makes on its own.
+const systemActor = "system"
+
+// actor identifies who is making the request in ctx: the role and subject
+// of an authenticated caller, otherwise the caller's network address.
+func actor(ctx context.Context) string {
+	if claims, ok := auth.FromContext(ctx); ok {
+		return string(claims.Role) + ":" + claims.Subject
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return "peer:" + p.Addr.String()
+	}
+	return systemActor
+}
+
+// record stamps events and appends them to the ledger together.
+//
+// A change is stored before its events are recorded, and if recording
+// fails the change is undone with restoreTickets or unstoreTickets, so the
+// store never holds a change that replaying the ledger would not make.
+// Departures cannot be deleted and a refund cannot be taken back, so new
+// departures and cancellations are recorded first instead; replaying either
+// a second time, after a failed write is retried, changes nothing.
+func (s *server) record(ctx context.Context, events ...*pb.BookingEvent) error {
+	for _, event := range events {
+		event.At = timestamppb.New(s.now())
+		event.Actor = actor(ctx)
+	}
+	return s.store.AppendEvents(events...)
+}
+
+// restoreTickets stores tickets as they were before a change that could not
+// be recorded. Failures are logged for reconciliation.
+func (s *server) restoreTickets(tickets ...*pb.TicketReceipt) {
+	for _, receipt := range tickets {
+		if err := s.store.PutTicket(receipt); err != nil {
+			log.Printf("Failed to restore ticket %s of booking %s: %v", receipt.TicketId, receipt.BookingReference, err)
+		}
+	}
+}
+
+// unstoreTickets deletes new tickets that could not be recorded or stored
+// in full. Failures are logged for reconciliation.
+func (s *server) unstoreTickets(tickets ...*pb.TicketReceipt) {
+	for _, receipt := range tickets {
+		if err := s.store.DeleteTicket(receipt.TicketId); err != nil {
+			log.Printf("Failed to roll back ticket %s of booking %s: %v", receipt.TicketId, receipt.BookingReference, err)
+		}
+	}
+}
+
+func purchaseEvent(receipt *pb.TicketReceipt) *pb.BookingEvent {
+	return &pb.BookingEvent{
+		Type:             pb.BookingEventType_BOOKING_EVENT_TYPE_TICKET_PURCHASED,
+		BookingReference: receipt.BookingReference,
+		TicketId:         receipt.TicketId,
+		DepartureId:      receipt.DepartureId,
+		After:            receipt,
+	}
+}
+
+func seatChangeEvent(before, after *pb.TicketReceipt, reason string) *pb.BookingEvent {
+	return &pb.BookingEvent{
+		Type:             pb.BookingEventType_BOOKING_EVENT_TYPE_SEAT_MODIFIED,
+		Reason:           reason,
+		BookingReference: after.BookingReference,
+		TicketId:         after.TicketId,
+		DepartureId:      after.DepartureId,
+		Before:           before,
+		After:            after,
+	}
+}
+
+func cancellationEvent(before *pb.TicketReceipt, cancellation *pb.Cancellation) *pb.BookingEvent {
+	return &pb.BookingEvent{
+		Type:             pb.BookingEventType_BOOKING_EVENT_TYPE_TICKET_CANCELLED,
+		Reason:           cancellation.Reason,
+		BookingReference: before.BookingReference,
+		TicketId:         before.TicketId,
+		DepartureId:      before.DepartureId,
+		Before:           before,
+		Cancellation:     cancellation,
+	}
+}
+
+func departureEvent(eventType pb.BookingEventType, departure *pb.Departure) *pb.BookingEvent {
+	return &pb.BookingEvent{
+		Type:        eventType,
+		DepartureId: departure.Id,
+		Departure:   departure,
+	}
+}
+
+// upgradeTicket gives a ticket written before tickets were versioned
+// version 1, so expected-version checks apply to it like any other.
+func upgradeTicket(receipt *pb.TicketReceipt) *pb.TicketReceipt {
+	if receipt.GetVersion() == 0 {
+		receipt = proto.Clone(receipt).(*pb.TicketReceipt)
+		receipt.Version = 1
+	}
+	return receipt
+}
+
+// replayLedger applies events, in order, to store.
+func replayLedger(store Store, events []*pb.BookingEvent) error {
+	for _, event := range events {
+		var err error
+		switch event.Type {
+		case pb.BookingEventType_BOOKING_EVENT_TYPE_TICKET_PURCHASED,
+			pb.BookingEventType_BOOKING_EVENT_TYPE_SEAT_MODIFIED:
+			err = store.PutTicket(upgradeTicket(event.After))
+		case pb.BookingEventType_BOOKING_EVENT_TYPE_TICKET_CANCELLED:
+			if err = store.PutCancellation(event.Cancellation); err == nil {
+				if err = store.DeleteTicket(event.TicketId); errors.Is(err, ErrNotFound) {
+					err = nil
+				}
+			}
+		case pb.BookingEventType_BOOKING_EVENT_TYPE_DEPARTURE_CREATED,
+			pb.BookingEventType_BOOKING_EVENT_TYPE_DEPARTURE_CANCELLED:
+			err = store.PutDeparture(event.Departure)
+		default:
+			err = fmt.Errorf("unknown event type %v", event.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("replay event %d: %w", event.Sequence, err)
+		}
+		if err := store.AppendEvents(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rebuild discards the stored departures, tickets and cancellations and
+// rebuilds them by replaying the ledger.
+func (m *memoryStore) Rebuild() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rebuilt := NewMemoryStore()
+	if err := replayLedger(rebuilt, m.events); err != nil {
+		return err
+	}
+	m.memoryData = rebuilt.memoryData
+	return nil
+}
+
+func (s *server) GetBookingHistory(ctx context.Context, req *pb.BookingHistoryRequest) (*pb.BookingHistory, error) {
+	events, err := s.store.EventsByReference(req.BookingReference)
+	if err != nil {
+		return nil, err
+	}
+	// Bookings the caller may not read look as if they did not exist
+	if len(events) == 0 || authorizeBooking(ctx, events) != nil {
+		return nil, trainerr.NotFound(trainerr.ResourceBooking, req.BookingReference, "booking %s not found", req.BookingReference)
+	}
+	return &pb.BookingHistory{Events: events}, nil
+}
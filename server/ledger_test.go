@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// storeState lists the departures, tickets and cancellations in store in a
+// stable order so two stores can be compared.
+func storeState(t *testing.T, store Store) []proto.Message {
+	t.Helper()
+	departures, err := store.ListDepartures()
+	require.NoError(t, err)
+	sort.Slice(departures, func(i, j int) bool { return departures[i].Id < departures[j].Id })
+
+	var state []proto.Message
+	var tickets []*pb.TicketReceipt
+	for _, departure := range departures {
+		state = append(state, departure)
+		for _, section := range departure.Sections {
+			sectionTickets, err := store.SectionTickets(departure.Id, section.Name)
+			require.NoError(t, err)
+			tickets = append(tickets, sectionTickets...)
+		}
+	}
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].TicketId < tickets[j].TicketId })
+	for _, receipt := range tickets {
+		state = append(state, receipt)
+	}
+
+	events, err := store.Events()
+	require.NoError(t, err)
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if event.BookingReference == "" || seen[event.BookingReference] {
+			continue
+		}
+		seen[event.BookingReference] = true
+		cancellations, err := store.CancellationsByReference(event.BookingReference)
+		require.NoError(t, err)
+		for _, cancellation := range cancellations {
+			state = append(state, cancellation)
+		}
+	}
+	return state
+}
+
+func assertSameState(t *testing.T, want, got []proto.Message) {
+	t.Helper()
+	require.Equal(t, len(want), len(got), "stores hold a different number of records")
+	for i := range want {
+		assert.True(t, proto.Equal(want[i], got[i]), "record %d differs:\nwant %v\ngot  %v", i, want[i], got[i])
+	}
+}
+
+// bookAndChange exercises every kind of ledger event.
+func bookAndChange(t *testing.T, server *server) {
+	t.Helper()
+	ctx := context.Background()
+	departure := newRouteDeparture(t, server)
+
+	first, err := server.PurchaseTicket(ctx, purchaseRequest("first@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	second, err := server.PurchaseTicket(ctx, purchaseRequest("second@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	_, err = server.PurchaseGroupTicket(ctx, groupRequest(3))
+	require.NoError(t, err, "error purchasing group ticket")
+	_, err = buyLeg(server, departure.Id, "route@example.com", "London", "Paris")
+	require.NoError(t, err, "error purchasing ticket")
+
+	_, err = server.ModifyUserSeat(ctx, &pb.ModifySeatRequest{BookingReference: first.BookingReference, NewSection: "B", NewSeat: 9})
+	require.NoError(t, err, "error modifying seat")
+	for _, pair := range [][2]string{{first.BookingReference, second.BookingReference}, {second.BookingReference, first.BookingReference}} {
+		_, err = server.SwapSeats(ctx, &pb.SwapSeatsRequest{BookingReference: pair[0], OtherBookingReference: pair[1]})
+		require.NoError(t, err, "error swapping seats")
+	}
+	_, err = server.CancelTicket(ctx, &pb.CancelTicketRequest{BookingReference: second.BookingReference})
+	require.NoError(t, err, "error cancelling ticket")
+	_, err = server.CancelDeparture(ctx, &pb.DepartureRequest{DepartureId: departure.Id})
+	require.NoError(t, err, "error cancelling departure")
+}
+
+func TestGetBookingHistory(t *testing.T) {
+	clock := newFakeClock()
+	server := NewServer(WithClock(clock.Now))
+	ctx := context.Background()
+
+	receipt, err := server.PurchaseTicket(ctx, purchaseRequest("john@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	clock.Advance(time.Minute)
+	moved, err := server.ModifyUserSeat(ctx, &pb.ModifySeatRequest{BookingReference: receipt.BookingReference, NewSection: "B", NewSeat: 3})
+	require.NoError(t, err, "error modifying seat")
+	_, err = server.RemoveUser(ctx, &pb.UserRequest{BookingReference: receipt.BookingReference})
+	require.NoError(t, err, "error removing user")
+
+	history, err := server.GetBookingHistory(ctx, &pb.BookingHistoryRequest{BookingReference: receipt.BookingReference})
+	require.NoError(t, err, "error getting booking history")
+	require.Len(t, history.Events, 3)
+
+	purchased, modified, cancelled := history.Events[0], history.Events[1], history.Events[2]
+	assert.Equal(t, pb.BookingEventType_BOOKING_EVENT_TYPE_TICKET_PURCHASED, purchased.Type)
+	assert.Nil(t, purchased.Before)
+	assert.True(t, proto.Equal(receipt, purchased.After))
+	assert.Equal(t, systemActor, purchased.Actor, "direct calls have no peer")
+	assert.True(t, clock.now.Equal(modified.At.AsTime()))
+
+	assert.Equal(t, pb.BookingEventType_BOOKING_EVENT_TYPE_SEAT_MODIFIED, modified.Type)
+	assert.Equal(t, receipt.Seat.Seat, modified.Before.Seat.Seat)
+	assert.True(t, proto.Equal(moved, modified.After))
+
+	assert.Equal(t, pb.BookingEventType_BOOKING_EVENT_TYPE_TICKET_CANCELLED, cancelled.Type)
+	assert.Equal(t, "removed", cancelled.Reason)
+	assert.Nil(t, cancelled.After)
+	assert.Equal(t, receipt.TicketId, cancelled.Cancellation.TicketId)
+	assert.Less(t, purchased.Sequence, modified.Sequence)
+
+	_, err = server.GetBookingHistory(ctx, &pb.BookingHistoryRequest{BookingReference: "ZZZZZZ"})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestBookingHistoryRecordsPeer(t *testing.T) {
+	client := dialTestServer(t, NewServer())
+	receipt, err := client.PurchaseTicket(context.Background(), purchaseRequest("john@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+
+	history, err := client.GetBookingHistory(context.Background(), &pb.BookingHistoryRequest{BookingReference: receipt.BookingReference})
+	require.NoError(t, err, "error getting booking history")
+	assert.True(t, strings.HasPrefix(history.Events[0].Actor, "peer:"), "actor %q", history.Events[0].Actor)
+}
+
+func TestReplayLedgerRebuildsState(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		bookAndChange(t, server)
+		events, err := server.store.Events()
+		require.NoError(t, err)
+
+		rebuilt := NewMemoryStore()
+		require.NoError(t, replayLedger(rebuilt, events), "error replaying ledger")
+		assertSameState(t, storeState(t, server.store), storeState(t, rebuilt))
+
+		replayed, err := rebuilt.Events()
+		require.NoError(t, err)
+		assert.Len(t, replayed, len(events), "the rebuilt store keeps the ledger")
+	})
+}
+
+func TestFileStoreRebuild(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenFileStore(dir, 3)
+	require.NoError(t, err, "error opening file store")
+	server, err := NewServerWithStore(store)
+	require.NoError(t, err, "error creating server")
+	bookAndChange(t, server)
+	want := storeState(t, store)
+
+	// Drift the materialised state away from the ledger, as a damaged
+	// snapshot would, then rebuild it.
+	require.NoError(t, store.memoryStore.PutTicket(&pb.TicketReceipt{TicketId: "TKT-STRAY", DepartureId: defaultDepartureID,
+		Seat: &pb.SeatAllocation{Section: "A", Seat: 50}, User: &pb.User{Email: "stray@example.com"}}))
+	require.NoError(t, store.Rebuild(), "error rebuilding store")
+	require.NoError(t, store.Close())
+
+	reopened, err := OpenFileStore(dir, 3)
+	require.NoError(t, err, "error reopening file store")
+	defer reopened.Close()
+	assertSameState(t, want, storeState(t, reopened))
+}
+
+// brokenLedger is a store whose ledger can refuse new events.
+type brokenLedger struct {
+	Store
+	fail bool
+}
+
+func (s *brokenLedger) AppendEvents(events ...*pb.BookingEvent) error {
+	if s.fail {
+		return errors.New("disk full")
+	}
+	return s.Store.AppendEvents(events...)
+}
+
+func TestUnrecordedChangesAreUndone(t *testing.T) {
+	store := &brokenLedger{Store: NewMemoryStore()}
+	gateway := NewFakeGateway(FakeApprove)
+	server, err := NewServerWithStore(store, WithPaymentProvider(gateway, time.Second))
+	require.NoError(t, err, "error creating server")
+	ctx := context.Background()
+	departure := newRouteDeparture(t, server)
+	first, err := server.PurchaseTicket(ctx, purchaseRequest("first@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	second, err := server.PurchaseTicket(ctx, purchaseRequest("second@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	_, err = server.SwapSeats(ctx, &pb.SwapSeatsRequest{BookingReference: first.BookingReference, OtherBookingReference: second.BookingReference})
+	require.NoError(t, err, "error consenting to swap")
+	want := storeState(t, store)
+
+	store.fail = true
+	_, err = server.PurchaseTicket(ctx, purchaseRequest("third@example.com"))
+	assert.Error(t, err, "purchase should fail when it cannot be recorded")
+	_, err = server.PurchaseGroupTicket(ctx, groupRequest(3))
+	assert.Error(t, err, "group purchase should fail when it cannot be recorded")
+	_, err = server.ModifyUserSeat(ctx, &pb.ModifySeatRequest{BookingReference: first.BookingReference, NewSection: "B", NewSeat: 9})
+	assert.Error(t, err, "seat change should fail when it cannot be recorded")
+	_, err = server.SwapSeats(ctx, &pb.SwapSeatsRequest{BookingReference: second.BookingReference, OtherBookingReference: first.BookingReference})
+	assert.Error(t, err, "swap should fail when it cannot be recorded")
+	_, err = server.CancelDeparture(ctx, &pb.DepartureRequest{DepartureId: departure.Id})
+	assert.Error(t, err, "departure cancellation should fail when it cannot be recorded")
+	_, err = server.CreateDeparture(ctx, &pb.CreateDepartureRequest{TrainId: "EUR9400", Date: "2026-11-01", Origin: "London", Destination: "Paris"})
+	assert.Error(t, err, "departure creation should fail when it cannot be recorded")
+
+	assertSameState(t, want, storeState(t, store))
+	events, err := store.Events()
+	require.NoError(t, err)
+	rebuilt := NewMemoryStore()
+	require.NoError(t, replayLedger(rebuilt, events), "error replaying ledger")
+	assertSameState(t, storeState(t, store), storeState(t, rebuilt))
+
+	var refunded int64
+	for _, txn := range gateway.transactions {
+		refunded += txn.refunded
+	}
+	assert.Equal(t, int64(2000*4), refunded, "unrecorded purchases should be refunded")
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"slices"
+	"sync"
+
+	pb "test_train/protobuf"
+)
+
+// Concurrency
+//
+// Each departure has its own read-write lock, so bookings on different
+// departures run in parallel and reads of a departure only wait for
+// changes to that departure. Seats are allocated across every section of a
+// departure, so the departure rather than the section is the unit of
+// locking.
+//
+//   - Changes to a departure's tickets, holds, waitlist or swap consents
+//     hold its lock exclusively: allocate, placeHold, issueTicket,
+//     cancelTicket, promoteWaitlist and seatChanged all expect it held.
+//   - Payments are never made under a departure lock, since the gateway
+//     may take up to its timeout. A purchase keeps its seat with a hold
+//     while it is charged unlocked, then locks again to store the ticket
+//     or give the seat up; see purchaseHold. Group purchases reserve
+//     their seats the same way.
+//   - Reads that combine several lookups, like seat maps and fare quotes,
+//     hold it shared. Single store lookups, like receipts and section
+//     lists, take no departure lock: the store is safe for concurrent use.
+//   - Calls that touch two departures lock both with lockDepartures, which
+//     orders them by id so two such calls cannot deadlock.
+//
+// The store, the availability feed, the idempotency cache and the payment
+// gateway guard themselves and are only ever locked after a departure.
+
+// departureState is the in-memory state of one departure, guarded by its
+// lock, which also serialises changes to the departure's stored tickets.
+type departureState struct {
+	sync.RWMutex
+	holds    map[string]*seatHold    // by token
+	waitlist []*waitlistEntry        // FIFO queue
+	swaps    map[string]*swapConsent // consenting ticket id -> consent
+
+	// Guarded by departureStates.mu rather than the departure's lock
+	users  int  // calls holding or waiting for the lock
+	exists bool // the departure is stored, so the state is kept
+}
+
+// departureStates holds the state of every departure and finds the
+// departure a hold token or waitlist entry belongs to.
+type departureStates struct {
+	mu      sync.Mutex
+	byID    map[string]*departureState
+	holds   map[string]string // hold token -> departure id
+	entries map[string]string // waitlist entry id -> departure id
+}
+
+func newDepartureStates() *departureStates {
+	return &departureStates{
+		byID:    make(map[string]*departureState),
+		holds:   make(map[string]string),
+		entries: make(map[string]string),
+	}
+}
+
+// state returns the state of departure id, with an empty id meaning the
+// default departure. Callers hold the departure's lock, so the state exists.
+func (s *server) state(id string) *departureState {
+	if id == "" {
+		id = defaultDepartureID
+	}
+	d := s.states
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.get(id)
+}
+
+// get returns the state of departure id, creating it on first use.
+func (d *departureStates) get(id string) *departureState {
+	state, ok := d.byID[id]
+	if !ok {
+		state = &departureState{holds: make(map[string]*seatHold), swaps: make(map[string]*swapConsent)}
+		d.byID[id] = state
+	}
+	return state
+}
+
+// acquire returns the state of departure id for a call about to lock it.
+func (s *server) acquire(id string) *departureState {
+	d := s.states
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state := d.get(id)
+	state.users++
+	return state
+}
+
+// release is called once the lock taken on an acquired state is released.
+// Requests may name departures that do not exist, and are only rejected
+// once locked, so the state of a departure that is not stored is dropped
+// when its last user is done; states of stored departures are kept, since
+// departures are cancelled rather than deleted.
+func (s *server) release(id string, state *departureState) {
+	d := s.states
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state.users--
+	if state.users > 0 || state.exists {
+		return
+	}
+	if _, err := s.store.GetDeparture(id); err == nil {
+		state.exists = true
+		return
+	}
+	delete(d.byID, id)
+}
+
+// lockDepartures locks the departures with ids exclusively, in a fixed
+// order, and returns the function that unlocks them.
+func (s *server) lockDepartures(ids ...string) func() {
+	ids = slices.Clone(ids)
+	for i, id := range ids {
+		if id == "" {
+			ids[i] = defaultDepartureID
+		}
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	states := make([]*departureState, len(ids))
+	for i, id := range ids {
+		states[i] = s.acquire(id)
+		states[i].Lock()
+	}
+	return func() {
+		for i := len(states) - 1; i >= 0; i-- {
+			states[i].Unlock()
+			s.release(ids[i], states[i])
+		}
+	}
+}
+
+// readLockDeparture locks departure id for reading and returns the
+// function that unlocks it.
+func (s *server) readLockDeparture(id string) func() {
+	if id == "" {
+		id = defaultDepartureID
+	}
+	state := s.acquire(id)
+	state.RLock()
+	return func() {
+		state.RUnlock()
+		s.release(id, state)
+	}
+}
+
+// lockTicket finds the ticket identified by reference and email, as
+// findTicket does with visible, and locks its departure, along with any
+// others named, until the returned function is called. The ticket is looked
+// up again once locked, in case it moved to another departure in the
+// meantime.
+func (s *server) lockTicket(reference, email string, visible func(*pb.TicketReceipt) bool, others ...string) (*pb.TicketReceipt, func(), error) {
+	for {
+		receipt, err := s.findTicket(reference, email, visible)
+		if err != nil {
+			return nil, nil, err
+		}
+		unlock := s.lockDepartures(append(others[:len(others):len(others)], receipt.DepartureId)...)
+		locked, err := s.findTicket(reference, email, visible)
+		if err == nil && locked.TicketId == receipt.TicketId && locked.DepartureId == receipt.DepartureId {
+			return locked, unlock, nil
+		}
+		unlock()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// holdDeparture returns the departure of the hold with token, if any.
+func (s *server) holdDeparture(token string) (string, bool) {
+	s.states.mu.Lock()
+	defer s.states.mu.Unlock()
+	id, ok := s.states.holds[token]
+	return id, ok
+}
+
+// entryDeparture returns the departure of the waitlist entry with id, if
+// any.
+func (s *server) entryDeparture(id string) (string, bool) {
+	s.states.mu.Lock()
+	defer s.states.mu.Unlock()
+	departureID, ok := s.states.entries[id]
+	return departureID, ok
+}
+
+// index records, or with an empty departureID forgets, the departure of a
+// hold token or waitlist entry in index.
+func (s *server) index(index map[string]string, key, departureID string) {
+	s.states.mu.Lock()
+	defer s.states.mu.Unlock()
+	if departureID == "" {
+		delete(index, key)
+		return
+	}
+	index[key] = departureID
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+)
+
+var (
+	// ErrPaymentDeclined is returned when the provider refuses a charge.
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrPaymentTimeout is returned when the provider does not answer in time.
+	ErrPaymentTimeout = errors.New("payment provider timed out")
+)
+
+// defaultPaymentTimeout bounds every call the server makes to the provider.
+const defaultPaymentTimeout = 10 * time.Second
+
+// PaymentRequest is a charge to authorize against a card token.
+type PaymentRequest struct {
+	Token       string
+	AmountCents int64
+	Currency    string
+	// Reference is the booking reference, for the provider's records.
+	Reference string
+}
+
+// PaymentProvider is a card payment gateway. Authorize reserves funds and
+// returns a transaction id that the other calls operate on; Capture settles
+// an authorization, Void cancels one that was not captured, and Refund
+// returns part or all of a captured amount. A Refund repeating an earlier
+// one's idempotency key returns that refund's id without refunding again,
+// so a refund whose outcome was lost can be retried.
+type PaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, req PaymentRequest) (transactionID string, err error)
+	Capture(ctx context.Context, transactionID string) error
+	Void(ctx context.Context, transactionID string) error
+	Refund(ctx context.Context, transactionID string, amountCents int64, idempotencyKey string) (refundID string, err error)
+}
+
+// FakeGatewayMode selects how a FakeGateway answers authorizations.
+type FakeGatewayMode string
+
+const (
+	FakeApprove FakeGatewayMode = "approve"
+	FakeDecline FakeGatewayMode = "decline"
+	FakeTimeout FakeGatewayMode = "timeout"
+)
+
+// Card tokens that make a FakeGateway decline or time out regardless of
+// its mode, so a single running server can exercise every path.
+const (
+	FakeDeclineToken = "tok_decline"
+	FakeTimeoutToken = "tok_timeout"
+)
+
+// fakeTransaction is the state of one authorization at the fake gateway.
+type fakeTransaction struct {
+	amount   int64
+	captured bool
+	voided   bool
+	refunded int64
+}
+
+// FakeGateway is a deterministic in-process PaymentProvider for tests and
+// local runs. Transaction ids are sequential.
+type FakeGateway struct {
+	mu           sync.Mutex
+	mode         FakeGatewayMode
+	next         int
+	transactions map[string]*fakeTransaction
+	refunds      map[string]string // idempotency key -> refund id
+}
+
+func NewFakeGateway(mode FakeGatewayMode) *FakeGateway {
+	return &FakeGateway{mode: mode, transactions: make(map[string]*fakeTransaction), refunds: make(map[string]string)}
+}
+
+func (g *FakeGateway) Name() string { return "fake" }
+
+// SetMode changes how subsequent authorizations are answered.
+func (g *FakeGateway) SetMode(mode FakeGatewayMode) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.mode = mode
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req PaymentRequest) (string, error) {
+	g.mu.Lock()
+	mode := g.mode
+	g.mu.Unlock()
+
+	switch {
+	case mode == FakeTimeout || req.Token == FakeTimeoutToken:
+		<-ctx.Done()
+		return "", fmt.Errorf("%w: %v", ErrPaymentTimeout, ctx.Err())
+	case mode == FakeDecline || req.Token == FakeDeclineToken:
+		return "", ErrPaymentDeclined
+	}
+	if req.AmountCents < 0 {
+		return "", fmt.Errorf("amount must not be negative")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.next++
+	id := fmt.Sprintf("fake_txn_%06d", g.next)
+	g.transactions[id] = &fakeTransaction{amount: req.AmountCents}
+	return id, nil
+}
+
+func (g *FakeGateway) transaction(id string) (*fakeTransaction, error) {
+	txn, ok := g.transactions[id]
+	if !ok {
+		return nil, fmt.Errorf("transaction %s not found", id)
+	}
+	return txn, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, transactionID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	txn, err := g.transaction(transactionID)
+	if err != nil {
+		return err
+	}
+	if txn.captured || txn.voided {
+		return fmt.Errorf("transaction %s cannot be captured", transactionID)
+	}
+	txn.captured = true
+	return nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, transactionID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	txn, err := g.transaction(transactionID)
+	if err != nil {
+		return err
+	}
+	if txn.captured || txn.voided {
+		return fmt.Errorf("transaction %s cannot be voided", transactionID)
+	}
+	txn.voided = true
+	return nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, transactionID string, amountCents int64, idempotencyKey string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if id, ok := g.refunds[idempotencyKey]; ok && idempotencyKey != "" {
+		return id, nil
+	}
+	txn, err := g.transaction(transactionID)
+	if err != nil {
+		return "", err
+	}
+	if !txn.captured {
+		return "", fmt.Errorf("transaction %s was not captured", transactionID)
+	}
+	if amountCents <= 0 || txn.refunded+amountCents > txn.amount {
+		return "", fmt.Errorf("cannot refund %d of %d remaining on %s", amountCents, txn.amount-txn.refunded, transactionID)
+	}
+	txn.refunded += amountCents
+	g.next++
+	id := fmt.Sprintf("fake_ref_%06d", g.next)
+	if idempotencyKey != "" {
+		g.refunds[idempotencyKey] = id
+	}
+	return id, nil
+}
+
+// paymentRetryDelay is suggested to clients after a transient payment error.
+const paymentRetryDelay = 5 * time.Second
+
+// paymentError maps a provider failure to the status returned to clients.
+func paymentError(err error) error {
+	switch {
+	case errors.Is(err, ErrPaymentDeclined):
+		return trainerr.FailedPrecondition("PAYMENT_DECLINED", "payment", "payment declined")
+	case errors.Is(err, ErrPaymentTimeout):
+		return trainerr.Unavailable(paymentRetryDelay, "payment provider timed out")
+	default:
+		return fmt.Errorf("payment failed: %w", err)
+	}
+}
+
+// WithPaymentProvider sets the gateway tickets are charged through and the
+// deadline applied to each call.
+func WithPaymentProvider(provider PaymentProvider, timeout time.Duration) Option {
+	return func(s *server) {
+		s.payment = provider
+		s.paymentTimeout = timeout
+	}
+}
+
+// paymentContext bounds a provider call. Cleanup calls (void, refund) pass
+// detached so they still run when the client has gone away.
+func (s *server) paymentContext(ctx context.Context, detached bool) (context.Context, context.CancelFunc) {
+	if detached {
+		ctx = context.WithoutCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.paymentTimeout)
+}
+
+// chargeTicket authorizes and captures the fare of receipt.
+func (s *server) chargeTicket(ctx context.Context, receipt *pb.TicketReceipt, token string) (*pb.PaymentInfo, error) {
+	return s.charge(ctx, receipt.BookingReference, receipt.Fare.TotalCents, receipt.Fare.Currency, token)
+}
+
+// charge authorizes and captures amountCents for a booking.
+func (s *server) charge(ctx context.Context, reference string, amountCents int64, currency, token string) (*pb.PaymentInfo, error) {
+	callCtx, cancel := s.paymentContext(ctx, false)
+	defer cancel()
+
+	transactionID, err := s.payment.Authorize(callCtx, PaymentRequest{
+		Token:       token,
+		AmountCents: amountCents,
+		Currency:    currency,
+		Reference:   reference,
+	})
+	if err != nil {
+		return nil, paymentError(err)
+	}
+	payment := &pb.PaymentInfo{
+		Provider:      s.payment.Name(),
+		Status:        pb.PaymentStatus_PAYMENT_STATUS_AUTHORIZED,
+		TransactionId: transactionID,
+		AmountCents:   amountCents,
+		Currency:      currency,
+	}
+
+	if err := s.payment.Capture(callCtx, transactionID); err != nil {
+		s.voidPayment(ctx, payment)
+		return nil, paymentError(err)
+	}
+	payment.Status = pb.PaymentStatus_PAYMENT_STATUS_CAPTURED
+	return payment, nil
+}
+
+// voidPayment cancels an authorization that will not be captured. Failures
+// are logged for reconciliation rather than returned, since the caller is
+// already unwinding another error.
+func (s *server) voidPayment(ctx context.Context, payment *pb.PaymentInfo) {
+	callCtx, cancel := s.paymentContext(ctx, true)
+	defer cancel()
+
+	if err := s.payment.Void(callCtx, payment.TransactionId); err != nil {
+		log.Printf("Failed to void payment %s: %v", payment.TransactionId, err)
+		return
+	}
+	payment.Status = pb.PaymentStatus_PAYMENT_STATUS_VOIDED
+}
+
+// refundPayment returns amountCents of a captured payment and records the
+// refund on payment. key identifies the refund to the provider, so calls
+// with the same key refund once. Nothing is refunded for a free fare or a
+// non-refundable cancellation, so the provider is not called.
+func (s *server) refundPayment(ctx context.Context, payment *pb.PaymentInfo, amountCents int64, key string) error {
+	if amountCents <= 0 {
+		return nil
+	}
+	callCtx, cancel := s.paymentContext(ctx, true)
+	defer cancel()
+
+	refundID, err := s.payment.Refund(callCtx, payment.TransactionId, amountCents, key)
+	if err != nil {
+		log.Printf("Failed to refund payment %s: %v", payment.TransactionId, err)
+		return trainerr.Unavailable(paymentRetryDelay, "refund failed; try again later")
+	}
+	payment.RefundedCents += amountCents
+	payment.RefundIds = append(payment.RefundIds, refundID)
+	if payment.RefundedCents >= payment.AmountCents {
+		payment.Status = pb.PaymentStatus_PAYMENT_STATUS_REFUNDED
+	} else {
+		payment.Status = pb.PaymentStatus_PAYMENT_STATUS_PARTIALLY_REFUNDED
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestPurchaseRecordsPayment(t *testing.T) {
+	server := NewServer()
+
+	receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("a@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	require.NotNil(t, receipt.Payment, "receipt should carry payment details")
+	assert.Equal(t, "fake", receipt.Payment.Provider)
+	assert.Equal(t, pb.PaymentStatus_PAYMENT_STATUS_CAPTURED, receipt.Payment.Status)
+	assert.Equal(t, "fake_txn_000001", receipt.Payment.TransactionId)
+	assert.Equal(t, receipt.Fare.TotalCents, receipt.Payment.AmountCents)
+	assert.Equal(t, receipt.Fare.Currency, receipt.Payment.Currency)
+}
+
+func TestDeclinedPaymentIssuesNoTicket(t *testing.T) {
+	gateway := NewFakeGateway(FakeDecline)
+	server := NewServer(WithPaymentProvider(gateway, time.Second))
+
+	_, err := server.PurchaseTicket(context.Background(), purchaseRequest("a@example.com"))
+	assertCode(t, err, codes.FailedPrecondition, "declined payment")
+
+	resp, err := server.ListTicketsForUser(context.Background(), &pb.UserRequest{Email: "a@example.com"})
+	require.NoError(t, err, "error listing tickets")
+	assert.Empty(t, resp.Tickets, "declined purchase should not create a ticket")
+
+	gateway.SetMode(FakeApprove)
+	receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("a@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	assert.Equal(t, int32(1), receipt.Seat.Seat, "declined purchase should not consume a seat")
+}
+
+func TestPaymentTimeout(t *testing.T) {
+	server := NewServer(WithPaymentProvider(NewFakeGateway(FakeApprove), 10*time.Millisecond))
+
+	req := purchaseRequest("a@example.com")
+	req.PaymentToken = FakeTimeoutToken
+	_, err := server.PurchaseTicket(context.Background(), req)
+	assertCode(t, err, codes.Unavailable, "payment timeout")
+
+	req.PaymentToken = FakeDeclineToken
+	_, err = server.PurchaseTicket(context.Background(), req)
+	assertCode(t, err, codes.FailedPrecondition, "declined payment")
+}
+
+func TestConfirmHoldCharges(t *testing.T) {
+	server := NewServer()
+
+	hold, err := server.HoldSeat(context.Background(), purchaseRequest("a@example.com"))
+	require.NoError(t, err, "error holding seat")
+
+	_, err = server.ConfirmHold(context.Background(), &pb.HoldRequest{Token: hold.Token, PaymentToken: FakeDeclineToken})
+	assertCode(t, err, codes.FailedPrecondition, "declined payment")
+
+	receipt, err := server.ConfirmHold(context.Background(), &pb.HoldRequest{Token: hold.Token})
+	require.NoError(t, err, "hold should survive a declined payment")
+	assert.Equal(t, pb.PaymentStatus_PAYMENT_STATUS_CAPTURED, receipt.Payment.Status)
+}
+
+func TestFakeGatewayTransitions(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway(FakeApprove)
+
+	txn, err := gateway.Authorize(ctx, PaymentRequest{AmountCents: 1000, Currency: "USD"})
+	require.NoError(t, err)
+	_, err = gateway.Refund(ctx, txn, 100, "r0")
+	assert.Error(t, err, "uncaptured payments cannot be refunded")
+
+	require.NoError(t, gateway.Capture(ctx, txn))
+	assert.Error(t, gateway.Void(ctx, txn), "captured payments cannot be voided")
+
+	refund, err := gateway.Refund(ctx, txn, 600, "r1")
+	assert.NoError(t, err)
+	again, err := gateway.Refund(ctx, txn, 600, "r1")
+	assert.NoError(t, err, "a repeated refund should be answered, not refused")
+	assert.Equal(t, refund, again, "a repeated refund should return the first one")
+	_, err = gateway.Refund(ctx, txn, 600, "r2")
+	assert.Error(t, err, "refunds cannot exceed the captured amount")
+
+	other, err := gateway.Authorize(ctx, PaymentRequest{AmountCents: 1000, Currency: "USD"})
+	require.NoError(t, err)
+	require.NoError(t, gateway.Void(ctx, other))
+	assert.Error(t, gateway.Capture(ctx, other), "voided payments cannot be captured")
+}
+
+// blockingGateway approves payments but holds up authorizations of the
+// block token until released.
+type blockingGateway struct {
+	*FakeGateway
+	blocked chan struct{}
+	release chan struct{}
+}
+
+func (g *blockingGateway) Authorize(ctx context.Context, req PaymentRequest) (string, error) {
+	if req.Token == "block" {
+		g.blocked <- struct{}{}
+		<-g.release
+	}
+	return g.FakeGateway.Authorize(ctx, req)
+}
+
+func TestSlowPaymentDoesNotBlockBookings(t *testing.T) {
+	gateway := &blockingGateway{NewFakeGateway(FakeApprove), make(chan struct{}), make(chan struct{})}
+	server := NewServer(WithPaymentProvider(gateway, time.Minute))
+	other := newTimedDeparture(t, server)
+
+	slow := purchaseRequest("slow@example.com")
+	slow.PaymentToken = "block"
+	purchased := make(chan *pb.TicketReceipt)
+	go func() {
+		receipt, err := server.PurchaseTicket(context.Background(), slow)
+		assert.NoError(t, err, "error purchasing ticket")
+		purchased <- receipt
+	}()
+	<-gateway.blocked
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		_, err := server.GetSeatMap(context.Background(), &pb.SeatMapRequest{})
+		assert.NoError(t, err, "error reading seat map")
+		_, err = buyLeg(server, other.Id, "other@example.com", "London", "Paris")
+		assert.NoError(t, err, "error purchasing ticket on another departure")
+		receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("same@example.com"))
+		if assert.NoError(t, err, "error purchasing ticket on the same departure") {
+			assert.Equal(t, int32(2), receipt.Seat.Seat, "the seat being paid for should stay reserved")
+		}
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("bookings waited for another purchase's payment")
+	}
+
+	close(gateway.release)
+	receipt := <-purchased
+	require.NotNil(t, receipt)
+	assert.Equal(t, int32(1), receipt.Seat.Seat)
+}
+
+// countingGateway counts the refunds asked of it.
+type countingGateway struct {
+	*blockingGateway
+	refunds int
+}
+
+func (g *countingGateway) Refund(ctx context.Context, transactionID string, amountCents int64, idempotencyKey string) (string, error) {
+	g.refunds++
+	return g.blockingGateway.Refund(ctx, transactionID, amountCents, idempotencyKey)
+}
+
+func TestFreeTicketIsNotRefundedWhenUnbooked(t *testing.T) {
+	gateway := &countingGateway{blockingGateway: &blockingGateway{NewFakeGateway(FakeApprove), make(chan struct{}), make(chan struct{})}}
+	server := NewServer(WithPaymentProvider(gateway, time.Minute), WithPricing(PricingRules{Currency: "USD"}))
+	departure := newTimedDeparture(t, server)
+
+	req := legRequest(departure.Id, "free@example.com", "London", "Paris")
+	req.PaymentToken = "block"
+	done := make(chan error)
+	go func() {
+		_, err := server.PurchaseTicket(context.Background(), req)
+		done <- err
+	}()
+	<-gateway.blocked
+	_, err := server.CancelDeparture(context.Background(), &pb.DepartureRequest{DepartureId: departure.Id})
+	require.NoError(t, err, "error cancelling departure")
+	close(gateway.release)
+
+	assertCode(t, <-done, codes.FailedPrecondition, "the departure was cancelled during the payment")
+	assert.Zero(t, gateway.refunds, "a free fare should not be refunded")
+}
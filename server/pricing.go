@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+)
+
+// PricingRules configures how fares are computed. Multipliers are applied in
+// turn to the running fare (class, lead time, demand, then passenger type)
+// and each adjustment is reported as its own fare component.
+type PricingRules struct {
+	Currency        string `json:"currency"`
+	BaseFareCents   int64  `json:"base_fare_cents"`
+	PerSegmentCents int64  `json:"per_segment_cents"`
+	PerKmCents      int64  `json:"per_km_cents"`
+	// ClassMultipliers is keyed by seat class; missing classes pay 1.0.
+	ClassMultipliers map[string]float64 `json:"class_multipliers"`
+	// PassengerMultipliers is keyed by "adult", "child" or "senior".
+	PassengerMultipliers map[string]float64 `json:"passenger_multipliers"`
+	// LeadTime applies the band with the largest MinDays not exceeding the
+	// number of days between booking and departure. Bands are listed from
+	// the largest MinDays down.
+	LeadTime []LeadTimeBand `json:"lead_time"`
+	// Demand applies the band with the largest MinLoadFactor not exceeding
+	// the share of seats already sold on the journey. Bands are listed from
+	// the largest MinLoadFactor down.
+	Demand []DemandBand `json:"demand"`
+}
+
+type LeadTimeBand struct {
+	MinDays    int     `json:"min_days"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+type DemandBand struct {
+	MinLoadFactor float64 `json:"min_load_factor"`
+	Multiplier    float64 `json:"multiplier"`
+}
+
+// defaultPricingRules charge the flat $20 every ticket cost before pricing
+// was configurable.
+func defaultPricingRules() PricingRules {
+	return PricingRules{Currency: "USD", BaseFareCents: 2000}
+}
+
+// LoadPricingRules reads and validates a pricing rules file.
+func LoadPricingRules(path string) (PricingRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PricingRules{}, err
+	}
+	var rules PricingRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return PricingRules{}, fmt.Errorf("decode %s: %w", path, err)
+	}
+	if err := rules.validate(); err != nil {
+		return PricingRules{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// validate rejects rules that could price a ticket wrongly: negative fares,
+// multipliers that are not positive, and bands that overlap or are out of
+// order, where a band could never apply.
+func (r PricingRules) validate() error {
+	if r.Currency == "" {
+		return fmt.Errorf("currency is required")
+	}
+	if r.BaseFareCents < 0 || r.PerSegmentCents < 0 || r.PerKmCents < 0 {
+		return fmt.Errorf("fares must not be negative")
+	}
+	for _, multipliers := range []map[string]float64{r.ClassMultipliers, r.PassengerMultipliers} {
+		for name, m := range multipliers {
+			if err := validMultiplier(m); err != nil {
+				return fmt.Errorf("multiplier for %s %w", name, err)
+			}
+		}
+	}
+	for name := range r.PassengerMultipliers {
+		if _, ok := pb.PassengerType_value["PASSENGER_TYPE_"+strings.ToUpper(name)]; !ok {
+			return fmt.Errorf("unknown passenger type %q", name)
+		}
+	}
+
+	for i, band := range r.LeadTime {
+		if err := validMultiplier(band.Multiplier); err != nil {
+			return fmt.Errorf("lead time band %d: multiplier %w", i, err)
+		}
+		if band.MinDays < 0 {
+			return fmt.Errorf("lead time band %d: min_days must not be negative", i)
+		}
+		if i > 0 && band.MinDays >= r.LeadTime[i-1].MinDays {
+			return fmt.Errorf("lead time band %d: min_days must be below the previous band's %d", i, r.LeadTime[i-1].MinDays)
+		}
+	}
+	for i, band := range r.Demand {
+		if err := validMultiplier(band.Multiplier); err != nil {
+			return fmt.Errorf("demand band %d: multiplier %w", i, err)
+		}
+		if band.MinLoadFactor < 0 || band.MinLoadFactor > 1 {
+			return fmt.Errorf("demand band %d: min_load_factor must be between 0 and 1", i)
+		}
+		if i > 0 && band.MinLoadFactor >= r.Demand[i-1].MinLoadFactor {
+			return fmt.Errorf("demand band %d: min_load_factor must be below the previous band's %g", i, r.Demand[i-1].MinLoadFactor)
+		}
+	}
+	return nil
+}
+
+// validMultiplier requires a finite, positive multiplier; a zero would make
+// tickets free and a negative one would pay the passenger.
+func validMultiplier(m float64) error {
+	if !(m > 0) || math.IsInf(m, 0) {
+		return fmt.Errorf("must be positive, got %g", m)
+	}
+	return nil
+}
+
+// fareInput describes the journey being priced.
+type fareInput struct {
+	segments  int
+	km        int
+	seatClass string
+	passenger pb.PassengerType
+	// leadDays is the number of whole days until departure, or -1 if the
+	// departure has no date.
+	leadDays   int
+	loadFactor float64
+}
+
+// passengerName returns the rules key for a passenger type, e.g. "child".
+func passengerName(passenger pb.PassengerType) string {
+	return strings.ToLower(strings.TrimPrefix(passenger.String(), "PASSENGER_TYPE_"))
+}
+
+// quote computes the itemised fare for in.
+func (r PricingRules) quote(in fareInput) *pb.FareBreakdown {
+	fare := &pb.FareBreakdown{Currency: r.Currency}
+	base := r.BaseFareCents + r.PerSegmentCents*int64(in.segments) + r.PerKmCents*int64(in.km)
+	fare.Components = append(fare.Components, &pb.FareComponent{
+		Name:        "base",
+		Description: fmt.Sprintf("%d segment(s), %d km", in.segments, in.km),
+		AmountCents: base,
+	})
+	total := base
+
+	adjust := func(name, description string, multiplier float64) {
+		delta := int64(math.Round(float64(total)*multiplier)) - total
+		if delta == 0 {
+			return
+		}
+		fare.Components = append(fare.Components, &pb.FareComponent{
+			Name:        name,
+			Description: description,
+			AmountCents: delta,
+		})
+		total += delta
+	}
+
+	if m, ok := r.ClassMultipliers[in.seatClass]; ok {
+		adjust("class", in.seatClass+" class", m)
+	}
+	if in.leadDays >= 0 {
+		for _, band := range r.LeadTime {
+			if in.leadDays >= band.MinDays {
+				adjust("lead_time", fmt.Sprintf("booked %d day(s) ahead", in.leadDays), band.Multiplier)
+				break
+			}
+		}
+	}
+	for _, band := range r.Demand {
+		if in.loadFactor >= band.MinLoadFactor {
+			adjust("demand", fmt.Sprintf("%.0f%% of seats sold", in.loadFactor*100), band.Multiplier)
+			break
+		}
+	}
+	if m, ok := r.PassengerMultipliers[passengerName(in.passenger)]; ok {
+		adjust("passenger", passengerName(in.passenger)+" fare", m)
+	}
+
+	fare.TotalCents = total
+	return fare
+}
+
+// wholeUnits rounds a fare to the whole-currency price carried on receipts.
+func wholeUnits(cents int64) int32 {
+	return int32((cents + 50) / 100)
+}
+
+// fareInput gathers everything needed to price a journey on departure.
+func (s *server) fareInput(departure *pb.Departure, journey leg, seatClass string, passenger pb.PassengerType) (fareInput, error) {
+	in := fareInput{
+		segments:  journey.to - journey.from,
+		seatClass: seatClass,
+		passenger: passenger,
+		leadDays:  -1,
+	}
+	if len(departure.SegmentKm) > 0 {
+		for i := journey.from; i < journey.to; i++ {
+			in.km += int(departure.SegmentKm[i])
+		}
+	}
+	if date, err := time.Parse(time.DateOnly, departure.Date); err == nil {
+		now := s.now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if days := int(date.Sub(today).Hours() / 24); days >= 0 {
+			in.leadDays = days
+		} else {
+			in.leadDays = 0
+		}
+	}
+
+	var total, sold int
+	for _, section := range departure.Sections {
+		occupancy, err := s.sectionOccupancy(departure, section.Name, "")
+		if err != nil {
+			return fareInput{}, err
+		}
+		for seat := int32(1); seat <= section.Seats; seat++ {
+			if !occupancy.free(seat, journey) {
+				sold++
+			}
+		}
+		total += int(section.Seats)
+	}
+	if total > 0 {
+		in.loadFactor = float64(sold) / float64(total)
+	}
+	return in, nil
+}
+
+func (s *server) QuoteFare(ctx context.Context, req *pb.QuoteFareRequest) (*pb.FareBreakdown, error) {
+	defer s.readLockDeparture(req.DepartureId)()
+
+	departure, err := s.bookableDeparture(req.DepartureId)
+	if err != nil {
+		return nil, err
+	}
+	journey, err := resolveLeg(departure, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	seatClass := req.SeatClass
+	if seatClass == "" {
+		seatClass = departure.Sections[0].SeatClass
+	} else if !hasSeatClass(departure, seatClass) {
+		return nil, trainerr.InvalidField("seat_class", "departure %s has no %s class seats", departure.Id, seatClass)
+	}
+
+	in, err := s.fareInput(departure, journey, seatClass, req.PassengerType)
+	if err != nil {
+		return nil, err
+	}
+	return s.pricing.quote(in), nil
+}
+
+// hasSeatClass reports whether any section of departure sells seatClass.
+func hasSeatClass(departure *pb.Departure, seatClass string) bool {
+	for _, section := range departure.Sections {
+		if section.SeatClass == seatClass {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestDefaultPriceIsTwentyDollars(t *testing.T) {
+	server := NewServer()
+
+	receipt, err := server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+		User: &pb.User{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"},
+		From: "London",
+		To:   "France",
+	})
+	require.NoError(t, err, "error purchasing ticket")
+	assert.Equal(t, int32(20), receipt.Price, "expected $20")
+	assert.Equal(t, "USD", receipt.Fare.Currency)
+	assert.Equal(t, int64(2000), receipt.Fare.TotalCents)
+}
+
+func TestPricingRulesQuote(t *testing.T) {
+	rules, err := LoadPricingRules("pricing.json")
+	require.NoError(t, err, "error loading sample pricing rules")
+
+	fare := rules.quote(fareInput{
+		segments:   2,
+		km:         500,
+		seatClass:  "first",
+		passenger:  pb.PassengerType_PASSENGER_TYPE_CHILD,
+		leadDays:   3,
+		loadFactor: 0.75,
+	})
+
+	// base 1500 + 2*500 + 500*8 = 6500; first x1.8 = 11700; late x1.25 =
+	// 14625; busy x1.2 = 17550; child x0.5 = 8775
+	assert.Equal(t, "EUR", fare.Currency)
+	assert.Equal(t, int64(8775), fare.TotalCents)
+	var names []string
+	var sum int64
+	for _, c := range fare.Components {
+		names = append(names, c.Name)
+		sum += c.AmountCents
+	}
+	assert.Equal(t, []string{"base", "class", "lead_time", "demand", "passenger"}, names)
+	assert.Equal(t, fare.TotalCents, sum, "components should add up to the total")
+}
+
+func TestLoadPricingRulesRejectsBadConfig(t *testing.T) {
+	for name, config := range map[string]string{
+		"negative fare":             `{"currency":"EUR","base_fare_cents":-1}`,
+		"zero class multiplier":     `{"currency":"EUR","class_multipliers":{"first":0}}`,
+		"negative passenger":        `{"currency":"EUR","passenger_multipliers":{"child":-0.5}}`,
+		"zero lead time multiplier": `{"currency":"EUR","lead_time":[{"min_days":7,"multiplier":0}]}`,
+		"unsorted lead time":        `{"currency":"EUR","lead_time":[{"min_days":0,"multiplier":1.25},{"min_days":30,"multiplier":0.8}]}`,
+		"overlapping lead time":     `{"currency":"EUR","lead_time":[{"min_days":7,"multiplier":1},{"min_days":7,"multiplier":0.8}]}`,
+		"negative lead time":        `{"currency":"EUR","lead_time":[{"min_days":-1,"multiplier":1}]}`,
+		"negative demand":           `{"currency":"EUR","demand":[{"min_load_factor":0.9,"multiplier":-1.5}]}`,
+		"unsorted demand":           `{"currency":"EUR","demand":[{"min_load_factor":0.7,"multiplier":1.2},{"min_load_factor":0.9,"multiplier":1.5}]}`,
+		"overlapping demand":        `{"currency":"EUR","demand":[{"min_load_factor":0.7,"multiplier":1.2},{"min_load_factor":0.7,"multiplier":1.5}]}`,
+		"load factor above one":     `{"currency":"EUR","demand":[{"min_load_factor":1.5,"multiplier":2}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pricing.json")
+			require.NoError(t, os.WriteFile(path, []byte(config), 0o644))
+			_, err := LoadPricingRules(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestQuoteFareMatchesPurchase(t *testing.T) {
+	rules, err := LoadPricingRules("pricing.json")
+	require.NoError(t, err, "error loading sample pricing rules")
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	server := NewServer(WithPricing(rules), WithClock(func() time.Time { return now }))
+
+	departure, err := server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+		TrainId:     "EUR9200",
+		Date:        "2026-11-15",
+		Origin:      "London",
+		Destination: "Brussels",
+		Via:         []string{"Paris"},
+		SegmentKm:   []int32{450, 300},
+	})
+	require.NoError(t, err, "error creating departure")
+
+	quote, err := server.QuoteFare(context.Background(), &pb.QuoteFareRequest{
+		DepartureId:   departure.Id,
+		From:          "London",
+		To:            "Paris",
+		PassengerType: pb.PassengerType_PASSENGER_TYPE_SENIOR,
+	})
+	require.NoError(t, err, "error quoting fare")
+	// 1500 + 500 + 450*8 = 5600; 45 days ahead x0.8 = 4480; senior x0.7 = 3136
+	assert.Equal(t, int64(3136), quote.TotalCents)
+
+	receipt, err := server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+		User:          &pb.User{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"},
+		From:          "London",
+		To:            "Paris",
+		DepartureId:   departure.Id,
+		PassengerType: pb.PassengerType_PASSENGER_TYPE_SENIOR,
+	})
+	require.NoError(t, err, "error purchasing ticket")
+	assert.Equal(t, quote.TotalCents, receipt.Fare.TotalCents, "purchase should charge the quoted fare")
+	assert.Equal(t, int32(31), receipt.Price)
+
+	_, err = server.QuoteFare(context.Background(), &pb.QuoteFareRequest{
+		DepartureId: departure.Id,
+		From:        "London",
+		To:          "Paris",
+		SeatClass:   "sleeper",
+	})
+	assertCode(t, err, codes.InvalidArgument, "unknown seat class should be rejected")
+}
+
+func TestDemandPricingFollowsLoad(t *testing.T) {
+	server := NewServer(
+		WithLayouts(Layouts{Default: "tiny", ByName: map[string][]*pb.SectionLayout{
+			"tiny": {{Name: "A", Seats: 2, SeatClass: "standard"}},
+		}}),
+		WithPricing(PricingRules{
+			Currency:      "USD",
+			BaseFareCents: 1000,
+			Demand:        []DemandBand{{MinLoadFactor: 0.5, Multiplier: 2}},
+		}),
+	)
+
+	var prices []int64
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		receipt, err := server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+			User: &pb.User{FirstName: "Test", LastName: "User", Email: email},
+			From: "London",
+			To:   "France",
+		})
+		require.NoError(t, err, "error purchasing ticket")
+		prices = append(prices, receipt.Fare.TotalCents)
+	}
+	assert.Equal(t, []int64{1000, 2000}, prices, "the second seat is sold at half load")
+}
+
+func TestMoveRefusesDepartureWithDifferentFare(t *testing.T) {
+	server := NewServer(WithPricing(PricingRules{Currency: "USD", BaseFareCents: 1000, PerKmCents: 10}))
+	newDeparture := func(trainID string, km int32) *pb.Departure {
+		departure, err := server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+			TrainId:     trainID,
+			Date:        "2026-11-15",
+			Origin:      "London",
+			Destination: "Paris",
+			SegmentKm:   []int32{km},
+		})
+		require.NoError(t, err, "error creating departure")
+		return departure
+	}
+	home := newDeparture("EUR9001", 450)
+	same := newDeparture("EUR9002", 450)
+	longer := newDeparture("EUR9003", 500)
+
+	receipt, err := buyLeg(server, home.Id, "john.doe@example.com", "London", "Paris")
+	require.NoError(t, err, "error purchasing ticket")
+	assert.Equal(t, int64(5500), receipt.Fare.TotalCents)
+
+	_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+		BookingReference: receipt.BookingReference, DepartureId: longer.Id, NewSection: "A", AnySeat: true,
+	})
+	assertCode(t, err, codes.FailedPrecondition, "a move onto a dearer departure should be refused")
+
+	moved, err := server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+		BookingReference: receipt.BookingReference, DepartureId: same.Id, NewSection: "A", AnySeat: true,
+	})
+	require.NoError(t, err, "a move at the same fare should be allowed")
+	assert.Equal(t, same.Id, moved.DepartureId)
+	assert.Equal(t, receipt.Fare.TotalCents, moved.Fare.TotalCents)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+)
+
+// leg is the half-open range of route segments [from, to) a ticket covers.
+// Segment i runs from station i to station i+1.
+type leg struct {
+	from, to int
+}
+
+func (l leg) overlaps(other leg) bool {
+	return l.from < other.to && other.from < l.to
+}
+
+// routeStations returns the calling points of a departure. Departures stored
+// before routes existed only know their origin and destination.
+func routeStations(departure *pb.Departure) []string {
+	if len(departure.Stations) > 0 {
+		return departure.Stations
+	}
+	return []string{departure.Origin, departure.Destination}
+}
+
+// wholeRoute is the leg from origin to destination.
+func wholeRoute(departure *pb.Departure) leg {
+	return leg{from: 0, to: len(routeStations(departure)) - 1}
+}
+
+// resolveLeg maps a pair of station names onto the departure's route,
+// rejecting stations the train does not call at and journeys that run
+// against the direction of travel.
+func resolveLeg(departure *pb.Departure, from, to string) (leg, error) {
+	index := make(map[string]int)
+	for i, station := range routeStations(departure) {
+		index[station] = i
+	}
+
+	fromIdx, ok := index[from]
+	if !ok {
+		return leg{}, trainerr.InvalidField("from", "departure %s does not call at %q", departure.Id, from)
+	}
+	toIdx, ok := index[to]
+	if !ok {
+		return leg{}, trainerr.InvalidField("to", "departure %s does not call at %q", departure.Id, to)
+	}
+	if fromIdx >= toIdx {
+		return leg{}, trainerr.InvalidArgument(
+			trainerr.FieldViolation("from", "departure %s runs from %s towards %s, not %s to %s",
+				departure.Id, departure.Origin, departure.Destination, from, to),
+			trainerr.FieldViolation("to", "must be after %s on the route", from))
+	}
+	return leg{from: fromIdx, to: toIdx}, nil
+}
+
+// ticketLeg returns the leg a stored ticket occupies. Tickets whose stations
+// cannot be resolved conservatively block the whole route.
+func ticketLeg(departure *pb.Departure, receipt *pb.TicketReceipt) leg {
+	l, err := resolveLeg(departure, receipt.From, receipt.To)
+	if err != nil {
+		return wholeRoute(departure)
+	}
+	return l
+}
+
+// seatOccupancy indexes the legs each seat in a section is sold for.
+type seatOccupancy map[int32][]leg
+
+// sectionOccupancy builds the occupancy of one section of a departure from
+// both sold tickets and active holds, skipping the ticket with id exclude
+// (if any) so a passenger can be re-seated without colliding with themselves.
+func (s *server) sectionOccupancy(departure *pb.Departure, section, exclude string) (seatOccupancy, error) {
+	occupancy, err := s.soldOccupancy(departure, section, exclude)
+	if err != nil {
+		return nil, err
+	}
+	for seat, legs := range s.heldOccupancy(departure.Id, section, true) {
+		occupancy[seat] = append(occupancy[seat], legs...)
+	}
+	return occupancy, nil
+}
+
+// soldOccupancy is like sectionOccupancy but ignores holds.
+func (s *server) soldOccupancy(departure *pb.Departure, section, exclude string) (seatOccupancy, error) {
+	tickets, err := s.store.SectionTickets(departure.Id, section)
+	if err != nil {
+		return nil, err
+	}
+	occupancy := make(seatOccupancy)
+	for _, receipt := range tickets {
+		if exclude != "" && receipt.TicketId == exclude {
+			continue
+		}
+		seat := receipt.Seat.GetSeat()
+		occupancy[seat] = append(occupancy[seat], ticketLeg(departure, receipt))
+	}
+	return occupancy, nil
+}
+
+// free reports whether seat is unsold on every segment of l.
+func (o seatOccupancy) free(seat int32, l leg) bool {
+	for _, taken := range o[seat] {
+		if taken.overlaps(l) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+// newRouteDeparture creates a one-seat London-Paris-Brussels departure.
+func newRouteDeparture(t *testing.T, server *server) *pb.Departure {
+	departure, err := server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+		TrainId:     "EUR9100",
+		Date:        "2026-11-01",
+		Origin:      "London",
+		Destination: "Brussels",
+		Via:         []string{"Paris"},
+		Sections:    []*pb.SectionLayout{{Name: "A", Seats: 1, SeatClass: "standard"}},
+	})
+	require.NoError(t, err, "error creating departure")
+	return departure
+}
+
+func legRequest(departureID, email, from, to string) *pb.PurchaseTicketRequest {
+	return &pb.PurchaseTicketRequest{
+		User:        &pb.User{FirstName: "Test", LastName: "User", Email: email},
+		From:        from,
+		To:          to,
+		DepartureId: departureID,
+	}
+}
+
+func buyLeg(server *server, departureID, email, from, to string) (*pb.TicketReceipt, error) {
+	return server.PurchaseTicket(context.Background(), legRequest(departureID, email, from, to))
+}
+
+func TestSeatIsReusedOnNonOverlappingLegs(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		departure := newRouteDeparture(t, server)
+		assert.Equal(t, []string{"London", "Paris", "Brussels"}, departure.Stations)
+
+		first, err := buyLeg(server, departure.Id, "a@example.com", "London", "Paris")
+		require.NoError(t, err, "error buying London-Paris")
+		second, err := buyLeg(server, departure.Id, "b@example.com", "Paris", "Brussels")
+		require.NoError(t, err, "error buying Paris-Brussels")
+		assert.Equal(t, first.Seat.Seat, second.Seat.Seat, "the same seat should be sold on both legs")
+
+		_, err = buyLeg(server, departure.Id, "c@example.com", "London", "Brussels")
+		assertCode(t, err, codes.ResourceExhausted, "the only seat is taken on both legs")
+	})
+}
+
+func TestOverlappingLegsDoNotShareSeat(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		departure := newRouteDeparture(t, server)
+
+		_, err := buyLeg(server, departure.Id, "a@example.com", "London", "Brussels")
+		require.NoError(t, err, "error buying London-Brussels")
+		_, err = buyLeg(server, departure.Id, "b@example.com", "Paris", "Brussels")
+		assertCode(t, err, codes.ResourceExhausted, "Paris-Brussels overlaps the sold leg")
+	})
+}
+
+func TestPurchaseTicketRejectsBadStations(t *testing.T) {
+	server := NewServer()
+	departure := newRouteDeparture(t, server)
+
+	_, err := buyLeg(server, departure.Id, "a@example.com", "London", "Amsterdam")
+	assertCode(t, err, codes.InvalidArgument, "unknown station should be rejected")
+
+	_, err = buyLeg(server, departure.Id, "a@example.com", "Brussels", "Paris")
+	assertCode(t, err, codes.InvalidArgument, "reversed direction should be rejected")
+
+	_, err = buyLeg(server, departure.Id, "a@example.com", "Paris", "Paris")
+	assertCode(t, err, codes.InvalidArgument, "empty journey should be rejected")
+}
+
+func TestSeatMapForLeg(t *testing.T) {
+	server := NewServer()
+	departure := newRouteDeparture(t, server)
+
+	_, err := buyLeg(server, departure.Id, "a@example.com", "London", "Paris")
+	require.NoError(t, err, "error buying London-Paris")
+
+	seatMap, err := server.GetSeatMap(context.Background(), &pb.SeatMapRequest{DepartureId: departure.Id})
+	require.NoError(t, err, "error fetching seat map")
+	assert.Equal(t, pb.SeatState_SEAT_STATE_SOLD, seatMap.Sections[0].Seats[0].State, "seat is sold on part of the route")
+
+	seatMap, err = server.GetSeatMap(context.Background(), &pb.SeatMapRequest{DepartureId: departure.Id, From: "Paris", To: "Brussels"})
+	require.NoError(t, err, "error fetching seat map")
+	assert.Equal(t, pb.SeatState_SEAT_STATE_FREE, seatMap.Sections[0].Seats[0].State, "seat is free from Paris")
+}
+
+func TestMoveRejectsDepartureNotServingStations(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("john.doe@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+		departure := newRouteDeparture(t, server)
+
+		_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+			BookingReference: receipt.BookingReference,
+			DepartureId:      departure.Id,
+			NewSection:       "A",
+			AnySeat:          true,
+		})
+		assertCode(t, err, codes.InvalidArgument, "a departure that does not call at London and France should be refused")
+
+		current, err := server.GetReceipt(context.Background(), &pb.UserRequest{BookingReference: receipt.BookingReference})
+		require.NoError(t, err, "error fetching receipt")
+		assert.Equal(t, receipt.DepartureId, current.DepartureId, "the ticket should not move")
+		assert.Equal(t, int64(1), current.Version)
+
+		_, err = buyLeg(server, departure.Id, "jane.smith@example.com", "London", "Brussels")
+		assert.NoError(t, err, "the refused move should not take a seat")
+	})
+}
@@ -2,147 +2,615 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net"
-	"sync"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"test_train/auth"
 	pb "test_train/protobuf"
+	"test_train/trainerr"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/protobuf/proto"
 )
 
 type server struct {
 	pb.UnimplementedTrainServiceServer
-	mu       sync.Mutex
-	tickets  map[string]*pb.TicketReceipt
-	sections map[string]map[string]*pb.SeatAllocation
-	userData map[string]*pb.User // Track user data by email
+	store          Store
+	layouts        Layouts
+	pricing        PricingRules
+	now            func() time.Time
+	holdTTL        time.Duration
+	payment        PaymentProvider
+	paymentTimeout time.Duration
+	refunds        RefundPolicy
+	notifier       Notifier
+
+	// states holds each departure's lock and in-memory state; see locks.go
+	states *departureStates
+
+	allocator Allocator
+	feed      *availabilityFeed
+	auth      *auth.Authenticator
+
+	idempotency *idempotencyCache
 }
 
-func NewServer() *server {
-	return &server{
-		tickets: make(map[string]*pb.TicketReceipt),
-		sections: map[string]map[string]*pb.SeatAllocation{
-			"A": make(map[string]*pb.SeatAllocation),
-			"B": make(map[string]*pb.SeatAllocation),
-		},
-		userData: make(map[string]*pb.User),
+// Option configures optional server behaviour.
+type Option func(*server)
+
+// WithLayouts sets the section layouts departures are created from.
+func WithLayouts(layouts Layouts) Option {
+	return func(s *server) {
+		s.layouts = layouts
 	}
 }
 
-func (s *server) PurchaseTicket(ctx context.Context, req *pb.PurchaseTicketRequest) (*pb.TicketReceipt, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// WithPricing sets the rules used to price tickets.
+func WithPricing(rules PricingRules) Option {
+	return func(s *server) {
+		s.pricing = rules
+	}
+}
+
+// WithClock overrides the server's source of the current time.
+func WithClock(now func() time.Time) Option {
+	return func(s *server) {
+		s.now = now
+	}
+}
 
-	// Assign section and seat number assuming  each section can contain 50  passengers
-	section := "A"
-	seat := len(s.sections[section]) + 1
-	if seat > 50 {
-		section = "B"
-		seat = len(s.sections[section]) + 1
-		if seat > 100 {
-			return nil, fmt.Errorf("Train is full")
+// NewServer returns a server backed by an in-memory store.
+func NewServer(opts ...Option) *server {
+	s, err := NewServerWithStore(NewMemoryStore(), opts...)
+	if err != nil {
+		// The memory store cannot fail
+		panic(err)
+	}
+	return s
+}
+
+// NewServerWithStore returns a server that keeps its bookings in store,
+// creating the default departure if the store does not have one yet.
+func NewServerWithStore(store Store, opts ...Option) (*server, error) {
+	s := &server{
+		store:          store,
+		layouts:        defaultLayouts(),
+		pricing:        defaultPricingRules(),
+		now:            time.Now,
+		holdTTL:        defaultHoldTTL,
+		payment:        NewFakeGateway(FakeApprove),
+		paymentTimeout: defaultPaymentTimeout,
+		refunds:        defaultRefundPolicy(),
+		notifier:       logNotifier{},
+		states:         newDepartureStates(),
+		allocator:      NewPreferenceAllocator(),
+		feed:           newAvailabilityFeed(),
+		idempotency:    newIdempotencyCache(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if _, err := store.GetDeparture(defaultDepartureID); errors.Is(err, ErrNotFound) {
+		departure := defaultDeparture(s.layouts.ByName[s.layouts.Default])
+		if err := s.record(context.Background(), departureEvent(pb.BookingEventType_BOOKING_EVENT_TYPE_DEPARTURE_CREATED, departure)); err != nil {
+			return nil, err
+		}
+		if err := store.PutDeparture(departure); err != nil {
+			return nil, err
 		}
+	} else if err != nil {
+		return nil, err
 	}
+	return s, nil
+}
 
-	// Create ticket receipt
-	receipt := &pb.TicketReceipt{
-		User:  req.User,
-		From:  req.From,
-		To:    req.To,
-		Price: 20,
-		Seat: &pb.SeatAllocation{
-			Section: section,
-			Seat:    int32(seat),
-		},
+func (s *server) PurchaseTicket(ctx context.Context, req *pb.PurchaseTicketRequest) (*pb.TicketReceipt, error) {
+	if err := authorizeUser(ctx, req.User.GetEmail(), true); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeCompanion(ctx, req.Preferences); err != nil {
+		return nil, err
 	}
 
-	// Save ticket and user data
-	s.tickets[req.User.Email] = receipt
-	s.sections[section][req.User.Email] = receipt.Seat
-	s.userData[req.User.Email] = req.User
+	hold, err := s.reserveForPurchase(req)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := s.purchaseHold(ctx, hold, req.PaymentToken)
+	if err != nil {
+		return nil, err
+	}
 
 	log.Printf("Ticket purchased: %+v", receipt)
 	return receipt, nil
 }
 
-func (s *server) GetReceipt(ctx context.Context, req *pb.UserRequest) (*pb.TicketReceipt, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// allocatedSeat is a seat chosen for a journey together with its fare.
+type allocatedSeat struct {
+	departureID string
+	seat        *pb.SeatAllocation
+	fare        *pb.FareBreakdown
+}
+
+// allocate picks and prices a seat for req without reserving it.
+func (s *server) allocate(req *pb.PurchaseTicketRequest) (*allocatedSeat, error) {
+	if req.User == nil {
+		return nil, trainerr.InvalidField("user", "is required")
+	}
+	departure, err := s.bookableDeparture(req.DepartureId)
+	if err != nil {
+		return nil, err
+	}
+
+	journey, err := resolveLeg(departure, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	seats, err := s.allocateSeats(departure, journey, 1, req.Preferences)
+	if err != nil {
+		return nil, err
+	}
+	seat := seats[0]
 
-	receipt, ok := s.tickets[req.Email]
-	if !ok {
-		return nil, fmt.Errorf("user not found")
+	layout := findSection(departure, seat.Section)
+	in, err := s.fareInput(departure, journey, layout.SeatClass, req.PassengerType)
+	if err != nil {
+		return nil, err
 	}
 
+	return &allocatedSeat{departureID: departure.Id, seat: seat, fare: s.pricing.quote(in)}, nil
+}
+
+// reserveForPurchase reserves a seat for req while it is paid for.
+func (s *server) reserveForPurchase(req *pb.PurchaseTicketRequest) (*seatHold, error) {
+	defer s.lockDepartures(req.DepartureId)()
+	hold, err := s.reserveSeat(req)
+	if err != nil {
+		return nil, err
+	}
+	hold.reservation, hold.charging = true, true
+	return hold, nil
+}
+
+// newReceipt numbers an unpaid ticket for an allocated seat.
+func (s *server) newReceipt(req *pb.PurchaseTicketRequest, seat *allocatedSeat) (*pb.TicketReceipt, error) {
+	reference, err := s.newBookingReference()
+	if err != nil {
+		return nil, err
+	}
+	ticketID, err := s.newTicketID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.TicketReceipt{
+		User:             req.User,
+		From:             req.From,
+		To:               req.To,
+		Price:            wholeUnits(seat.fare.TotalCents),
+		Seat:             seat.seat,
+		DepartureId:      seat.departureID,
+		BookingReference: reference,
+		TicketId:         ticketID,
+		Fare:             seat.fare,
+		PassengerType:    req.PassengerType,
+		Version:          1,
+	}, nil
+}
+
+// issueTicket stores and records a paid ticket, refunding its payment if
+// the ticket cannot be stored or recorded or its departure was cancelled
+// during the charge. The caller holds the departure's lock.
+func (s *server) issueTicket(ctx context.Context, receipt *pb.TicketReceipt) error {
+	_, err := s.bookableDeparture(receipt.DepartureId)
+	if err == nil {
+		err = s.store.PutTicket(receipt)
+		if err == nil {
+			if err = s.record(ctx, purchaseEvent(receipt)); err != nil {
+				s.unstoreTickets(receipt)
+			}
+		}
+	}
+	if err != nil {
+		// refundPayment logs its own failure for reconciliation
+		s.refundPayment(ctx, receipt.Payment, receipt.Payment.AmountCents, receipt.TicketId+"/unbooked")
+		return err
+	}
+	return nil
+}
+
+func (s *server) GetReceipt(ctx context.Context, req *pb.UserRequest) (*pb.TicketReceipt, error) {
+	receipt, err := s.findTicket(req.BookingReference, req.Email, canRead(ctx))
+	if err != nil {
+		return nil, err
+	}
 	return receipt, nil
 }
 
 func (s *server) GetUsersBySection(ctx context.Context, req *pb.SectionRequest) (*pb.UsersResponse, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	departure, err := s.departure(req.DepartureId)
+	if err != nil {
+		return nil, err
+	}
 
-	users := []*pb.User{}
-	for email, _ := range s.sections[req.Section] {
-		// Retrieve the user using their email
-		user, exists := s.userData[email]
-		if exists {
-			users = append(users, &pb.User{
-				FirstName: user.FirstName,
-				LastName:  user.LastName,
-				Email:     user.Email,
-			})
-		}
+	users, err := s.store.UsersBySection(departure.Id, req.Section)
+	if err != nil {
+		return nil, err
 	}
 
 	return &pb.UsersResponse{Users: users}, nil
 }
 
 func (s *server) RemoveUser(ctx context.Context, req *pb.UserRequest) (*pb.EmptyResponse, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	receipt, ok := s.tickets[req.Email]
-	if !ok {
-		return nil, fmt.Errorf("user not found")
+	receipt, unlock, err := s.lockTicket(req.BookingReference, req.Email, canRead(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := checkVersion(receipt, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if _, err := s.cancelTicket(ctx, receipt, "removed"); err != nil {
+		return nil, err
 	}
-
-	delete(s.tickets, req.Email)
-	delete(s.sections[receipt.Seat.Section], req.Email)
 
 	return &pb.EmptyResponse{}, nil
 }
 
 func (s *server) ModifyUserSeat(ctx context.Context, req *pb.ModifySeatRequest) (*pb.TicketReceipt, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	receipt, ok := s.tickets[req.Email]
-	if !ok {
-		return nil, fmt.Errorf("user not found")
+	// Moving to another departure locks it as well as the ticket's own
+	var others []string
+	if req.DepartureId != "" {
+		others = append(others, req.DepartureId)
+	}
+	receipt, unlock, err := s.lockTicket(req.BookingReference, req.Email, canRead(ctx), others...)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := authorizeUser(ctx, receipt.User.GetEmail(), true); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(receipt, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if err := checkNotCancelling(receipt); err != nil {
+		return nil, err
 	}
 
-	delete(s.sections[receipt.Seat.Section], req.Email)
+	departureID := req.DepartureId
+	if departureID == "" {
+		departureID = receipt.DepartureId
+	}
+	departure, err := s.bookableDeparture(departureID)
+	if err != nil {
+		return nil, err
+	}
+	journey, err := resolveLeg(departure, receipt.From, receipt.To)
+	if err != nil {
+		return nil, trainerr.InvalidField("departure_id", "departure %s does not run from %s to %s", departure.Id, receipt.From, receipt.To)
+	}
+	seat, err := s.freeSeat(departure, journey, receipt, req)
+	if err != nil {
+		return nil, err
+	}
+	if departure.Id != receipt.DepartureId {
+		if err := s.checkSameFare(departure, journey, receipt, req.NewSection); err != nil {
+			return nil, err
+		}
+	}
 
+	before := proto.Clone(receipt).(*pb.TicketReceipt)
+	receipt.DepartureId = departure.Id
 	receipt.Seat.Section = req.NewSection
-	receipt.Seat.Seat = req.NewSeat
-	s.sections[req.NewSection][req.Email] = receipt.Seat
+	receipt.Seat.Seat = seat
+	receipt.Version++
+	if err := s.store.PutTicket(receipt); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, seatChangeEvent(before, receipt, "seat_changed")); err != nil {
+		s.restoreTickets(before)
+		return nil, err
+	}
+	s.seatChanged(before.DepartureId, before.Seat, "seat_changed")
+	s.seatChanged(receipt.DepartureId, receipt.Seat, "seat_changed")
 
 	return receipt, nil
 }
 
+// freeSeat checks that the seat requested by req is free for journey,
+// ignoring the current seat of receipt, or with AnySeat picks the lowest such
+// seat in the section.
+func (s *server) freeSeat(departure *pb.Departure, journey leg, receipt *pb.TicketReceipt, req *pb.ModifySeatRequest) (int32, error) {
+	section := findSection(departure, req.NewSection)
+	if section == nil {
+		return 0, trainerr.InvalidField("new_section", "section %s does not exist on departure %s", req.NewSection, departure.Id)
+	}
+	sold, err := s.soldOccupancy(departure, section.Name, receipt.TicketId)
+	if err != nil {
+		return 0, err
+	}
+	held := s.heldOccupancy(departure.Id, section.Name, true)
+
+	if req.AnySeat {
+		for n := int32(1); n <= section.Seats; n++ {
+			if sold.free(n, journey) && held.free(n, journey) {
+				return n, nil
+			}
+		}
+		return 0, trainerr.ResourceExhausted(trainerr.ResourceDeparture, departure.Id, 0, "section %s has no free seat for this journey", section.Name)
+	}
+
+	if err := validateSeat(departure, "new_section", req.NewSection, "new_seat", req.NewSeat); err != nil {
+		return 0, err
+	}
+	seat := fmt.Sprintf("%s%d", req.NewSection, req.NewSeat)
+	if !sold.free(req.NewSeat, journey) {
+		return 0, trainerr.AlreadyExists(trainerr.ResourceSeat, seat, "seat %s is already taken", seat)
+	}
+	if !held.free(req.NewSeat, journey) {
+		return 0, trainerr.AlreadyExists(trainerr.ResourceSeat, seat, "seat %s is held for another customer", seat)
+	}
+	return req.NewSeat, nil
+}
+
+// checkSameFare refuses to move receipt onto departure unless its journey
+// there, in the class of section, costs what was paid. Moves do not charge or
+// refund the difference; the passenger cancels and books again instead.
+func (s *server) checkSameFare(departure *pb.Departure, journey leg, receipt *pb.TicketReceipt, section string) error {
+	in, err := s.fareInput(departure, journey, findSection(departure, section).SeatClass, receipt.PassengerType)
+	if err != nil {
+		return err
+	}
+	fare := s.pricing.quote(in)
+	paid := receipt.Fare.GetTotalCents()
+	if receipt.Fare == nil {
+		// Tickets sold before fares were itemised only carry a whole price
+		paid = int64(receipt.Price) * 100
+	}
+	if fare.TotalCents != paid {
+		return trainerr.FailedPrecondition("FARE_CHANGED", departure.Id,
+			"the fare on departure %s is %d cents, not the %d cents paid; cancel and book again instead", departure.Id, fare.TotalCents, paid)
+	}
+	return nil
+}
+
+// openStore builds the Store selected by the -store flag.
+func openStore(kind, dir string, snapshotEvery int) (Store, error) {
+	switch kind {
+	case "memory":
+		return NewMemoryStore(), nil
+	case "file":
+		return OpenFileStore(dir, snapshotEvery)
+	default:
+		return nil, fmt.Errorf("unknown store %q", kind)
+	}
+}
+
+// keepalivePolicy lets clients ping idle connections every 20 seconds to
+// detect dead ones; trainclient pings every 30. gRPC's default of five
+// minutes would close their connections as abusive.
+var keepalivePolicy = keepalive.EnforcementPolicy{MinTime: 20 * time.Second, PermitWithoutStream: true}
+
+// newGRPCServer wraps trainServer in a gRPC server with the service's
+// interceptors installed.
+func newGRPCServer(trainServer *server, opts ...grpc.ServerOption) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{trainerr.UnaryServerInterceptor()}
+	stream := []grpc.StreamServerInterceptor{trainerr.StreamServerInterceptor()}
+	// Authenticate before validating so anonymous callers learn nothing
+	// about the data from validation errors
+	if trainServer.auth != nil {
+		unary = append(unary, trainServer.auth.UnaryServerInterceptor())
+		stream = append(stream, trainServer.auth.StreamServerInterceptor())
+	}
+	// Replay only requests that passed validation, for the caller who made
+	// them
+	unary = append(unary, trainServer.validationInterceptor(), trainServer.idempotencyInterceptor())
+	stream = append(stream, trainServer.validationStreamInterceptor())
+
+	opts = append([]grpc.ServerOption{grpc.KeepaliveEnforcementPolicy(keepalivePolicy)}, opts...)
+	opts = append(opts, grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterTrainServiceServer(grpcServer, trainServer)
+	return grpcServer
+}
+
 func main() {
+	storeKind := flag.String("store", "memory", "booking store: memory or file")
+	dataDir := flag.String("data-dir", "data", "directory for the file store")
+	snapshotEvery := flag.Int("snapshot-every", 100, "journal entries between file store snapshots")
+	layoutsPath := flag.String("layouts", "", "JSON file of coach and seat layouts")
+	pricingPath := flag.String("pricing", "", "JSON file of fare rules")
+	holdTTL := flag.Duration("hold-ttl", defaultHoldTTL, "how long HoldSeat reserves a seat")
+	fakePayment := flag.String("fake-payment", string(FakeApprove), "fake payment gateway mode: approve, decline or timeout")
+	paymentTimeout := flag.Duration("payment-timeout", defaultPaymentTimeout, "deadline for each payment provider call")
+	refundFullHours := flag.Int("refund-full-hours", defaultRefundPolicy().FullRefundHours, "hours before departure up to which cancellations are refunded in full")
+	refundPartialPercent := flag.Int("refund-partial-percent", defaultRefundPolicy().PartialRefundPercent, "percentage refunded for later cancellations before departure")
+	allocatorName := flag.String("allocator", "preference", "seat allocation strategy: preference or first-free")
+	rebuildLedger := flag.Bool("rebuild-from-ledger", false, "rebuild departures, tickets and cancellations by replaying the booking ledger at startup")
+	authKeys := flag.String("auth-keys", "", "JSON key set for verifying bearer tokens; empty disables authentication")
+	tlsCert := flag.String("tls-cert", "", "PEM server certificate; empty serves without TLS")
+	tlsKey := flag.String("tls-key", "", "PEM private key of -tls-cert")
+	tlsClientCA := flag.String("tls-client-ca", "", "PEM CA bundle for verifying client certificates, which then authenticate callers")
+	httpAddr := flag.String("http-addr", ":8112", "address for the HTTP/JSON gateway; empty disables it")
+	openAPIPath := flag.String("openapi", "", "write the gateway's OpenAPI document to this file and exit")
+	idempotencyWindow := flag.Duration("idempotency-window", defaultIdempotencyWindow, "how long responses to changes are kept for replay to retries with the same idempotency key; 0 ignores keys")
+	idempotencyLimit := flag.Int("idempotency-limit", defaultIdempotencyLimit, "most responses kept for replay; the least recently used are dropped first")
+	tlsRequireClientCert := flag.Bool("tls-require-client-cert", false, "reject connections without a client certificate (mutual TLS)")
+	flag.Parse()
+
+	if *openAPIPath != "" {
+		doc, err := openAPIDocument()
+		if err != nil {
+			log.Fatalf("failed to generate OpenAPI document: %v", err)
+		}
+		if err := os.WriteFile(*openAPIPath, append(doc, '\n'), 0o644); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	switch mode := FakeGatewayMode(*fakePayment); mode {
+	case FakeApprove, FakeDecline, FakeTimeout:
+	default:
+		log.Fatalf("unknown fake payment mode %q", mode)
+	}
+
+	if *refundFullHours < 0 || *refundPartialPercent < 0 || *refundPartialPercent > 100 {
+		log.Fatalf("refund hours must be non-negative and the partial refund between 0 and 100 percent")
+	}
+
+	if *idempotencyLimit < 1 {
+		log.Fatalf("the idempotency limit must be at least 1")
+	}
+
+	allocator, err := parseAllocator(*allocatorName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := []Option{
+		WithAllocator(allocator),
+		WithHoldTTL(*holdTTL),
+		WithPaymentProvider(NewFakeGateway(FakeGatewayMode(*fakePayment)), *paymentTimeout),
+		WithRefundPolicy(RefundPolicy{FullRefundHours: *refundFullHours, PartialRefundPercent: *refundPartialPercent}),
+		WithIdempotencyWindow(*idempotencyWindow),
+		WithIdempotencyLimit(*idempotencyLimit),
+	}
+	if *layoutsPath != "" {
+		layouts, err := LoadLayouts(*layoutsPath)
+		if err != nil {
+			log.Fatalf("failed to load layouts: %v", err)
+		}
+		opts = append(opts, WithLayouts(layouts))
+	}
+	if *pricingPath != "" {
+		rules, err := LoadPricingRules(*pricingPath)
+		if err != nil {
+			log.Fatalf("failed to load pricing rules: %v", err)
+		}
+		opts = append(opts, WithPricing(rules))
+	}
+	if *authKeys != "" {
+		keys, err := auth.LoadKeySet(*authKeys)
+		if err != nil {
+			log.Fatalf("failed to load auth keys: %v", err)
+		}
+		opts = append(opts, WithKeySet(keys))
+	}
+	var serverOpts []grpc.ServerOption
+	var reloader *auth.CertReloader
+	if *tlsCert != "" {
+		reloader, err = auth.NewCertReloader(*tlsCert, *tlsKey, *tlsClientCA)
+		if err != nil {
+			log.Fatalf("failed to load TLS certificates: %v", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(reloader.ServerConfig(*tlsRequireClientCert))))
+		if *tlsClientCA != "" {
+			opts = append(opts, WithClientCertIdentity())
+		}
+	} else if *tlsClientCA != "" || *tlsRequireClientCert {
+		log.Fatalf("client certificates require -tls-cert and -tls-key")
+	}
+
+	store, err := openStore(*storeKind, *dataDir, *snapshotEvery)
+	if err != nil {
+		log.Fatalf("failed to open store: %v", err)
+	}
+	if *rebuildLedger {
+		rebuilder, ok := store.(interface{ Rebuild() error })
+		if !ok {
+			log.Fatalf("store %s cannot be rebuilt", *storeKind)
+		}
+		if err := rebuilder.Rebuild(); err != nil {
+			log.Fatalf("failed to rebuild from ledger: %v", err)
+		}
+	}
+
 	listener, err := net.Listen("tcp", ":8111")
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
-	pb.RegisterTrainServiceServer(grpcServer, NewServer())
+	trainServer, err := NewServerWithStore(store, opts...)
+	if err != nil {
+		log.Fatalf("failed to initialise server: %v", err)
+	}
+
+	grpcServer := newGRPCServer(trainServer, serverOpts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go trainServer.runHoldReaper(ctx, time.Second)
+
+	var httpServer *http.Server
+	if *httpAddr != "" {
+		handler, stopGateway, err := newGateway(trainServer)
+		if err != nil {
+			log.Fatalf("failed to start HTTP gateway: %v", err)
+		}
+		defer stopGateway()
+		httpServer = &http.Server{Addr: *httpAddr, Handler: handler}
+		if reloader != nil {
+			httpServer.TLSConfig = httpTLSConfig(reloader.ServerConfig(*tlsRequireClientCert))
+		}
+		go func() {
+			log.Printf("HTTP gateway is listening on %s", *httpAddr)
+			var err error
+			if reloader != nil {
+				err = httpServer.ListenAndServeTLS("", "")
+			} else {
+				err = httpServer.ListenAndServe()
+			}
+			if !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("failed to serve HTTP: %v", err)
+			}
+		}()
+	}
+
+	// Stop cleanly on SIGINT/SIGTERM so the store can flush a final snapshot
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		if httpServer != nil {
+			httpServer.Shutdown(context.Background())
+		}
+		grpcServer.GracefulStop()
+	}()
 
 	log.Println("Server is listening on port 8111")
 	if err := grpcServer.Serve(listener); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
+	if err := store.Close(); err != nil {
+		log.Fatalf("failed to close store: %v", err)
+	}
+}
+
+// httpTLSConfig adapts a gRPC server TLS configuration for the HTTP
+// gateway, which also speaks HTTP/1.1.
+func httpTLSConfig(config *tls.Config) *tls.Config {
+	getConfig := config.GetConfigForClient
+	config.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		conn, err := getConfig(hello)
+		if conn != nil {
+			conn.NextProtos = []string{"h2", "http/1.1"}
+		}
+		return conn, err
+	}
+	return config
 }
@@ -2,136 +2,239 @@ package main
 
 import (
 	"context"
+	"net"
 	"testing"
 
 	pb "test_train/protobuf"
+	"test_train/trainerr"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
-func TestPurchaseTicket(t *testing.T) {
-	server := NewServer()
-
-	req := &pb.PurchaseTicketRequest{
-		User: &pb.User{
-			FirstName: "John",
-			LastName:  "Doe",
-			Email:     "john.doe@example.com",
-		},
-		From: "London",
-		To:   "France",
+// assertCode checks that err is a gRPC status with the expected code.
+func assertCode(t *testing.T, err error, code codes.Code, msgAndArgs ...interface{}) bool {
+	t.Helper()
+	if !assert.Error(t, err, msgAndArgs...) {
+		return false
 	}
+	return assert.Equal(t, code, status.Code(err), msgAndArgs...)
+}
 
-	receipt, err := server.PurchaseTicket(context.Background(), req)
-	assert.NoError(t, err, "error purchasing ticket")
-	assert.NotNil(t, receipt, "receipt should not be nil")
-	assert.Equal(t, "A", receipt.Seat.Section, "expected section A")
-	assert.Equal(t, int32(1), receipt.Seat.Seat, "expected seat 1")
-	assert.Equal(t, req.User.Email, receipt.User.Email, "user email should match")
+// forEachStore runs fn once per Store backend with a fresh server.
+func forEachStore(t *testing.T, fn func(t *testing.T, server *server)) {
+	t.Run("memory", func(t *testing.T) {
+		fn(t, NewServer())
+	})
+	t.Run("file", func(t *testing.T) {
+		store, err := OpenFileStore(t.TempDir(), 4)
+		if !assert.NoError(t, err, "error opening file store") {
+			return
+		}
+		defer store.Close()
+		server, err := NewServerWithStore(store)
+		if !assert.NoError(t, err, "error creating server") {
+			return
+		}
+		fn(t, server)
+	})
 }
 
-func TestGetReceipt(t *testing.T) {
-	server := NewServer()
+// dialTestServer serves server over an in-memory listener, as main does over
+// TCP, and returns a client connected to it.
+func dialTestServer(t *testing.T, server *server, opts ...grpc.ServerOption) pb.TrainServiceClient {
+	t.Helper()
+	return dialListener(t, serveTestServer(t, server, opts...), insecure.NewCredentials())
+}
 
-	// Add a ticket
-	req := &pb.PurchaseTicketRequest{
-		User: &pb.User{
-			FirstName: "John",
-			LastName:  "Doe",
-			Email:     "john.doe@example.com",
-		},
-		From: "London",
-		To:   "France",
-	}
-	server.PurchaseTicket(context.Background(), req)
-
-	// Fetch the receipt
-	userReq := &pb.UserRequest{Email: "john.doe@example.com"}
-	receipt, err := server.GetReceipt(context.Background(), userReq)
-	assert.NoError(t, err, "error fetching receipt")
-	assert.NotNil(t, receipt, "receipt should not be nil")
-	assert.Equal(t, req.User.Email, receipt.User.Email, "user email should match")
+// serveTestServer serves server on a new in-memory listener until the test
+// ends.
+func serveTestServer(t *testing.T, server *server, opts ...grpc.ServerOption) *bufconn.Listener {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := newGRPCServer(server, opts...)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	return listener
 }
 
-func TestGetUsersBySection(t *testing.T) {
-	server := NewServer()
-
-	// Add users to sections
-	server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
-		User: &pb.User{
-			FirstName: "John",
-			LastName:  "Doe",
-			Email:     "john.doe@example.com",
-		},
-		From: "London",
-		To:   "France",
+// dialListener returns a client connected to listener with creds.
+func dialListener(t *testing.T, listener *bufconn.Listener, creds credentials.TransportCredentials) pb.TrainServiceClient {
+	t.Helper()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(creds),
+	)
+	require.NoError(t, err, "error dialing test server")
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewTrainServiceClient(conn)
+}
+
+func TestPurchaseTicket(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		req := &pb.PurchaseTicketRequest{
+			User: &pb.User{
+				FirstName: "John",
+				LastName:  "Doe",
+				Email:     "john.doe@example.com",
+			},
+			From: "London",
+			To:   "France",
+		}
+
+		receipt, err := server.PurchaseTicket(context.Background(), req)
+		assert.NoError(t, err, "error purchasing ticket")
+		assert.NotNil(t, receipt, "receipt should not be nil")
+		assert.Equal(t, "A", receipt.Seat.Section, "expected section A")
+		assert.Equal(t, int32(1), receipt.Seat.Seat, "expected seat 1")
+		assert.Equal(t, req.User.Email, receipt.User.Email, "user email should match")
 	})
-	server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
-		User: &pb.User{
-			FirstName: "Jane",
-			LastName:  "Smith",
-			Email:     "jane.smith@example.com",
-		},
-		From: "London",
-		To:   "France",
+}
+
+func TestGetReceipt(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		// Add a ticket
+		req := &pb.PurchaseTicketRequest{
+			User: &pb.User{
+				FirstName: "John",
+				LastName:  "Doe",
+				Email:     "john.doe@example.com",
+			},
+			From: "London",
+			To:   "France",
+		}
+		server.PurchaseTicket(context.Background(), req)
+
+		// Fetch the receipt
+		userReq := &pb.UserRequest{Email: "john.doe@example.com"}
+		receipt, err := server.GetReceipt(context.Background(), userReq)
+		assert.NoError(t, err, "error fetching receipt")
+		assert.NotNil(t, receipt, "receipt should not be nil")
+		assert.Equal(t, req.User.Email, receipt.User.Email, "user email should match")
 	})
+}
 
-	// Fetch users in Section A
-	sectionReq := &pb.SectionRequest{Section: "A"}
-	resp, err := server.GetUsersBySection(context.Background(), sectionReq)
-	assert.NoError(t, err, "error fetching users by section")
-	assert.Len(t, resp.Users, 2, "expected 2 users in section A")
+func TestGetUsersBySection(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		// Add users to sections
+		server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+			User: &pb.User{
+				FirstName: "John",
+				LastName:  "Doe",
+				Email:     "john.doe@example.com",
+			},
+			From: "London",
+			To:   "France",
+		})
+		server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+			User: &pb.User{
+				FirstName: "Jane",
+				LastName:  "Smith",
+				Email:     "jane.smith@example.com",
+			},
+			From: "London",
+			To:   "France",
+		})
+
+		// Fetch users in Section A
+		sectionReq := &pb.SectionRequest{Section: "A"}
+		resp, err := server.GetUsersBySection(context.Background(), sectionReq)
+		assert.NoError(t, err, "error fetching users by section")
+		assert.Len(t, resp.Users, 2, "expected 2 users in section A")
+	})
 }
 
 func TestRemoveUser(t *testing.T) {
-	server := NewServer()
-
-	// Add a user
-	req := &pb.PurchaseTicketRequest{
-		User: &pb.User{
-			FirstName: "John",
-			LastName:  "Doe",
-			Email:     "john.doe@example.com",
-		},
-		From: "London",
-		To:   "France",
-	}
-	server.PurchaseTicket(context.Background(), req)
-
-	// Remove the user
-	userReq := &pb.UserRequest{Email: "john.doe@example.com"}
-	_, err := server.RemoveUser(context.Background(), userReq)
-	assert.NoError(t, err, "error removing user")
-
-	// Verify the user is removed
-	_, err = server.GetReceipt(context.Background(), userReq)
-	assert.Error(t, err, "user should not exist after removal")
+	forEachStore(t, func(t *testing.T, server *server) {
+		// Add a user
+		req := &pb.PurchaseTicketRequest{
+			User: &pb.User{
+				FirstName: "John",
+				LastName:  "Doe",
+				Email:     "john.doe@example.com",
+			},
+			From: "London",
+			To:   "France",
+		}
+		server.PurchaseTicket(context.Background(), req)
+
+		// Remove the user
+		userReq := &pb.UserRequest{Email: "john.doe@example.com"}
+		_, err := server.RemoveUser(context.Background(), userReq)
+		assert.NoError(t, err, "error removing user")
+
+		// Verify the user is removed
+		_, err = server.GetReceipt(context.Background(), userReq)
+		assertCode(t, err, codes.NotFound, "user should not exist after removal")
+	})
 }
 
 func TestModifyUserSeat(t *testing.T) {
-	server := NewServer()
+	forEachStore(t, func(t *testing.T, server *server) {
+		// Add a user
+		req := &pb.PurchaseTicketRequest{
+			User: &pb.User{
+				FirstName: "John",
+				LastName:  "Doe",
+				Email:     "john.doe@example.com",
+			},
+			From: "London",
+			To:   "France",
+		}
+		server.PurchaseTicket(context.Background(), req)
+
+		// Modify the user's seat
+		modifyReq := &pb.ModifySeatRequest{
+			Email:      "john.doe@example.com",
+			NewSection: "B",
+			NewSeat:    5,
+		}
+		receipt, err := server.ModifyUserSeat(context.Background(), modifyReq)
+		assert.NoError(t, err, "error modifying user seat")
+		assert.Equal(t, "B", receipt.Seat.Section, "expected section B")
+		assert.Equal(t, int32(5), receipt.Seat.Seat, "expected seat 5")
+	})
+}
+
+func TestErrorsCarryDetails(t *testing.T) {
+	server := NewServer(WithLayouts(Layouts{
+		Default: "single",
+		ByName:  map[string][]*pb.SectionLayout{"single": {{Name: "A", Seats: 1, SeatClass: "standard"}}},
+	}))
+
+	_, err := server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+		User: &pb.User{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"},
+		From: "France",
+		To:   "London",
+	})
+	assertCode(t, err, codes.InvalidArgument, "reversed journey")
+	var fields []string
+	for _, v := range trainerr.FieldViolations(err) {
+		fields = append(fields, v.Field)
+	}
+	assert.Equal(t, []string{"from", "to"}, fields)
 
-	// Add a user
 	req := &pb.PurchaseTicketRequest{
-		User: &pb.User{
-			FirstName: "John",
-			LastName:  "Doe",
-			Email:     "john.doe@example.com",
-		},
+		User: &pb.User{FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"},
 		From: "London",
 		To:   "France",
 	}
-	server.PurchaseTicket(context.Background(), req)
-
-	// Modify the user's seat
-	modifyReq := &pb.ModifySeatRequest{
-		Email:      "john.doe@example.com",
-		NewSection: "B",
-		NewSeat:    5,
-	}
-	receipt, err := server.ModifyUserSeat(context.Background(), modifyReq)
-	assert.NoError(t, err, "error modifying user seat")
-	assert.Equal(t, "B", receipt.Seat.Section, "expected section B")
-	assert.Equal(t, int32(5), receipt.Seat.Seat, "expected seat 5")
+	_, err = server.PurchaseTicket(context.Background(), req)
+	assert.NoError(t, err, "error purchasing ticket")
+	_, err = server.PurchaseTicket(context.Background(), req)
+	assertCode(t, err, codes.ResourceExhausted, "train should be full")
+	delay, ok := trainerr.RetryDelay(err)
+	assert.True(t, ok, "full train should suggest when to retry")
+	assert.Equal(t, defaultHoldTTL, delay)
+
+	_, err = server.GetReceipt(context.Background(), &pb.UserRequest{Email: "nobody@example.com"})
+	assertCode(t, err, codes.NotFound, "unknown user")
+	assert.Equal(t, "user not found", status.Convert(err).Message())
 }
-
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+
+	pb "test_train/protobuf"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNotFound is returned by a Store when no record exists for the given key.
+var ErrNotFound = errors.New("not found")
+
+// Store is the persistence layer behind the TrainService handlers.
+// Implementations must be safe for concurrent use, with each call atomic;
+// handlers that read and then write hold the departure's lock so no other
+// change to the departure comes in between. Tickets are copied on the way
+// in and out so that handlers never share pointers with the stored state.
+type Store interface {
+	// PutTicket inserts or replaces the ticket with receipt.TicketId and
+	// re-indexes its seat, user and booking reference.
+	PutTicket(receipt *pb.TicketReceipt) error
+	// GetTicket returns the ticket with ticketID, or ErrNotFound.
+	GetTicket(ticketID string) (*pb.TicketReceipt, error)
+	// DeleteTicket removes the ticket with ticketID, or returns ErrNotFound.
+	DeleteTicket(ticketID string) error
+	// TicketsByUser returns every ticket held by email, oldest first.
+	TicketsByUser(email string) ([]*pb.TicketReceipt, error)
+	// TicketsByReference returns the tickets booked under a booking
+	// reference, oldest first.
+	TicketsByReference(reference string) ([]*pb.TicketReceipt, error)
+	// SectionTickets returns the tickets seated in a departure's section.
+	SectionTickets(departureID, section string) ([]*pb.TicketReceipt, error)
+	// UsersBySection returns the users seated in a departure's section.
+	UsersBySection(departureID, section string) ([]*pb.User, error)
+	// PutCancellation records a cancelled ticket, replacing any earlier
+	// record of the same ticket left by an interrupted cancellation.
+	PutCancellation(cancellation *pb.Cancellation) error
+	// CancellationsByReference returns the cancellations recorded under a
+	// booking reference, oldest first.
+	CancellationsByReference(reference string) ([]*pb.Cancellation, error)
+	// PutDeparture inserts or replaces a departure.
+	PutDeparture(departure *pb.Departure) error
+	// GetDeparture returns the departure with id, or ErrNotFound.
+	GetDeparture(id string) (*pb.Departure, error)
+	// ListDepartures returns every departure in no particular order.
+	ListDepartures() ([]*pb.Departure, error)
+	// AppendEvents adds events to the booking ledger in order, assigning
+	// each the next sequence number. Either all of them are added or, on
+	// error, none are. Events are never changed or removed.
+	AppendEvents(events ...*pb.BookingEvent) error
+	// Events returns the whole ledger in sequence order.
+	Events() ([]*pb.BookingEvent, error)
+	// EventsByReference returns the events of one booking in sequence order.
+	EventsByReference(reference string) ([]*pb.BookingEvent, error)
+	// Close flushes any buffered state and releases resources.
+	Close() error
+}
+
+// memoryStore keeps everything in process memory and loses it on restart.
+type memoryStore struct {
+	mu sync.RWMutex
+	memoryData
+}
+
+// memoryData is the state of a memoryStore, apart so Rebuild can replace it
+// whole.
+type memoryData struct {
+	tickets     map[string]*pb.TicketReceipt                        // ticket id -> ticket
+	sections    map[string]map[string]map[string]*pb.SeatAllocation // departure -> section -> ticket id
+	byUser      map[string]map[string]bool                          // email -> ticket ids
+	byReference map[string]map[string]bool                          // booking reference -> ticket ids
+	userData    map[string]*pb.User                                 // Track user data by email
+	departures  map[string]*pb.Departure
+	sequence    map[string]int // ticket id -> insertion order
+	next        int
+
+	cancellations map[string][]*pb.Cancellation // booking reference -> cancellations
+
+	events            []*pb.BookingEvent
+	eventsByReference map[string][]int // booking reference -> indexes into events
+}
+
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{memoryData: memoryData{
+		tickets:     make(map[string]*pb.TicketReceipt),
+		sections:    make(map[string]map[string]map[string]*pb.SeatAllocation),
+		byUser:      make(map[string]map[string]bool),
+		byReference: make(map[string]map[string]bool),
+		userData:    make(map[string]*pb.User),
+		departures:  make(map[string]*pb.Departure),
+		sequence:    make(map[string]int),
+
+		cancellations: make(map[string][]*pb.Cancellation),
+
+		eventsByReference: make(map[string][]int),
+	}}
+}
+
+// section returns the seat index for a departure's section, creating it when
+// create is set. A nil map is safe to read from.
+func (m *memoryStore) section(departureID, section string, create bool) map[string]*pb.SeatAllocation {
+	bySection, ok := m.sections[departureID]
+	if !ok {
+		if !create {
+			return nil
+		}
+		bySection = make(map[string]map[string]*pb.SeatAllocation)
+		m.sections[departureID] = bySection
+	}
+	seats, ok := bySection[section]
+	if !ok && create {
+		seats = make(map[string]*pb.SeatAllocation)
+		bySection[section] = seats
+	}
+	return seats
+}
+
+// addIndex records id under key in index.
+func addIndex(index map[string]map[string]bool, key, id string) {
+	if index[key] == nil {
+		index[key] = make(map[string]bool)
+	}
+	index[key][id] = true
+}
+
+// removeIndex drops id from key in index, pruning empty keys.
+func removeIndex(index map[string]map[string]bool, key, id string) {
+	delete(index[key], id)
+	if len(index[key]) == 0 {
+		delete(index, key)
+	}
+}
+
+// unindex removes every index entry pointing at a stored ticket.
+func (m *memoryStore) unindex(old *pb.TicketReceipt) {
+	delete(m.section(old.DepartureId, old.Seat.GetSection(), false), old.TicketId)
+	removeIndex(m.byUser, old.User.GetEmail(), old.TicketId)
+	removeIndex(m.byReference, old.BookingReference, old.TicketId)
+}
+
+func (m *memoryStore) PutTicket(receipt *pb.TicketReceipt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	receipt = proto.Clone(receipt).(*pb.TicketReceipt)
+	id := receipt.TicketId
+
+	if old, ok := m.tickets[id]; ok {
+		m.unindex(old)
+	} else {
+		m.next++
+		m.sequence[id] = m.next
+	}
+
+	m.tickets[id] = receipt
+	m.section(receipt.DepartureId, receipt.Seat.GetSection(), true)[id] = receipt.Seat
+	addIndex(m.byUser, receipt.User.GetEmail(), id)
+	addIndex(m.byReference, receipt.BookingReference, id)
+	m.userData[receipt.User.GetEmail()] = receipt.User
+	return nil
+}
+
+func (m *memoryStore) GetTicket(ticketID string) (*pb.TicketReceipt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	receipt, ok := m.tickets[ticketID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(receipt).(*pb.TicketReceipt), nil
+}
+
+func (m *memoryStore) DeleteTicket(ticketID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	receipt, ok := m.tickets[ticketID]
+	if !ok {
+		return ErrNotFound
+	}
+	m.unindex(receipt)
+	delete(m.tickets, ticketID)
+	delete(m.sequence, ticketID)
+	if email := receipt.User.GetEmail(); len(m.byUser[email]) == 0 {
+		delete(m.userData, email)
+	}
+	return nil
+}
+
+// collect copies the tickets with the given ids, oldest first.
+func (m *memoryStore) collect(ids map[string]bool) []*pb.TicketReceipt {
+	tickets := []*pb.TicketReceipt{}
+	for id := range ids {
+		tickets = append(tickets, proto.Clone(m.tickets[id]).(*pb.TicketReceipt))
+	}
+	sort.Slice(tickets, func(i, j int) bool {
+		return m.sequence[tickets[i].TicketId] < m.sequence[tickets[j].TicketId]
+	})
+	return tickets
+}
+
+func (m *memoryStore) TicketsByUser(email string) ([]*pb.TicketReceipt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.collect(m.byUser[email]), nil
+}
+
+func (m *memoryStore) TicketsByReference(reference string) ([]*pb.TicketReceipt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.collect(m.byReference[reference]), nil
+}
+
+func (m *memoryStore) SectionTickets(departureID, section string) ([]*pb.TicketReceipt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tickets := []*pb.TicketReceipt{}
+	for id := range m.section(departureID, section, false) {
+		tickets = append(tickets, proto.Clone(m.tickets[id]).(*pb.TicketReceipt))
+	}
+	return tickets, nil
+}
+
+func (m *memoryStore) UsersBySection(departureID, section string) ([]*pb.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := []*pb.User{}
+	seen := make(map[string]bool)
+	for id := range m.section(departureID, section, false) {
+		// Retrieve the user using their email; list each user once even if
+		// they hold several seats in the section
+		email := m.tickets[id].User.GetEmail()
+		user, exists := m.userData[email]
+		if exists && !seen[email] {
+			seen[email] = true
+			users = append(users, &pb.User{
+				FirstName: user.FirstName,
+				LastName:  user.LastName,
+				Email:     user.Email,
+			})
+		}
+	}
+	return users, nil
+}
+
+func (m *memoryStore) PutCancellation(cancellation *pb.Cancellation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reference := cancellation.BookingReference
+	cancellations := slices.DeleteFunc(m.cancellations[reference], func(c *pb.Cancellation) bool {
+		return c.TicketId == cancellation.TicketId
+	})
+	m.cancellations[reference] = append(cancellations, proto.Clone(cancellation).(*pb.Cancellation))
+	return nil
+}
+
+func (m *memoryStore) CancellationsByReference(reference string) ([]*pb.Cancellation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cancellations := []*pb.Cancellation{}
+	for _, cancellation := range m.cancellations[reference] {
+		cancellations = append(cancellations, proto.Clone(cancellation).(*pb.Cancellation))
+	}
+	return cancellations, nil
+}
+
+func (m *memoryStore) PutDeparture(departure *pb.Departure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.departures[departure.Id] = proto.Clone(departure).(*pb.Departure)
+	return nil
+}
+
+func (m *memoryStore) GetDeparture(id string) (*pb.Departure, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	departure, ok := m.departures[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(departure).(*pb.Departure), nil
+}
+
+func (m *memoryStore) ListDepartures() ([]*pb.Departure, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	departures := make([]*pb.Departure, 0, len(m.departures))
+	for _, departure := range m.departures {
+		departures = append(departures, proto.Clone(departure).(*pb.Departure))
+	}
+	return departures, nil
+}
+
+func (m *memoryStore) AppendEvents(events ...*pb.BookingEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.addEvents(m.numberEvents(events))
+	return nil
+}
+
+// numberEvents returns copies of events numbered to follow the ledger. The
+// caller holds m.mu.
+func (m *memoryStore) numberEvents(events []*pb.BookingEvent) []*pb.BookingEvent {
+	numbered := make([]*pb.BookingEvent, len(events))
+	for i, event := range events {
+		numbered[i] = proto.Clone(event).(*pb.BookingEvent)
+		numbered[i].Sequence = uint64(len(m.events) + i + 1)
+	}
+	return numbered
+}
+
+// addEvents appends events numbered by numberEvents to the ledger. The
+// caller holds m.mu exclusively.
+func (m *memoryStore) addEvents(events []*pb.BookingEvent) {
+	for _, event := range events {
+		m.events = append(m.events, event)
+		if event.BookingReference != "" {
+			m.eventsByReference[event.BookingReference] = append(m.eventsByReference[event.BookingReference], len(m.events)-1)
+		}
+	}
+}
+
+func (m *memoryStore) Events() ([]*pb.BookingEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := make([]*pb.BookingEvent, len(m.events))
+	for i, event := range m.events {
+		events[i] = proto.Clone(event).(*pb.BookingEvent)
+	}
+	return events, nil
+}
+
+func (m *memoryStore) EventsByReference(reference string) ([]*pb.BookingEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := []*pb.BookingEvent{}
+	for _, i := range m.eventsByReference[reference] {
+		events = append(events, proto.Clone(m.events[i]).(*pb.BookingEvent))
+	}
+	return events, nil
+}
+
+func (m *memoryStore) Close() error { return nil }
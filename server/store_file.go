@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	pb "test_train/protobuf"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	journalFile  = "journal.log"
+	snapshotFile = "snapshot.json"
+)
+
+// journalEntry is one line of the append-only journal.
+type journalEntry struct {
+	Op        string          `json:"op"`
+	TicketID  string          `json:"ticket_id,omitempty"`
+	Ticket    json.RawMessage `json:"ticket,omitempty"`
+	Departure json.RawMessage `json:"departure,omitempty"`
+
+	Cancellation json.RawMessage   `json:"cancellation,omitempty"`
+	Event        json.RawMessage   `json:"event,omitempty"`
+	Events       []json.RawMessage `json:"events,omitempty"`
+}
+
+// snapshot is the full state written when the journal is compacted.
+type snapshot struct {
+	Departures    []json.RawMessage `json:"departures"`
+	Tickets       []json.RawMessage `json:"tickets"`
+	Cancellations []json.RawMessage `json:"cancellations"`
+	Events        []json.RawMessage `json:"events"`
+}
+
+// fileStore is a memoryStore made durable by an append-only journal in dir.
+// Every snapshotEvery journal entries the state is written to a snapshot and
+// the journal is truncated, so recovery replays at most that many entries.
+type fileStore struct {
+	*memoryStore
+	// mu keeps journal entries in the order their changes were applied, so
+	// replaying the journal numbers ledger events as they were numbered
+	mu            sync.Mutex
+	dir           string
+	journal       *os.File
+	entries       int
+	snapshotEvery int
+}
+
+// OpenFileStore loads the snapshot and journal in dir, creating the
+// directory if needed. A snapshotEvery of zero or less disables compaction
+// except on Close.
+func OpenFileStore(dir string, snapshotEvery int) (*fileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f := &fileStore{
+		memoryStore:   NewMemoryStore(),
+		dir:           dir,
+		snapshotEvery: snapshotEvery,
+	}
+	if err := f.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := f.replayJournal(); err != nil {
+		return nil, err
+	}
+
+	journal, err := os.OpenFile(filepath.Join(dir, journalFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	f.journal = journal
+	return f, nil
+}
+
+func (f *fileStore) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(f.dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+	for _, raw := range snap.Departures {
+		departure := &pb.Departure{}
+		if err := protojson.Unmarshal(raw, departure); err != nil {
+			return fmt.Errorf("decode snapshot departure: %w", err)
+		}
+		f.memoryStore.PutDeparture(departure)
+	}
+	for _, raw := range snap.Tickets {
+		receipt := &pb.TicketReceipt{}
+		if err := protojson.Unmarshal(raw, receipt); err != nil {
+			return fmt.Errorf("decode snapshot ticket: %w", err)
+		}
+		f.memoryStore.PutTicket(upgradeTicket(receipt))
+	}
+	for _, raw := range snap.Cancellations {
+		cancellation := &pb.Cancellation{}
+		if err := protojson.Unmarshal(raw, cancellation); err != nil {
+			return fmt.Errorf("decode snapshot cancellation: %w", err)
+		}
+		f.memoryStore.PutCancellation(cancellation)
+	}
+	for _, raw := range snap.Events {
+		event := &pb.BookingEvent{}
+		if err := protojson.Unmarshal(raw, event); err != nil {
+			return fmt.Errorf("decode snapshot event: %w", err)
+		}
+		f.memoryStore.AppendEvents(event)
+	}
+	return nil
+}
+
+func (f *fileStore) replayJournal() error {
+	file, err := os.Open(filepath.Join(f.dir, journalFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var good int64
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A torn final write is expected after a crash; cut the journal
+			// there so new entries are not appended behind garbage
+			return os.Truncate(file.Name(), good)
+		}
+		if err := f.apply(entry); err != nil {
+			return err
+		}
+		good += int64(len(scanner.Bytes())) + 1
+		f.entries++
+	}
+	return scanner.Err()
+}
+
+func (f *fileStore) apply(entry journalEntry) error {
+	switch entry.Op {
+	case "put":
+		receipt := &pb.TicketReceipt{}
+		if err := protojson.Unmarshal(entry.Ticket, receipt); err != nil {
+			return fmt.Errorf("decode journal ticket: %w", err)
+		}
+		return f.memoryStore.PutTicket(upgradeTicket(receipt))
+	case "delete":
+		if err := f.memoryStore.DeleteTicket(entry.TicketID); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return nil
+	case "departure":
+		departure := &pb.Departure{}
+		if err := protojson.Unmarshal(entry.Departure, departure); err != nil {
+			return fmt.Errorf("decode journal departure: %w", err)
+		}
+		return f.memoryStore.PutDeparture(departure)
+	case "cancellation":
+		cancellation := &pb.Cancellation{}
+		if err := protojson.Unmarshal(entry.Cancellation, cancellation); err != nil {
+			return fmt.Errorf("decode journal cancellation: %w", err)
+		}
+		return f.memoryStore.PutCancellation(cancellation)
+	case "event", "events":
+		// Journals written before events were appended together hold one
+		// event per entry
+		raws := entry.Events
+		if entry.Op == "event" {
+			raws = []json.RawMessage{entry.Event}
+		}
+		events := make([]*pb.BookingEvent, len(raws))
+		for i, raw := range raws {
+			events[i] = &pb.BookingEvent{}
+			if err := protojson.Unmarshal(raw, events[i]); err != nil {
+				return fmt.Errorf("decode journal event: %w", err)
+			}
+		}
+		return f.memoryStore.AppendEvents(events...)
+	default:
+		return fmt.Errorf("unknown journal op %q", entry.Op)
+	}
+}
+
+func (f *fileStore) append(entry journalEntry) error {
+	if err := f.write(entry); err != nil {
+		return err
+	}
+	return f.compact()
+}
+
+// write appends entry to the journal and syncs it.
+func (f *fileStore) write(entry journalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := f.journal.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := f.journal.Sync(); err != nil {
+		return err
+	}
+	f.entries++
+	return nil
+}
+
+// compact writes a snapshot once snapshotEvery entries have been journaled.
+func (f *fileStore) compact() error {
+	if f.snapshotEvery > 0 && f.entries >= f.snapshotEvery {
+		return f.writeSnapshot()
+	}
+	return nil
+}
+
+func (f *fileStore) PutTicket(receipt *pb.TicketReceipt) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	raw, err := protojson.Marshal(receipt)
+	if err != nil {
+		return err
+	}
+	if err := f.memoryStore.PutTicket(receipt); err != nil {
+		return err
+	}
+	return f.append(journalEntry{Op: "put", Ticket: raw})
+}
+
+func (f *fileStore) DeleteTicket(ticketID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.memoryStore.DeleteTicket(ticketID); err != nil {
+		return err
+	}
+	return f.append(journalEntry{Op: "delete", TicketID: ticketID})
+}
+
+func (f *fileStore) PutDeparture(departure *pb.Departure) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	raw, err := protojson.Marshal(departure)
+	if err != nil {
+		return err
+	}
+	if err := f.memoryStore.PutDeparture(departure); err != nil {
+		return err
+	}
+	return f.append(journalEntry{Op: "departure", Departure: raw})
+}
+
+func (f *fileStore) PutCancellation(cancellation *pb.Cancellation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	raw, err := protojson.Marshal(cancellation)
+	if err != nil {
+		return err
+	}
+	if err := f.memoryStore.PutCancellation(cancellation); err != nil {
+		return err
+	}
+	return f.append(journalEntry{Op: "cancellation", Cancellation: raw})
+}
+
+// AppendEvents journals events as one entry, so a torn write loses all of
+// them, and only then adds them to the ledger in memory, so a failed write
+// leaves no trace of them.
+func (f *fileStore) AppendEvents(events ...*pb.BookingEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// f.mu keeps other appends out until the events are added
+	f.memoryStore.mu.RLock()
+	numbered := f.memoryStore.numberEvents(events)
+	f.memoryStore.mu.RUnlock()
+	entry := journalEntry{Op: "events"}
+	for _, event := range numbered {
+		raw, err := protojson.Marshal(event)
+		if err != nil {
+			return err
+		}
+		entry.Events = append(entry.Events, raw)
+	}
+	if err := f.write(entry); err != nil {
+		return err
+	}
+
+	f.memoryStore.mu.Lock()
+	f.memoryStore.addEvents(numbered)
+	f.memoryStore.mu.Unlock()
+	return f.compact()
+}
+
+// Rebuild replaces the stored state with a replay of the ledger and writes
+// it out as a new snapshot.
+func (f *fileStore) Rebuild() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.memoryStore.Rebuild(); err != nil {
+		return err
+	}
+	return f.writeSnapshot()
+}
+
+// Snapshot writes the current state atomically and truncates the journal.
+func (f *fileStore) Snapshot() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.writeSnapshot()
+}
+
+func (f *fileStore) writeSnapshot() error {
+	f.memoryStore.mu.RLock()
+	defer f.memoryStore.mu.RUnlock()
+
+	snap := snapshot{
+		Departures:    []json.RawMessage{},
+		Tickets:       []json.RawMessage{},
+		Cancellations: []json.RawMessage{},
+		Events:        []json.RawMessage{},
+	}
+	for _, departure := range f.departures {
+		raw, err := protojson.Marshal(departure)
+		if err != nil {
+			return err
+		}
+		snap.Departures = append(snap.Departures, raw)
+	}
+	// Keep tickets in booking order so it survives a reload
+	ids := make([]string, 0, len(f.tickets))
+	for id := range f.tickets {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return f.sequence[ids[i]] < f.sequence[ids[j]] })
+	for _, id := range ids {
+		raw, err := protojson.Marshal(f.tickets[id])
+		if err != nil {
+			return err
+		}
+		snap.Tickets = append(snap.Tickets, raw)
+	}
+	for _, cancellations := range f.cancellations {
+		for _, cancellation := range cancellations {
+			raw, err := protojson.Marshal(cancellation)
+			if err != nil {
+				return err
+			}
+			snap.Cancellations = append(snap.Cancellations, raw)
+		}
+	}
+
+	for _, event := range f.events {
+		raw, err := protojson.Marshal(event)
+		if err != nil {
+			return err
+		}
+		snap.Events = append(snap.Events, raw)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(f.dir, snapshotFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(f.dir, snapshotFile)); err != nil {
+		return err
+	}
+
+	if err := f.journal.Truncate(0); err != nil {
+		return err
+	}
+	f.entries = 0
+	return nil
+}
+
+func (f *fileStore) Close() error {
+	if err := f.Snapshot(); err != nil {
+		f.journal.Close()
+		return err
+	}
+	return f.journal.Close()
+}
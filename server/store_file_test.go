@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestFileStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := OpenFileStore(dir, 0)
+	require.NoError(t, err, "error opening file store")
+	server, err := NewServerWithStore(store)
+	require.NoError(t, err, "error creating server")
+
+	for _, email := range []string{"john.doe@example.com", "jane.smith@example.com", "bob.jones@example.com"} {
+		_, err := server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{
+			User: &pb.User{FirstName: "Test", LastName: "User", Email: email},
+			From: "London",
+			To:   "France",
+		})
+		require.NoError(t, err, "error purchasing ticket")
+	}
+	_, err = server.RemoveUser(context.Background(), &pb.UserRequest{Email: "bob.jones@example.com"})
+	require.NoError(t, err, "error removing user")
+	_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+		Email:      "jane.smith@example.com",
+		NewSection: "B",
+		NewSeat:    7,
+	})
+	require.NoError(t, err, "error modifying seat")
+
+	// Simulate a crash: drop the store without a final snapshot
+	require.NoError(t, store.journal.Close())
+
+	reopened, err := OpenFileStore(dir, 0)
+	require.NoError(t, err, "error reopening file store")
+	defer reopened.Close()
+	server, err = NewServerWithStore(reopened)
+	require.NoError(t, err, "error creating server")
+
+	receipt, err := server.GetReceipt(context.Background(), &pb.UserRequest{Email: "jane.smith@example.com"})
+	assert.NoError(t, err, "error fetching receipt after restart")
+	assert.Equal(t, "B", receipt.Seat.Section, "expected section B")
+	assert.Equal(t, int32(7), receipt.Seat.Seat, "expected seat 7")
+
+	_, err = server.GetReceipt(context.Background(), &pb.UserRequest{Email: "bob.jones@example.com"})
+	assertCode(t, err, codes.NotFound, "removed user should stay removed after restart")
+
+	resp, err := server.GetUsersBySection(context.Background(), &pb.SectionRequest{Section: "A"})
+	assert.NoError(t, err, "error fetching users by section")
+	assert.Len(t, resp.Users, 1, "expected 1 user in section A")
+}
+
+func TestFileStoreSnapshotTruncatesJournal(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := OpenFileStore(dir, 2)
+	require.NoError(t, err, "error opening file store")
+
+	for i, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		require.NoError(t, store.PutTicket(&pb.TicketReceipt{
+			TicketId: email,
+			User:     &pb.User{Email: email},
+			Seat:     &pb.SeatAllocation{Section: "A", Seat: int32(i + 1)},
+		}))
+	}
+
+	// Two entries were compacted into the snapshot, one remains in the journal
+	assert.FileExists(t, filepath.Join(dir, snapshotFile))
+	assert.Equal(t, 1, store.entries, "expected one journal entry after snapshot")
+	require.NoError(t, store.Close())
+
+	info, err := os.Stat(filepath.Join(dir, journalFile))
+	require.NoError(t, err)
+	assert.Zero(t, info.Size(), "journal should be empty after close")
+
+	reopened, err := OpenFileStore(dir, 2)
+	require.NoError(t, err, "error reopening file store")
+	defer reopened.Close()
+	tickets, err := reopened.SectionTickets("", "A")
+	assert.NoError(t, err)
+	assert.Len(t, tickets, 3, "expected 3 tickets in section A")
+}
+
+func TestFileStoreIgnoresTornJournalTail(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := OpenFileStore(dir, 0)
+	require.NoError(t, err, "error opening file store")
+	require.NoError(t, store.PutTicket(&pb.TicketReceipt{
+		TicketId: "TKT-1",
+		User:     &pb.User{Email: "john.doe@example.com"},
+		Seat:     &pb.SeatAllocation{Section: "A", Seat: 1},
+	}))
+	_, err = store.journal.WriteString(`{"op":"put","tick`)
+	require.NoError(t, err)
+	require.NoError(t, store.journal.Close())
+
+	reopened, err := OpenFileStore(dir, 0)
+	require.NoError(t, err, "torn tail should not prevent recovery")
+	_, err = reopened.GetTicket("TKT-1")
+	assert.NoError(t, err, "ticket before the torn write should survive")
+
+	// Entries written after recovery must not be hidden behind the torn line
+	require.NoError(t, reopened.PutTicket(&pb.TicketReceipt{
+		TicketId: "TKT-2",
+		User:     &pb.User{Email: "jane.smith@example.com"},
+		Seat:     &pb.SeatAllocation{Section: "A", Seat: 2},
+	}))
+	require.NoError(t, reopened.journal.Close())
+
+	again, err := OpenFileStore(dir, 0)
+	require.NoError(t, err, "error reopening file store")
+	defer again.Close()
+	_, err = again.GetTicket("TKT-2")
+	assert.NoError(t, err, "ticket written after recovery should survive")
+}
+
+func TestFileStoreVersionsLegacyTickets(t *testing.T) {
+	dir := t.TempDir()
+
+	// Written before tickets carried a version: one compacted into the
+	// snapshot, one left in the journal
+	store, err := OpenFileStore(dir, 1)
+	require.NoError(t, err, "error opening file store")
+	for i, email := range []string{"john.doe@example.com", "jane.smith@example.com"} {
+		require.NoError(t, store.PutTicket(&pb.TicketReceipt{
+			TicketId: email,
+			User:     &pb.User{FirstName: "Test", LastName: "User", Email: email},
+			From:     "London",
+			To:       "France",
+			Seat:     &pb.SeatAllocation{Section: "A", Seat: int32(i + 1)},
+		}))
+	}
+	require.NoError(t, store.journal.Close())
+
+	reopened, err := OpenFileStore(dir, 0)
+	require.NoError(t, err, "error reopening file store")
+	defer reopened.Close()
+	server, err := NewServerWithStore(reopened)
+	require.NoError(t, err, "error creating server")
+
+	for _, email := range []string{"john.doe@example.com", "jane.smith@example.com"} {
+		receipt, err := server.GetReceipt(context.Background(), &pb.UserRequest{Email: email})
+		require.NoError(t, err, "error fetching receipt")
+		assert.Equal(t, int64(1), receipt.Version, "legacy ticket should load at version 1")
+
+		_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+			Email:           email,
+			NewSection:      "B",
+			NewSeat:         7,
+			ExpectedVersion: 2,
+		})
+		assertCode(t, err, codes.Aborted, "stale version should be refused for a legacy ticket")
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// swapConsentTTL is how long one passenger's consent to a seat swap waits
+// for the other passenger to agree.
+const swapConsentTTL = 15 * time.Minute
+
+// swapConsent records that a ticket's passenger agreed to exchange seats
+// with other, as both tickets were seated at the time.
+type swapConsent struct {
+	other     string // ticket id
+	seat      *pb.SeatAllocation
+	otherSeat *pb.SeatAllocation
+	expires   time.Time
+}
+
+// SwapSeats records the caller's consent to exchange seats with another
+// ticket. When the other passenger has already consented to the same swap,
+// and neither ticket has moved since, the seats are exchanged atomically.
+func (s *server) SwapSeats(ctx context.Context, req *pb.SwapSeatsRequest) (*pb.SwapSeatsResponse, error) {
+	mine, unlock, err := s.lockTicket(req.BookingReference, req.Email, canRead(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := authorizeUser(ctx, mine.User.GetEmail(), true); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(mine, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	// The other ticket is another passenger's by design
+	other, err := s.findTicket(req.OtherBookingReference, req.OtherEmail, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, receipt := range []*pb.TicketReceipt{mine, other} {
+		if err := checkNotCancelling(receipt); err != nil {
+			return nil, err
+		}
+	}
+	if mine.TicketId == other.TicketId {
+		return nil, trainerr.InvalidField("other_booking_reference", "cannot swap a ticket with itself")
+	}
+	if mine.DepartureId != other.DepartureId {
+		return nil, trainerr.FailedPrecondition("DIFFERENT_DEPARTURES", other.TicketId,
+			"tickets %s and %s are on different departures", mine.TicketId, other.TicketId)
+	}
+	if proto.Equal(mine.Seat, other.Seat) {
+		return nil, trainerr.InvalidField("other_booking_reference", "both tickets already use seat %s%d", mine.Seat.Section, mine.Seat.Seat)
+	}
+
+	// Both tickets are on the locked departure, so other cannot move
+	// while the swap is considered
+	swaps := s.state(mine.DepartureId).swaps
+	now := s.now()
+	for id, consent := range swaps {
+		if !now.Before(consent.expires) {
+			delete(swaps, id)
+		}
+	}
+
+	if consent := swaps[other.TicketId]; consent != nil && consent.other == mine.TicketId &&
+		proto.Equal(consent.seat, other.Seat) && proto.Equal(consent.otherSeat, mine.Seat) {
+		if err := s.swapSeats(ctx, mine, other); err != nil {
+			return nil, err
+		}
+		delete(swaps, mine.TicketId)
+		delete(swaps, other.TicketId)
+		return &pb.SwapSeatsResponse{Status: pb.SwapStatus_SWAP_STATUS_COMPLETED, Ticket: mine}, nil
+	}
+
+	consent := &swapConsent{
+		other:     other.TicketId,
+		seat:      proto.Clone(mine.Seat).(*pb.SeatAllocation),
+		otherSeat: proto.Clone(other.Seat).(*pb.SeatAllocation),
+		expires:   now.Add(swapConsentTTL),
+	}
+	swaps[mine.TicketId] = consent
+	return &pb.SwapSeatsResponse{
+		Status:         pb.SwapStatus_SWAP_STATUS_PENDING,
+		Ticket:         mine,
+		ConsentExpires: timestamppb.New(consent.expires),
+	}, nil
+}
+
+// swapSeats exchanges the seats of a and b, which must be on the same
+// departure. Each passenger must be able to use the other's seat for their
+// own journey, which can differ when the tickets cover different legs.
+func (s *server) swapSeats(ctx context.Context, a, b *pb.TicketReceipt) error {
+	departure, err := s.bookableDeparture(a.DepartureId)
+	if err != nil {
+		return err
+	}
+	for _, pair := range [][2]*pb.TicketReceipt{{a, b}, {b, a}} {
+		passenger, owner := pair[0], pair[1]
+		occupancy, err := s.sectionOccupancy(departure, owner.Seat.Section, owner.TicketId)
+		if err != nil {
+			return err
+		}
+		if !occupancy.free(owner.Seat.Seat, ticketLeg(departure, passenger)) {
+			seat := fmt.Sprintf("%s%d", owner.Seat.Section, owner.Seat.Seat)
+			return trainerr.AlreadyExists(trainerr.ResourceSeat, seat, "seat %s is taken on part of ticket %s's journey", seat, passenger.TicketId)
+		}
+	}
+
+	beforeA := proto.Clone(a).(*pb.TicketReceipt)
+	beforeB := proto.Clone(b).(*pb.TicketReceipt)
+	a.Seat, b.Seat = b.Seat, a.Seat
+	a.Version++
+	b.Version++
+	if err := s.store.PutTicket(a); err != nil {
+		a.Seat, b.Seat = b.Seat, a.Seat
+		a.Version--
+		b.Version--
+		return err
+	}
+	if err := s.store.PutTicket(b); err != nil {
+		a.Seat, b.Seat = b.Seat, a.Seat
+		a.Version--
+		b.Version--
+		if rollbackErr := s.store.PutTicket(a); rollbackErr != nil {
+			return fmt.Errorf("swap seats: %v; restoring ticket %s: %w", err, a.TicketId, rollbackErr)
+		}
+		return err
+	}
+	if err := s.record(ctx, seatChangeEvent(beforeA, a, "swapped"), seatChangeEvent(beforeB, b, "swapped")); err != nil {
+		s.restoreTickets(beforeA, beforeB)
+		return err
+	}
+	s.seatChanged(a.DepartureId, a.Seat, "swapped")
+	s.seatChanged(b.DepartureId, b.Seat, "swapped")
+	return nil
+}
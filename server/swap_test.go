@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestModifyUserSeatRejectsTakenSeat(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		first, err := server.PurchaseTicket(context.Background(), purchaseRequest("first@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+		second, err := server.PurchaseTicket(context.Background(), purchaseRequest("second@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+
+		_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+			BookingReference: second.BookingReference,
+			NewSection:       first.Seat.Section,
+			NewSeat:          first.Seat.Seat,
+		})
+		assertCode(t, err, codes.AlreadyExists, "moving onto an occupied seat should fail")
+
+		// Re-selecting one's own seat is not a conflict.
+		_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+			BookingReference: second.BookingReference,
+			NewSection:       second.Seat.Section,
+			NewSeat:          second.Seat.Seat,
+		})
+		assert.NoError(t, err, "error keeping own seat")
+	})
+}
+
+func TestModifyUserSeatAnySeat(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		for _, email := range []string{"first@example.com", "second@example.com"} {
+			_, err := server.PurchaseTicket(context.Background(), purchaseRequest(email))
+			require.NoError(t, err, "error purchasing ticket")
+		}
+
+		for i, email := range []string{"first@example.com", "second@example.com"} {
+			receipt, err := server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+				Email:      email,
+				NewSection: "B",
+				AnySeat:    true,
+			})
+			require.NoError(t, err, "error moving to any seat")
+			assert.Equal(t, "B", receipt.Seat.Section)
+			assert.Equal(t, int32(i+1), receipt.Seat.Seat, "expected the lowest free seat in B")
+		}
+	})
+}
+
+func TestModifyUserSeatAnySeatInFullSection(t *testing.T) {
+	server := newSingleSeatServer()
+	_, err := server.PurchaseTicket(context.Background(), purchaseRequest("first@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	_, err = server.PurchaseTicket(context.Background(), purchaseRequest("second@example.com"))
+	assertCode(t, err, codes.ResourceExhausted)
+
+	receipt, err := server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+		Email:      "first@example.com",
+		NewSection: "A",
+		AnySeat:    true,
+	})
+	require.NoError(t, err, "a passenger may keep their own seat")
+	assert.Equal(t, int32(1), receipt.Seat.Seat)
+}
+
+func TestSwapSeatsNeedsBothConsents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, server *server) {
+		first, err := server.PurchaseTicket(context.Background(), purchaseRequest("first@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+		second, err := server.PurchaseTicket(context.Background(), purchaseRequest("second@example.com"))
+		require.NoError(t, err, "error purchasing ticket")
+
+		resp, err := server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+			BookingReference:      first.BookingReference,
+			OtherBookingReference: second.BookingReference,
+		})
+		require.NoError(t, err, "error consenting to swap")
+		assert.Equal(t, pb.SwapStatus_SWAP_STATUS_PENDING, resp.Status)
+		assert.NotNil(t, resp.ConsentExpires)
+
+		unchanged, err := server.GetReceipt(context.Background(), &pb.UserRequest{BookingReference: first.BookingReference})
+		require.NoError(t, err, "error getting receipt")
+		assert.Equal(t, first.Seat.Seat, unchanged.Seat.Seat, "one consent must not move anyone")
+
+		resp, err = server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+			BookingReference:      second.BookingReference,
+			OtherBookingReference: first.BookingReference,
+		})
+		require.NoError(t, err, "error completing swap")
+		assert.Equal(t, pb.SwapStatus_SWAP_STATUS_COMPLETED, resp.Status)
+		assert.Equal(t, first.Seat.Seat, resp.Ticket.Seat.Seat)
+
+		swapped, err := server.GetReceipt(context.Background(), &pb.UserRequest{BookingReference: first.BookingReference})
+		require.NoError(t, err, "error getting receipt")
+		assert.Equal(t, second.Seat.Seat, swapped.Seat.Seat)
+	})
+}
+
+func TestSwapSeatsConsentLapses(t *testing.T) {
+	clock := newFakeClock()
+	server := NewServer(WithClock(clock.Now))
+	first, err := server.PurchaseTicket(context.Background(), purchaseRequest("first@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	second, err := server.PurchaseTicket(context.Background(), purchaseRequest("second@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+
+	_, err = server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+		BookingReference:      first.BookingReference,
+		OtherBookingReference: second.BookingReference,
+	})
+	require.NoError(t, err, "error consenting to swap")
+
+	clock.Advance(swapConsentTTL)
+	resp, err := server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+		BookingReference:      second.BookingReference,
+		OtherBookingReference: first.BookingReference,
+	})
+	require.NoError(t, err, "error consenting to swap")
+	assert.Equal(t, pb.SwapStatus_SWAP_STATUS_PENDING, resp.Status, "expired consent must not complete a swap")
+}
+
+func TestSwapSeatsInvalidatedByMove(t *testing.T) {
+	server := NewServer()
+	first, err := server.PurchaseTicket(context.Background(), purchaseRequest("first@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	second, err := server.PurchaseTicket(context.Background(), purchaseRequest("second@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+
+	_, err = server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+		BookingReference:      first.BookingReference,
+		OtherBookingReference: second.BookingReference,
+	})
+	require.NoError(t, err, "error consenting to swap")
+	_, err = server.ModifyUserSeat(context.Background(), &pb.ModifySeatRequest{
+		BookingReference: second.BookingReference,
+		NewSection:       "B",
+		NewSeat:          10,
+	})
+	require.NoError(t, err, "error modifying seat")
+
+	resp, err := server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+		BookingReference:      second.BookingReference,
+		OtherBookingReference: first.BookingReference,
+	})
+	require.NoError(t, err, "error consenting to swap")
+	assert.Equal(t, pb.SwapStatus_SWAP_STATUS_PENDING, resp.Status, "consent was given for a different seat")
+}
+
+func TestSwapSeatsRespectsOtherLegs(t *testing.T) {
+	server := NewServer()
+	// Seat A2 is sold London-Paris to one passenger and Paris-Brussels to a
+	// third party, so a through passenger cannot move into it.
+	twoSeat, err := server.CreateDeparture(context.Background(), &pb.CreateDepartureRequest{
+		TrainId:     "EUR9102",
+		Date:        "2026-11-01",
+		Origin:      "London",
+		Destination: "Brussels",
+		Via:         []string{"Paris"},
+		Sections:    []*pb.SectionLayout{{Name: "A", Seats: 2, SeatClass: "standard"}},
+	})
+	require.NoError(t, err, "error creating departure")
+
+	whole, err := buyLeg(server, twoSeat.Id, "whole@example.com", "London", "Brussels")
+	require.NoError(t, err, "error purchasing ticket")
+	short, err := buyLeg(server, twoSeat.Id, "short@example.com", "London", "Paris")
+	require.NoError(t, err, "error purchasing ticket")
+	_, err = buyLeg(server, twoSeat.Id, "later@example.com", "Paris", "Brussels")
+	require.NoError(t, err, "error purchasing ticket")
+	require.Equal(t, int32(2), short.Seat.Seat)
+
+	_, err = server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+		BookingReference:      whole.BookingReference,
+		OtherBookingReference: short.BookingReference,
+	})
+	require.NoError(t, err, "error consenting to swap")
+	_, err = server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+		BookingReference:      short.BookingReference,
+		OtherBookingReference: whole.BookingReference,
+	})
+	assertCode(t, err, codes.AlreadyExists, "seat A2 is sold Paris-Brussels so cannot take a through passenger")
+}
+
+func TestSwapSeatsAcrossDepartures(t *testing.T) {
+	server := NewServer()
+	departure := newRouteDeparture(t, server)
+	first, err := server.PurchaseTicket(context.Background(), purchaseRequest("first@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	second, err := buyLeg(server, departure.Id, "second@example.com", "London", "Paris")
+	require.NoError(t, err, "error purchasing ticket")
+
+	_, err = server.SwapSeats(context.Background(), &pb.SwapSeatsRequest{
+		BookingReference:      first.BookingReference,
+		OtherBookingReference: second.BookingReference,
+	})
+	assertCode(t, err, codes.FailedPrecondition)
+}
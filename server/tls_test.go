@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"test_train/auth"
+	"test_train/auth/authtest"
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// tlsFixture is a throwaway CA with a server certificate written to disk.
+type tlsFixture struct {
+	dir                   string
+	ca                    *authtest.CA
+	caFile                string
+	serverCert, serverKey string
+}
+
+func newTLSFixture(t *testing.T) *tlsFixture {
+	dir := t.TempDir()
+	ca := authtest.NewCA(t, "test CA")
+	certFile, keyFile := ca.Server(t).Write(t, dir, "server")
+	return &tlsFixture{dir: dir, ca: ca, caFile: ca.WriteCA(t, dir), serverCert: certFile, serverKey: keyFile}
+}
+
+// serve serves server with TLS, verifying client certificates if clientCA
+// is set and requiring them if requireClientCert is.
+func (f *tlsFixture) serve(t *testing.T, server *server, clientCA bool, requireClientCert bool) *bufconn.Listener {
+	caFile := ""
+	if clientCA {
+		caFile = f.caFile
+	}
+	reloader, err := auth.NewCertReloader(f.serverCert, f.serverKey, caFile)
+	require.NoError(t, err, "error loading server certificate")
+	return serveTestServer(t, server, grpc.Creds(credentials.NewTLS(reloader.ServerConfig(requireClientCert))))
+}
+
+// dial connects to listener trusting the fixture's CA, presenting cert if
+// it is not nil.
+func (f *tlsFixture) dial(t *testing.T, listener *bufconn.Listener, cert *authtest.Cert) pb.TrainServiceClient {
+	var certFile, keyFile string
+	if cert != nil {
+		certFile, keyFile = cert.Write(t, t.TempDir(), "client")
+	}
+	config, err := auth.ClientConfig(f.caFile, certFile, keyFile, "localhost")
+	require.NoError(t, err, "error loading client TLS configuration")
+	return dialListener(t, listener, credentials.NewTLS(config))
+}
+
+func TestTLS(t *testing.T) {
+	f := newTLSFixture(t)
+	listener := f.serve(t, NewServer(), false, false)
+
+	_, err := f.dial(t, listener, nil).ListDepartures(context.Background(), &pb.ListDeparturesRequest{})
+	assert.NoError(t, err, "error listing departures over TLS")
+
+	untrusted := authtest.NewCA(t, "other CA")
+	config, err := auth.ClientConfig(untrusted.WriteCA(t, t.TempDir()), "", "", "localhost")
+	require.NoError(t, err, "error loading client TLS configuration")
+	_, err = dialListener(t, listener, credentials.NewTLS(config)).ListDepartures(context.Background(), &pb.ListDeparturesRequest{})
+	assertCode(t, err, codes.Unavailable, "server certificate from an untrusted CA")
+}
+
+func TestMutualTLSRequiresClientCertificate(t *testing.T) {
+	f := newTLSFixture(t)
+	listener := f.serve(t, NewServer(WithClientCertIdentity()), true, true)
+	ctx := context.Background()
+
+	_, err := f.dial(t, listener, nil).ListDepartures(ctx, &pb.ListDeparturesRequest{})
+	assertCode(t, err, codes.Unavailable, "connection without a client certificate")
+
+	foreign := authtest.NewCA(t, "other CA").Client(t, "mallory", "")
+	_, err = f.dial(t, listener, &foreign).ListDepartures(ctx, &pb.ListDeparturesRequest{})
+	assertCode(t, err, codes.Unavailable, "client certificate from an untrusted CA")
+
+	cert := f.ca.Client(t, "john", "john.doe@example.com")
+	_, err = f.dial(t, listener, &cert).ListDepartures(ctx, &pb.ListDeparturesRequest{})
+	assert.NoError(t, err, "error listing departures with a client certificate")
+}
+
+func TestClientCertificateIdentity(t *testing.T) {
+	f := newTLSFixture(t)
+	keys := testKeySet()
+	listener := f.serve(t, NewServer(WithClientCertIdentity(), WithKeySet(keys)), true, false)
+	ctx := context.Background()
+
+	johnCert := f.ca.Client(t, "john", "john.doe@example.com")
+	john := f.dial(t, listener, &johnCert)
+	conductorCert := f.ca.Client(t, "conductor-7", "", string(auth.RoleConductor))
+	conductor := f.dial(t, listener, &conductorCert)
+	anonymous := f.dial(t, listener, nil)
+
+	_, err := anonymous.ListDepartures(ctx, &pb.ListDeparturesRequest{})
+	assertCode(t, err, codes.Unauthenticated, "no certificate and no token")
+
+	receipt, err := john.PurchaseTicket(ctx, purchaseRequest("john.doe@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	_, err = john.PurchaseTicket(ctx, purchaseRequest("jane.smith@example.com"))
+	assertCode(t, err, codes.PermissionDenied, "certificate identity should limit passengers to their own bookings")
+
+	_, err = john.GetUsersBySection(ctx, &pb.SectionRequest{Section: "A"})
+	assertCode(t, err, codes.PermissionDenied, "passenger certificate listing a section")
+	users, err := conductor.GetUsersBySection(ctx, &pb.SectionRequest{Section: "A"})
+	require.NoError(t, err, "error listing section")
+	assert.Len(t, users.Users, 1)
+
+	// Tokens work without a certificate and take precedence over one
+	_, err = anonymous.GetReceipt(ctx, &pb.UserRequest{BookingReference: receipt.BookingReference},
+		bearer(t, keys, auth.RolePassenger, "john", "john.doe@example.com"))
+	assert.NoError(t, err, "error fetching receipt with a token")
+	_, err = conductor.RemoveUser(ctx, &pb.UserRequest{BookingReference: receipt.BookingReference},
+		bearer(t, keys, auth.RoleAdmin, "ops", ""))
+	assert.NoError(t, err, "error removing user with an admin token")
+}
+
+func TestGatewayClientCertificateIdentity(t *testing.T) {
+	f := newTLSFixture(t)
+	handler, stop, err := newGateway(NewServer(WithClientCertIdentity()))
+	require.NoError(t, err, "error starting gateway")
+	reloader, err := auth.NewCertReloader(f.serverCert, f.serverKey, f.caFile)
+	require.NoError(t, err, "error loading server certificate")
+	httpServer := httptest.NewUnstartedServer(handler)
+	httpServer.TLS = httpTLSConfig(reloader.ServerConfig(false))
+	httpServer.StartTLS()
+	t.Cleanup(func() {
+		httpServer.Close()
+		stop()
+	})
+
+	johnCert := f.ca.Client(t, "john", "john.doe@example.com")
+	post := func(cert *authtest.Cert, body string, header ...string) int {
+		t.Helper()
+		var certFile, keyFile string
+		if cert != nil {
+			certFile, keyFile = cert.Write(t, t.TempDir(), "client")
+		}
+		config, err := auth.ClientConfig(f.caFile, certFile, keyFile, "localhost")
+		require.NoError(t, err, "error loading client TLS configuration")
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+		req, err := http.NewRequest("POST", httpServer.URL+"/v1/tickets", strings.NewReader(body))
+		require.NoError(t, err, "error building request")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err, "error calling gateway")
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, post(&johnCert, johnJSON), "error purchasing ticket with a client certificate")
+	assert.Equal(t, http.StatusForbidden, post(&johnCert, strings.Replace(johnJSON, "john.doe", "jane.smith", 1)),
+		"certificate identity should limit passengers to their own bookings")
+	assert.Equal(t, http.StatusUnauthorized, post(nil, johnJSON), "no certificate and no token")
+
+	block, _ := pem.Decode(johnCert.CertPEM)
+	forged := base64.StdEncoding.EncodeToString(block.Bytes)
+	assert.Equal(t, http.StatusUnauthorized, post(nil, johnJSON, "Grpc-Metadata-Gateway-Client-Cert-Bin", forged),
+		"a header must not stand in for a client certificate")
+}
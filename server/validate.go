@@ -0,0 +1,545 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// validation collects the violations found in one request.
+type validation struct {
+	ctx        context.Context
+	s          *server
+	msg        protoreflect.Message
+	violations []*errdetails.BadRequest_FieldViolation
+}
+
+func (v *validation) fail(field, format string, args ...any) {
+	v.violations = append(v.violations, trainerr.FieldViolation(field, format, args...))
+}
+
+// failed reports whether field already has a violation, so later rules do
+// not pile more complaints onto the same field.
+func (v *validation) failed(field string) bool {
+	for _, violation := range v.violations {
+		if violation.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// get resolves a dotted field path such as "user.email". Unset messages on
+// the path yield an invalid Value.
+func (v *validation) get(path string) protoreflect.Value {
+	msg := v.msg
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(part))
+		if fd == nil {
+			panic("validate: unknown field " + path)
+		}
+		if i == len(parts)-1 {
+			return msg.Get(fd)
+		}
+		if !msg.Has(fd) {
+			return protoreflect.Value{}
+		}
+		msg = msg.Get(fd).Message()
+	}
+	return protoreflect.Value{}
+}
+
+// field returns the descriptor of a dotted field path.
+func (v *validation) field(path string) protoreflect.FieldDescriptor {
+	desc := v.msg.Descriptor()
+	var fd protoreflect.FieldDescriptor
+	for _, part := range strings.Split(path, ".") {
+		fd = desc.Fields().ByName(protoreflect.Name(part))
+		if fd == nil {
+			panic("validate: unknown field " + path)
+		}
+		desc = fd.Message()
+	}
+	return fd
+}
+
+func (v *validation) str(path string) string {
+	if value := v.get(path); value.IsValid() {
+		return value.String()
+	}
+	return ""
+}
+
+// rule checks one aspect of a request.
+type rule func(v *validation)
+
+// requestRules declares the validation applied to every TrainService
+// request before it reaches a handler.
+var requestRules = map[protoreflect.FullName][]rule{
+	"train.PurchaseTicketRequest": {
+		present("user"),
+		required("user.first_name"), required("user.last_name"),
+		required("user.email"), email("user.email"),
+		required("from"), required("to"),
+		knownStations(departureField("departure_id"), "from", "to"),
+		definedEnum("passenger_type"),
+		definedEnum("preferences.position"),
+		knownSeat(departureField("departure_id"), "preferences.section", ""),
+		bookingReference("preferences.companion_booking_reference"),
+		idempotencyKey("idempotency_key"),
+	},
+	"train.PurchaseGroupTicketRequest": {
+		passengers("passengers"),
+		required("from"), required("to"),
+		knownStations(departureField("departure_id"), "from", "to"),
+		definedEnum("preferences.position"),
+		knownSeat(departureField("departure_id"), "preferences.section", ""),
+		bookingReference("preferences.companion_booking_reference"),
+		idempotencyKey("idempotency_key"),
+	},
+	"train.UserRequest": {
+		anyOf("email", "booking_reference"),
+		email("email"), bookingReference("booking_reference"),
+		nonNegative("expected_version"),
+	},
+	"train.SectionRequest": {
+		required("section"),
+		knownSeat(departureField("departure_id"), "section", ""),
+	},
+	"train.ModifySeatRequest": {
+		anyOf("email", "booking_reference"),
+		email("email"), bookingReference("booking_reference"),
+		required("new_section"),
+		knownSeat(ticketDeparture("departure_id", "booking_reference", "email"), "new_section", ""),
+		unless("any_seat", knownSeat(ticketDeparture("departure_id", "booking_reference", "email"), "new_section", "new_seat")),
+		idempotencyKey("idempotency_key"),
+		nonNegative("expected_version"),
+	},
+	"train.SwapSeatsRequest": {
+		anyOf("email", "booking_reference"),
+		email("email"), bookingReference("booking_reference"),
+		required("other_booking_reference"),
+		email("other_email"), bookingReference("other_booking_reference"),
+		idempotencyKey("idempotency_key"),
+		nonNegative("expected_version"),
+	},
+	"train.CreateDepartureRequest": {
+		required("train_id"), date("date"), required("date"), clock("time"),
+		required("origin"), required("destination"),
+		sections("sections"), positiveEach("segment_km"),
+		idempotencyKey("idempotency_key"),
+	},
+	"train.ListDeparturesRequest": {
+		date("date"),
+	},
+	"train.DepartureRequest": {
+		required("departure_id"),
+	},
+	"train.SeatMapRequest": {
+		allOrNone("from", "to"),
+		knownStations(departureField("departure_id"), "from", "to"),
+	},
+	"train.QuoteFareRequest": {
+		required("from"), required("to"),
+		knownStations(departureField("departure_id"), "from", "to"),
+		definedEnum("passenger_type"),
+	},
+	"train.HoldRequest": {
+		required("token"),
+		idempotencyKey("idempotency_key"),
+	},
+	"train.CancelTicketRequest": {
+		anyOf("email", "booking_reference"),
+		email("email"), bookingReference("booking_reference"),
+		idempotencyKey("idempotency_key"),
+		nonNegative("expected_version"),
+	},
+	"train.BookingHistoryRequest": {
+		required("booking_reference"), bookingReference("booking_reference"),
+	},
+	"train.WaitlistRequest": {
+		present("user"),
+		required("user.first_name"), required("user.last_name"),
+		required("user.email"), email("user.email"),
+		required("from"), required("to"),
+		knownStations(departureField("departure_id"), "from", "to"),
+		definedEnum("passenger_type"),
+		idempotencyKey("idempotency_key"),
+	},
+	"train.WatchAvailabilityRequest": {
+		allOrNone("from", "to"),
+		knownStations(departureField("departure_id"), "from", "to"),
+	},
+	"train.WaitlistEntryRequest": {
+		required("entry_id"),
+	},
+}
+
+// idempotencyKey limits an idempotency key to short printable ASCII.
+func idempotencyKey(path string) rule {
+	return func(v *validation) {
+		if problem := validIdempotencyKey(v.str(path)); problem != "" {
+			v.fail(path, "%s", problem)
+		}
+	}
+}
+
+// present requires a message field to be set.
+func present(path string) rule {
+	return func(v *validation) {
+		if !v.get(path).IsValid() || !v.get(path).Message().IsValid() {
+			v.fail(path, "is required")
+		}
+	}
+}
+
+// required requires a string field to be non-blank.
+func required(path string) rule {
+	return func(v *validation) {
+		if strings.TrimSpace(v.str(path)) == "" && !v.failed(path) {
+			v.fail(path, "is required")
+		}
+	}
+}
+
+// anyOf requires at least one of the string fields to be set.
+func anyOf(paths ...string) rule {
+	return func(v *validation) {
+		for _, path := range paths {
+			if v.str(path) != "" {
+				return
+			}
+		}
+		v.fail(paths[0], "one of %s is required", strings.Join(paths, ", "))
+	}
+}
+
+// allOrNone requires the string fields to be set together or not at all.
+func allOrNone(paths ...string) rule {
+	return func(v *validation) {
+		set := 0
+		for _, path := range paths {
+			if v.str(path) != "" {
+				set++
+			}
+		}
+		if set != 0 && set != len(paths) {
+			v.fail(paths[0], "%s must be given together", strings.Join(paths, " and "))
+		}
+	}
+}
+
+// unless applies check only when the boolean field flag is false.
+func unless(flag string, check rule) rule {
+	return func(v *validation) {
+		if !v.get(flag).Bool() {
+			check(v)
+		}
+	}
+}
+
+// email checks the format of an email field when it is set.
+func email(path string) rule {
+	return func(v *validation) {
+		if value := v.str(path); value != "" && !emailPattern.MatchString(value) {
+			v.fail(path, "must be a valid email address")
+		}
+	}
+}
+
+// bookingReference checks the format of a booking reference when set.
+func bookingReference(path string) rule {
+	return func(v *validation) {
+		value := v.str(path)
+		if value == "" {
+			return
+		}
+		if len(value) != 6 || strings.Trim(value, referenceAlphabet) != "" {
+			v.fail(path, "must be a six-character booking reference")
+		}
+	}
+}
+
+// date checks a YYYY-MM-DD field when it is set.
+func date(path string) rule {
+	return func(v *validation) {
+		if value := v.str(path); value != "" {
+			if _, err := time.Parse(time.DateOnly, value); err != nil {
+				v.fail(path, "must be a date in YYYY-MM-DD format")
+			}
+		}
+	}
+}
+
+// clock checks an HH:MM field when it is set.
+func clock(path string) rule {
+	return func(v *validation) {
+		if value := v.str(path); value != "" {
+			if _, err := time.Parse("15:04", value); err != nil {
+				v.fail(path, "must be a time in HH:MM format")
+			}
+		}
+	}
+}
+
+// definedEnum rejects enum numbers the schema does not define.
+func definedEnum(path string) rule {
+	return func(v *validation) {
+		value := v.get(path)
+		if !value.IsValid() {
+			return
+		}
+		if v.field(path).Enum().Values().ByNumber(value.Enum()) == nil {
+			v.fail(path, "unknown value %d", value.Enum())
+		}
+	}
+}
+
+// nonNegative rejects a negative integer field.
+func nonNegative(path string) rule {
+	return func(v *validation) {
+		if value := v.get(path); value.IsValid() && value.Int() < 0 {
+			v.fail(path, "must not be negative")
+		}
+	}
+}
+
+// positiveEach requires every element of a repeated integer field to be
+// positive.
+func positiveEach(path string) rule {
+	return func(v *validation) {
+		list := v.get(path).List()
+		for i := 0; i < list.Len(); i++ {
+			if list.Get(i).Int() <= 0 {
+				v.fail(path, "element %d must be positive", i)
+			}
+		}
+	}
+}
+
+// sections checks every section layout in a repeated SectionLayout field.
+func sections(path string) rule {
+	return func(v *validation) {
+		list := v.get(path).List()
+		seen := make(map[string]bool)
+		for i := 0; i < list.Len(); i++ {
+			section := list.Get(i).Message().Interface().(*pb.SectionLayout)
+			if err := validateSection(section); err != nil {
+				v.fail(path, "%v", err)
+			} else if seen[section.Name] {
+				v.fail(path, "section %s is defined twice", section.Name)
+			}
+			seen[section.Name] = true
+		}
+	}
+}
+
+// passengers checks every passenger of a group booking. Emails must be
+// unique within the group so each ticket can be addressed on its own.
+func passengers(path string) rule {
+	return func(v *validation) {
+		list := v.get(path).List()
+		if list.Len() == 0 {
+			v.fail(path, "at least one passenger is required")
+			return
+		}
+		seen := make(map[string]int)
+		for i := 0; i < list.Len(); i++ {
+			passenger := list.Get(i).Message().Interface().(*pb.GroupPassenger)
+			field := fmt.Sprintf("%s[%d]", path, i)
+			if passenger.User == nil {
+				v.fail(field+".user", "is required")
+				continue
+			}
+			if strings.TrimSpace(passenger.User.FirstName) == "" {
+				v.fail(field+".user.first_name", "is required")
+			}
+			if strings.TrimSpace(passenger.User.LastName) == "" {
+				v.fail(field+".user.last_name", "is required")
+			}
+			switch email := passenger.User.Email; {
+			case email == "":
+				v.fail(field+".user.email", "is required")
+			case !emailPattern.MatchString(email):
+				v.fail(field+".user.email", "must be a valid email address")
+			default:
+				if j, ok := seen[email]; ok {
+					v.fail(field+".user.email", "duplicates passengers[%d]", j)
+				}
+				seen[email] = i
+			}
+			if pb.PassengerType_name[int32(passenger.PassengerType)] == "" {
+				v.fail(field+".passenger_type", "unknown value %d", passenger.PassengerType)
+			}
+		}
+	}
+}
+
+// departureResolver finds the departure a request refers to, or nil if it
+// cannot be determined; rules that need it then defer to the handler.
+type departureResolver func(v *validation) *pb.Departure
+
+// departureField resolves the departure named by a field, with an empty id
+// meaning the default departure.
+func departureField(path string) departureResolver {
+	return func(v *validation) *pb.Departure {
+		id := v.str(path)
+		if id == "" {
+			id = defaultDepartureID
+		}
+		departure, err := v.s.store.GetDeparture(id)
+		if err != nil {
+			return nil
+		}
+		return departure
+	}
+}
+
+// ticketDeparture is departureField, falling back to the departure of the
+// ticket identified by the reference and email fields, if the caller may
+// see it.
+func ticketDeparture(departurePath, referencePath, emailPath string) departureResolver {
+	return func(v *validation) *pb.Departure {
+		if v.str(departurePath) != "" {
+			return departureField(departurePath)(v)
+		}
+		receipt, err := v.s.findTicket(v.str(referencePath), v.str(emailPath), canRead(v.ctx))
+		if err != nil {
+			return nil
+		}
+		departure, err := v.s.departure(receipt.DepartureId)
+		if err != nil {
+			return nil
+		}
+		return departure
+	}
+}
+
+// knownStations requires the station fields to be calling points of the
+// departure, in the direction of travel.
+func knownStations(resolve departureResolver, fromPath, toPath string) rule {
+	return func(v *validation) {
+		from, to := v.str(fromPath), v.str(toPath)
+		if from == "" && to == "" {
+			return
+		}
+		departure := resolve(v)
+		if departure == nil {
+			return
+		}
+		index := make(map[string]int)
+		for i, station := range routeStations(departure) {
+			index[station] = i
+		}
+
+		fromIdx, fromOK := index[from]
+		toIdx, toOK := index[to]
+		if from != "" && !fromOK {
+			v.fail(fromPath, "departure %s does not call at %q", departure.Id, from)
+		}
+		if to != "" && !toOK {
+			v.fail(toPath, "departure %s does not call at %q", departure.Id, to)
+		}
+		if fromOK && toOK && fromIdx >= toIdx {
+			v.fail(toPath, "must be after %s on the route", from)
+		}
+	}
+}
+
+// knownSeat requires the section field to name a section of the departure
+// and, if seatPath is given, the seat to be within that section.
+func knownSeat(resolve departureResolver, sectionPath, seatPath string) rule {
+	return func(v *validation) {
+		name := v.str(sectionPath)
+		if name == "" {
+			return
+		}
+		departure := resolve(v)
+		if departure == nil {
+			return
+		}
+		section := findSection(departure, name)
+		if section == nil {
+			if v.failed(sectionPath) {
+				return
+			}
+			v.fail(sectionPath, "section %s does not exist on departure %s", name, departure.Id)
+			return
+		}
+		if seatPath == "" {
+			return
+		}
+		if seat := int32(v.get(seatPath).Int()); seat < 1 || seat > section.Seats {
+			v.fail(seatPath, "must be between 1 and %d", section.Seats)
+		}
+	}
+}
+
+// validate applies the rules declared for req's message type, for the
+// caller in ctx.
+func (s *server) validate(ctx context.Context, req proto.Message) error {
+	msg := req.ProtoReflect()
+	rules := requestRules[msg.Descriptor().FullName()]
+	if len(rules) == 0 {
+		return nil
+	}
+
+	v := &validation{ctx: ctx, s: s, msg: msg}
+	for _, check := range rules {
+		check(v)
+	}
+	if len(v.violations) > 0 {
+		return trainerr.InvalidArgument(v.violations...)
+	}
+	return nil
+}
+
+// validationInterceptor rejects invalid requests with InvalidArgument
+// before they reach a handler.
+func (s *server) validationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if msg, ok := req.(proto.Message); ok {
+			if err := s.validate(ctx, msg); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// validationStreamInterceptor applies the same rules to the request
+// messages of streaming calls.
+func (s *server) validationStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss, s: s})
+	}
+}
+
+// validatingStream validates each message as it is received.
+type validatingStream struct {
+	grpc.ServerStream
+	s *server
+}
+
+func (v *validatingStream) RecvMsg(m any) error {
+	if err := v.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(proto.Message); ok {
+		return v.s.validate(v.Context(), msg)
+	}
+	return nil
+}
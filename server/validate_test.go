@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// violatedFields returns the field paths reported by an InvalidArgument error.
+func violatedFields(err error) []string {
+	var fields []string
+	for _, violation := range trainerr.FieldViolations(err) {
+		fields = append(fields, violation.Field)
+	}
+	return fields
+}
+
+func TestEveryRequestHasRules(t *testing.T) {
+	methods := pb.File_train_schema_proto.Services().ByName("TrainService").Methods()
+	for i := 0; i < methods.Len(); i++ {
+		input := methods.Get(i).Input().FullName()
+		_, ok := requestRules[input]
+		assert.True(t, ok, "no validation rules for %s", input)
+	}
+}
+
+func TestValidatePurchaseTicket(t *testing.T) {
+	server := NewServer()
+
+	err := server.validate(context.Background(), &pb.PurchaseTicketRequest{From: "London", To: "France"})
+	assertCode(t, err, codes.InvalidArgument, "nil user should be rejected")
+	assert.Equal(t, []string{"user", "user.first_name", "user.last_name", "user.email"}, violatedFields(err))
+
+	err = server.validate(context.Background(), &pb.PurchaseTicketRequest{
+		User: &pb.User{FirstName: " ", LastName: "Doe", Email: "not-an-email"},
+		From: "London",
+		To:   "Berlin",
+	})
+	assertCode(t, err, codes.InvalidArgument)
+	assert.Equal(t, []string{"user.first_name", "user.email", "to"}, violatedFields(err))
+
+	err = server.validate(context.Background(), &pb.PurchaseTicketRequest{
+		User:          &pb.User{FirstName: "John", LastName: "Doe", Email: "john@example.com"},
+		From:          "France",
+		To:            "London",
+		PassengerType: pb.PassengerType(42),
+	})
+	assertCode(t, err, codes.InvalidArgument)
+	assert.Equal(t, []string{"to", "passenger_type"}, violatedFields(err))
+
+	assert.NoError(t, server.validate(context.Background(), purchaseRequest("john@example.com")))
+}
+
+func TestValidateModifySeat(t *testing.T) {
+	server := NewServer()
+	receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("john@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+
+	err = server.validate(context.Background(), &pb.ModifySeatRequest{NewSection: "A", NewSeat: 1})
+	assertCode(t, err, codes.InvalidArgument, "missing ticket identifier")
+	assert.Equal(t, []string{"email"}, violatedFields(err))
+
+	err = server.validate(context.Background(), &pb.ModifySeatRequest{BookingReference: receipt.BookingReference, NewSection: "Z", NewSeat: 1})
+	assertCode(t, err, codes.InvalidArgument, "unknown section")
+	assert.Equal(t, []string{"new_section"}, violatedFields(err))
+
+	err = server.validate(context.Background(), &pb.ModifySeatRequest{BookingReference: receipt.BookingReference, NewSection: "B", NewSeat: 51})
+	assertCode(t, err, codes.InvalidArgument, "seat out of range")
+	assert.Equal(t, []string{"new_seat"}, violatedFields(err))
+
+	err = server.validate(context.Background(), &pb.ModifySeatRequest{BookingReference: "abc", NewSection: "B", NewSeat: 2})
+	assertCode(t, err, codes.InvalidArgument, "malformed booking reference")
+	assert.Equal(t, []string{"booking_reference"}, violatedFields(err))
+
+	assert.NoError(t, server.validate(context.Background(), &pb.ModifySeatRequest{BookingReference: receipt.BookingReference, NewSection: "B", NewSeat: 2}))
+}
+
+func TestValidateUsesDepartureLayout(t *testing.T) {
+	server := NewServer()
+	departure := newRouteDeparture(t, server)
+
+	err := server.validate(context.Background(), &pb.SectionRequest{Section: "B", DepartureId: departure.Id})
+	assertCode(t, err, codes.InvalidArgument, "section B is not on the route departure")
+	assert.NoError(t, server.validate(context.Background(), &pb.SectionRequest{Section: "B"}))
+
+	err = server.validate(context.Background(), &pb.SeatMapRequest{DepartureId: departure.Id, From: "Paris"})
+	assertCode(t, err, codes.InvalidArgument, "from without to")
+	assert.NoError(t, server.validate(context.Background(), &pb.SeatMapRequest{DepartureId: departure.Id, From: "Paris", To: "Brussels"}))
+}
+
+func TestValidateCreateDeparture(t *testing.T) {
+	server := NewServer()
+	err := server.validate(context.Background(), &pb.CreateDepartureRequest{
+		Date:      "01/11/2026",
+		Time:      "25:00",
+		Origin:    "London",
+		Sections:  []*pb.SectionLayout{{Name: "A", Seats: 0}},
+		SegmentKm: []int32{-1},
+	})
+	assertCode(t, err, codes.InvalidArgument)
+	assert.Equal(t, []string{"train_id", "date", "time", "destination", "sections", "segment_km"}, violatedFields(err))
+}
+
+func TestValidationInterceptor(t *testing.T) {
+	server := NewServer()
+	interceptor := server.validationInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/train.TrainService/PurchaseTicket"}
+
+	called := false
+	handler := func(ctx context.Context, req any) (any, error) {
+		called = true
+		return server.PurchaseTicket(ctx, req.(*pb.PurchaseTicketRequest))
+	}
+
+	_, err := interceptor(context.Background(), &pb.PurchaseTicketRequest{}, info, handler)
+	assertCode(t, err, codes.InvalidArgument, "invalid request should be rejected")
+	assert.False(t, called, "handler should not run for an invalid request")
+
+	resp, err := interceptor(context.Background(), purchaseRequest("john@example.com"), info, handler)
+	require.NoError(t, err, "valid request should reach the handler")
+	assert.True(t, called)
+	assert.True(t, proto.Equal(resp.(*pb.TicketReceipt).User, purchaseRequest("john@example.com").User))
+}
+
+func TestHandlersRejectNilUser(t *testing.T) {
+	server := NewServer()
+
+	_, err := server.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{From: "London", To: "France"})
+	assertCode(t, err, codes.InvalidArgument, "PurchaseTicket without a user")
+	_, err = server.HoldSeat(context.Background(), &pb.PurchaseTicketRequest{From: "London", To: "France"})
+	assertCode(t, err, codes.InvalidArgument, "HoldSeat without a user")
+}
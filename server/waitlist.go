@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Notifier tells customers about changes they did not ask for, such as a
+// waitlist offer.
+type Notifier interface {
+	Notify(user *pb.User, message string)
+}
+
+// logNotifier writes notifications to the server log.
+type logNotifier struct{}
+
+func (logNotifier) Notify(user *pb.User, message string) {
+	log.Printf("Notify %s: %s", user.GetEmail(), message)
+}
+
+// WithNotifier sets where customer notifications are sent.
+func WithNotifier(notifier Notifier) Option {
+	return func(s *server) {
+		s.notifier = notifier
+	}
+}
+
+// waitlistEntry is a customer queued for a full departure. Like holds,
+// waitlists are kept in memory only.
+type waitlistEntry struct {
+	id          string
+	departureID string
+	user        *pb.User
+	request     *pb.PurchaseTicketRequest
+	joined      time.Time
+	// hold is the token of the seat offered to this entry, if any.
+	hold string
+}
+
+// waitlistEntry returns the entry with id on a departure's waitlist, or
+// nil.
+func (s *server) waitlistEntry(departureID, id string) *waitlistEntry {
+	if id == "" {
+		return nil
+	}
+	for _, entry := range s.state(departureID).waitlist {
+		if entry.id == id {
+			return entry
+		}
+	}
+	return nil
+}
+
+// removeWaitlistEntry drops an entry from its departure's queue.
+func (s *server) removeWaitlistEntry(departureID, id string) {
+	state := s.state(departureID)
+	for i, entry := range state.waitlist {
+		if entry.id == id {
+			state.waitlist = append(state.waitlist[:i:i], state.waitlist[i+1:]...)
+			break
+		}
+	}
+	s.index(s.states.entries, id, "")
+}
+
+func (s *server) waitlistProto(entry *waitlistEntry) *pb.WaitlistEntry {
+	msg := &pb.WaitlistEntry{
+		EntryId:     entry.id,
+		DepartureId: entry.departureID,
+		User:        proto.Clone(entry.user).(*pb.User),
+		From:        entry.request.From,
+		To:          entry.request.To,
+		JoinedAt:    timestamppb.New(entry.joined),
+	}
+	for i, queued := range s.state(entry.departureID).waitlist {
+		if queued == entry {
+			msg.Position = int32(i + 1)
+		}
+	}
+	if hold := s.activeHold(entry.departureID, entry.hold); hold != nil {
+		msg.OfferedHold = hold.proto()
+	}
+	return msg
+}
+
+// promoteWaitlist offers freed seats on a departure to waiting customers in
+// the order they joined. An entry whose journey does not fit any free seat
+// keeps its place, and later entries for other legs may be served first.
+func (s *server) promoteWaitlist(departureID string) {
+	for _, entry := range s.state(departureID).waitlist {
+		if s.activeHold(departureID, entry.hold) != nil {
+			continue
+		}
+		hold, err := s.placeHold(entry.request)
+		if err != nil {
+			continue
+		}
+		hold.waitlistEntry = entry.id
+		entry.hold = hold.token
+		s.notifier.Notify(entry.user, fmt.Sprintf(
+			"A seat is available on departure %s: seat %s%d is held for you until %s (hold %s)",
+			departureID, hold.seat.seat.Section, hold.seat.seat.Seat, hold.expires.Format(time.RFC3339), hold.token))
+	}
+}
+
+func (s *server) JoinWaitlist(ctx context.Context, req *pb.WaitlistRequest) (*pb.WaitlistEntry, error) {
+	if req.User == nil {
+		return nil, trainerr.InvalidField("user", "is required")
+	}
+	if err := authorizeUser(ctx, req.User.GetEmail(), true); err != nil {
+		return nil, err
+	}
+
+	defer s.lockDepartures(req.DepartureId)()
+
+	purchase := &pb.PurchaseTicketRequest{
+		User:          req.User,
+		From:          req.From,
+		To:            req.To,
+		DepartureId:   req.DepartureId,
+		PassengerType: req.PassengerType,
+	}
+	departure, err := s.bookableDeparture(req.DepartureId)
+	if err != nil {
+		return nil, err
+	}
+	purchase.DepartureId = departure.Id
+	if _, err := resolveLeg(departure, req.From, req.To); err != nil {
+		return nil, err
+	}
+	if _, err := s.allocate(purchase); err == nil {
+		return nil, trainerr.FailedPrecondition("SEATS_AVAILABLE", departure.Id, "seats are available on departure %s; purchase a ticket instead", departure.Id)
+	}
+	state := s.state(departure.Id)
+	for _, entry := range state.waitlist {
+		if entry.user.GetEmail() == req.User.GetEmail() && entry.request.From == req.From && entry.request.To == req.To {
+			return nil, trainerr.AlreadyExists(trainerr.ResourceWaitlistEntry, req.User.GetEmail(), "%s is already waitlisted for this journey", req.User.GetEmail())
+		}
+	}
+
+	entry := &waitlistEntry{
+		id:          "WL-" + randomString("0123456789ABCDEF", 12),
+		departureID: departure.Id,
+		user:        proto.Clone(req.User).(*pb.User),
+		request:     purchase,
+		joined:      s.now(),
+	}
+	state.waitlist = append(state.waitlist, entry)
+	s.index(s.states.entries, entry.id, departure.Id)
+	return s.waitlistProto(entry), nil
+}
+
+func (s *server) LeaveWaitlist(ctx context.Context, req *pb.WaitlistEntryRequest) (*pb.EmptyResponse, error) {
+	departureID, ok := s.entryDeparture(req.EntryId)
+	var entry *waitlistEntry
+	if ok {
+		defer s.lockDepartures(departureID)()
+		entry = s.waitlistEntry(departureID, req.EntryId)
+	}
+	if entry == nil {
+		return nil, trainerr.NotFound(trainerr.ResourceWaitlistEntry, req.EntryId, "waitlist entry %s not found", req.EntryId)
+	}
+	if err := authorizeUser(ctx, entry.user.GetEmail(), true); err != nil {
+		return nil, err
+	}
+	s.removeWaitlistEntry(departureID, entry.id)
+
+	// Give up any seat offered to the entry so the next customer gets it,
+	// unless it is being paid for already
+	if hold := s.activeHold(departureID, entry.hold); hold != nil && !hold.charging {
+		s.releaseHold(hold, "hold_released")
+		s.promoteWaitlist(departureID)
+	}
+	return &pb.EmptyResponse{}, nil
+}
+
+func (s *server) GetWaitlistPosition(ctx context.Context, req *pb.WaitlistEntryRequest) (*pb.WaitlistEntry, error) {
+	departureID, ok := s.entryDeparture(req.EntryId)
+	var entry *waitlistEntry
+	if ok {
+		defer s.readLockDeparture(departureID)()
+		entry = s.waitlistEntry(departureID, req.EntryId)
+	}
+	if entry == nil {
+		return nil, trainerr.NotFound(trainerr.ResourceWaitlistEntry, req.EntryId, "waitlist entry %s not found", req.EntryId)
+	}
+	if err := authorizeUser(ctx, entry.user.GetEmail(), false); err != nil {
+		return nil, err
+	}
+	return s.waitlistProto(entry), nil
+}
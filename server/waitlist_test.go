@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	pb "test_train/protobuf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+// recordingNotifier keeps every notification for inspection.
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages map[string][]string
+}
+
+func (n *recordingNotifier) Notify(user *pb.User, message string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.messages == nil {
+		n.messages = make(map[string][]string)
+	}
+	n.messages[user.GetEmail()] = append(n.messages[user.GetEmail()], message)
+}
+
+func (n *recordingNotifier) count(email string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.messages[email])
+}
+
+// newSingleSeatServer returns a server whose default departure has one seat.
+func newSingleSeatServer(opts ...Option) *server {
+	return NewServer(append([]Option{WithLayouts(Layouts{
+		Default: "single",
+		ByName: map[string][]*pb.SectionLayout{
+			"single": {{Name: "A", Seats: 1, SeatClass: "standard"}},
+		},
+	})}, opts...)...)
+}
+
+func waitlistRequest(email string) *pb.WaitlistRequest {
+	return &pb.WaitlistRequest{
+		User: &pb.User{FirstName: "Test", LastName: "User", Email: email},
+		From: "London",
+		To:   "France",
+	}
+}
+
+func TestJoinWaitlistOnlyWhenFull(t *testing.T) {
+	server := newSingleSeatServer()
+
+	_, err := server.JoinWaitlist(context.Background(), waitlistRequest("b@example.com"))
+	assertCode(t, err, codes.FailedPrecondition, "waitlist should be refused while seats are free")
+
+	_, err = server.PurchaseTicket(context.Background(), purchaseRequest("a@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+
+	first, err := server.JoinWaitlist(context.Background(), waitlistRequest("b@example.com"))
+	require.NoError(t, err, "error joining waitlist")
+	assert.Equal(t, int32(1), first.Position)
+	second, err := server.JoinWaitlist(context.Background(), waitlistRequest("c@example.com"))
+	require.NoError(t, err, "error joining waitlist")
+	assert.Equal(t, int32(2), second.Position)
+
+	_, err = server.JoinWaitlist(context.Background(), waitlistRequest("b@example.com"))
+	assertCode(t, err, codes.AlreadyExists, "joining twice for the same journey should be refused")
+
+	_, err = server.LeaveWaitlist(context.Background(), &pb.WaitlistEntryRequest{EntryId: first.EntryId})
+	require.NoError(t, err, "error leaving waitlist")
+	entry, err := server.GetWaitlistPosition(context.Background(), &pb.WaitlistEntryRequest{EntryId: second.EntryId})
+	require.NoError(t, err, "error fetching waitlist position")
+	assert.Equal(t, int32(1), entry.Position, "second customer should move to the head")
+}
+
+func TestCancellationPromotesWaitlistHead(t *testing.T) {
+	notifier := &recordingNotifier{}
+	server := newSingleSeatServer(WithNotifier(notifier))
+
+	receipt, err := server.PurchaseTicket(context.Background(), purchaseRequest("a@example.com"))
+	require.NoError(t, err, "error purchasing ticket")
+	head, err := server.JoinWaitlist(context.Background(), waitlistRequest("b@example.com"))
+	require.NoError(t, err, "error joining waitlist")
+	next, err := server.JoinWaitlist(context.Background(), waitlistRequest("c@example.com"))
+	require.NoError(t, err, "error joining waitlist")
+
+	_, err = server.CancelTicket(context.Background(), &pb.CancelTicketRequest{BookingReference: receipt.BookingReference})
+	require.NoError(t, err, "error cancelling ticket")
+
+	assert.Equal(t, 1, notifier.count("b@example.com"), "head should be notified")
+	assert.Zero(t, notifier.count("c@example.com"), "only one seat was freed")
+
+	entry, err := server.GetWaitlistPosition(context.Background(), &pb.WaitlistEntryRequest{EntryId: head.EntryId})
+	require.NoError(t, err, "error fetching waitlist position")
+	require.NotNil(t, entry.OfferedHold, "head should be offered a hold")
+
+	confirmed, err := server.ConfirmHold(context.Background(), &pb.HoldRequest{Token: entry.OfferedHold.Token})
+	require.NoError(t, err, "error confirming offered hold")
+	assert.Equal(t, "b@example.com", confirmed.User.Email)
+
+	_, err = server.GetWaitlistPosition(context.Background(), &pb.WaitlistEntryRequest{EntryId: head.EntryId})
+	assertCode(t, err, codes.NotFound, "confirmed entry should leave the waitlist")
+	entry, err = server.GetWaitlistPosition(context.Background(), &pb.WaitlistEntryRequest{EntryId: next.EntryId})
+	require.NoError(t, err, "error fetching waitlist position")
+	assert.Equal(t, int32(1), entry.Position)
+}
+
+func TestLapsedOfferPassesToNextCustomer(t *testing.T) {
+	clock := newFakeClock()
+	notifier := &recordingNotifier{}
+	server := newSingleSeatServer(WithNotifier(notifier), WithClock(clock.Now), WithHoldTTL(time.Minute))
+
+	hold, err := server.HoldSeat(context.Background(), purchaseRequest("a@example.com"))
+	require.NoError(t, err, "error holding seat")
+	head, err := server.JoinWaitlist(context.Background(), waitlistRequest("b@example.com"))
+	require.NoError(t, err, "error joining waitlist")
+	next, err := server.JoinWaitlist(context.Background(), waitlistRequest("c@example.com"))
+	require.NoError(t, err, "error joining waitlist")
+
+	// The unconfirmed hold expires and the head is offered the seat
+	clock.Advance(time.Minute)
+	assert.Equal(t, 1, server.reapExpiredHolds())
+	entry, err := server.GetWaitlistPosition(context.Background(), &pb.WaitlistEntryRequest{EntryId: head.EntryId})
+	require.NoError(t, err, "error fetching waitlist position")
+	require.NotNil(t, entry.OfferedHold, "head should be offered the expired hold's seat")
+	assert.Equal(t, hold.Seat.Seat, entry.OfferedHold.Seat.Seat)
+
+	// The head lets the offer lapse too, so it moves on
+	clock.Advance(time.Minute)
+	assert.Equal(t, 1, server.reapExpiredHolds())
+	_, err = server.GetWaitlistPosition(context.Background(), &pb.WaitlistEntryRequest{EntryId: head.EntryId})
+	assertCode(t, err, codes.NotFound, "lapsed entry should leave the waitlist")
+	assert.Equal(t, 2, notifier.count("b@example.com"), "head should hear about the offer and its expiry")
+
+	entry, err = server.GetWaitlistPosition(context.Background(), &pb.WaitlistEntryRequest{EntryId: next.EntryId})
+	require.NoError(t, err, "error fetching waitlist position")
+	assert.NotNil(t, entry.OfferedHold, "next customer should be offered the seat")
+	assert.Equal(t, 1, notifier.count("c@example.com"))
+}
@@ -0,0 +1,56 @@
+// Command tokengen creates server key sets and issues bearer tokens signed
+// with them, for development and for operators minting staff credentials.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"test_train/auth"
+)
+
+func main() {
+	keysPath := flag.String("keys", "keys.json", "JSON key set to sign with")
+	subject := flag.String("sub", "", "token subject, such as a user or staff id")
+	email := flag.String("email", "", "email of the passenger the token acts for")
+	role := flag.String("role", string(auth.RolePassenger), "role: passenger, conductor or admin")
+	ttl := flag.Duration("ttl", time.Hour, "how long the token is valid")
+	newKeys := flag.Bool("new-keys", false, "write a key set with a new random secret to -keys and exit")
+	flag.Parse()
+
+	if *newKeys {
+		keys, err := auth.NewKeySet("test-train", time.Now().UTC().Format("key-20060102"))
+		if err != nil {
+			log.Fatalf("failed to generate keys: %v", err)
+		}
+		if err := auth.WriteKeySet(*keysPath, keys); err != nil {
+			log.Fatalf("failed to write keys: %v", err)
+		}
+		return
+	}
+
+	if *subject == "" {
+		*subject = *email
+	}
+	if *subject == "" {
+		log.Fatal("-sub or -email is required")
+	}
+	keys, err := auth.LoadKeySet(*keysPath)
+	if err != nil {
+		log.Fatalf("failed to load keys: %v", err)
+	}
+	now := time.Now()
+	token, err := keys.Sign(auth.Claims{
+		Subject:   *subject,
+		Email:     *email,
+		Role:      auth.Role(*role),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(*ttl).Unix(),
+	})
+	if err != nil {
+		log.Fatalf("failed to sign token: %v", err)
+	}
+	fmt.Println(token)
+}
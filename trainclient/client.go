@@ -0,0 +1,342 @@
+// Package trainclient is the Go client for TrainService. It wraps the
+// generated client with what every application would otherwise rebuild:
+//
+//   - a default deadline on calls made without one;
+//   - retries of transient failures, with backoff that honours the delay
+//     the server suggests;
+//   - an idempotency key on every call that changes bookings, reused across
+//     its retries so the change is applied once;
+//   - keepalives that detect dead connections, and Connect to wait for a
+//     working one;
+//   - errors that match ErrNotFound, ErrConflict and the other kinds with
+//     errors.Is, and carry the server's details as an *Error.
+//
+// A Client implements pb.TrainServiceClient, so it can replace the
+// generated client wherever one is used.
+package trainclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"time"
+
+	"test_train/auth"
+	pb "test_train/protobuf"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultTimeout is the deadline given to unary calls made without one.
+const DefaultTimeout = 10 * time.Second
+
+// Keepalive pings are sent after this long without activity, and the
+// connection is closed if one is not answered within keepaliveTimeout.
+// The server permits pings this frequent.
+const (
+	keepaliveTime    = 30 * time.Second
+	keepaliveTimeout = 10 * time.Second
+)
+
+// Client calls TrainService. It is safe for concurrent use.
+type Client struct {
+	service pb.TrainServiceClient
+	// conn is the connection the client dialled or was given, if it can
+	// report its state
+	conn    *grpc.ClientConn
+	owned   bool
+	timeout time.Duration
+	retry   RetryPolicy
+
+	// Dial settings
+	tlsConfig   *tls.Config
+	token       string
+	dialOptions []grpc.DialOption
+}
+
+var _ pb.TrainServiceClient = (*Client)(nil)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTimeout sets the deadline given to unary calls made without one. Zero
+// leaves such calls without a deadline. Streams never get a default
+// deadline.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithTLS makes Dial connect over TLS with config.
+func WithTLS(config *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// WithToken makes Dial send token as a bearer token on every call.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithDialOptions adds options for Dial to pass to grpc.NewClient, after
+// its own.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *Client) {
+		c.dialOptions = append(c.dialOptions, opts...)
+	}
+}
+
+func newClient(opts []Option) *Client {
+	c := &Client{timeout: DefaultTimeout, retry: DefaultRetryPolicy()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Dial creates a client for the server at target, connecting without TLS
+// unless WithTLS is given. The connection is made lazily; see Connect.
+func Dial(target string, opts ...Option) (*Client, error) {
+	c := newClient(opts)
+	creds := insecure.NewCredentials()
+	if c.tlsConfig != nil {
+		creds = credentials.NewTLS(c.tlsConfig)
+	}
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                keepaliveTime,
+			Timeout:             keepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	}
+	if c.token != "" {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(auth.BearerToken(c.token)))
+	}
+	conn, err := grpc.NewClient(target, append(dialOptions, c.dialOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+	c.service = pb.NewTrainServiceClient(conn)
+	c.conn = conn
+	c.owned = true
+	return c, nil
+}
+
+// New creates a client that calls over conn, which the caller keeps
+// ownership of. The TLS, token and dial options only apply to Dial.
+func New(conn grpc.ClientConnInterface, opts ...Option) *Client {
+	c := newClient(opts)
+	c.service = pb.NewTrainServiceClient(conn)
+	c.conn, _ = conn.(*grpc.ClientConn)
+	return c
+}
+
+// Close closes the connection made by Dial. It does nothing for clients
+// created with New.
+func (c *Client) Close() error {
+	if !c.owned {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Connect waits until the client has a working connection to the server,
+// or ctx ends. Calls do not need it, but an application can use it to fail
+// fast at startup or to report readiness. It returns nil at once for
+// clients created with New over a connection that cannot report its
+// state.
+func (c *Client) Connect(ctx context.Context) error {
+	if c.conn == nil {
+		return nil
+	}
+	for {
+		state := c.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Idle:
+			c.conn.Connect()
+		case connectivity.Shutdown:
+			return newError("Connect", 1, status.Error(codes.Canceled, "client closed"))
+		}
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return newError("Connect", 1, status.Errorf(codes.Unavailable, "not connected: %s", state))
+		}
+	}
+}
+
+func (c *Client) PurchaseTicket(ctx context.Context, req *pb.PurchaseTicketRequest, opts ...grpc.CallOption) (*pb.TicketReceipt, error) {
+	return invoke(ctx, c, pb.TrainService_PurchaseTicket_FullMethodName, req, c.service.PurchaseTicket, opts)
+}
+
+func (c *Client) PurchaseGroupTicket(ctx context.Context, req *pb.PurchaseGroupTicketRequest, opts ...grpc.CallOption) (*pb.GroupReceipt, error) {
+	return invoke(ctx, c, pb.TrainService_PurchaseGroupTicket_FullMethodName, req, c.service.PurchaseGroupTicket, opts)
+}
+
+func (c *Client) GetReceipt(ctx context.Context, req *pb.UserRequest, opts ...grpc.CallOption) (*pb.TicketReceipt, error) {
+	return invoke(ctx, c, pb.TrainService_GetReceipt_FullMethodName, req, c.service.GetReceipt, opts)
+}
+
+func (c *Client) GetUsersBySection(ctx context.Context, req *pb.SectionRequest, opts ...grpc.CallOption) (*pb.UsersResponse, error) {
+	return invoke(ctx, c, pb.TrainService_GetUsersBySection_FullMethodName, req, c.service.GetUsersBySection, opts)
+}
+
+func (c *Client) RemoveUser(ctx context.Context, req *pb.UserRequest, opts ...grpc.CallOption) (*pb.EmptyResponse, error) {
+	return invoke(ctx, c, pb.TrainService_RemoveUser_FullMethodName, req, c.service.RemoveUser, opts)
+}
+
+func (c *Client) ModifyUserSeat(ctx context.Context, req *pb.ModifySeatRequest, opts ...grpc.CallOption) (*pb.TicketReceipt, error) {
+	return invoke(ctx, c, pb.TrainService_ModifyUserSeat_FullMethodName, req, c.service.ModifyUserSeat, opts)
+}
+
+func (c *Client) SwapSeats(ctx context.Context, req *pb.SwapSeatsRequest, opts ...grpc.CallOption) (*pb.SwapSeatsResponse, error) {
+	return invoke(ctx, c, pb.TrainService_SwapSeats_FullMethodName, req, c.service.SwapSeats, opts)
+}
+
+func (c *Client) GetSeatMap(ctx context.Context, req *pb.SeatMapRequest, opts ...grpc.CallOption) (*pb.SeatMap, error) {
+	return invoke(ctx, c, pb.TrainService_GetSeatMap_FullMethodName, req, c.service.GetSeatMap, opts)
+}
+
+func (c *Client) ListTicketsForUser(ctx context.Context, req *pb.UserRequest, opts ...grpc.CallOption) (*pb.TicketsResponse, error) {
+	return invoke(ctx, c, pb.TrainService_ListTicketsForUser_FullMethodName, req, c.service.ListTicketsForUser, opts)
+}
+
+func (c *Client) QuoteFare(ctx context.Context, req *pb.QuoteFareRequest, opts ...grpc.CallOption) (*pb.FareBreakdown, error) {
+	return invoke(ctx, c, pb.TrainService_QuoteFare_FullMethodName, req, c.service.QuoteFare, opts)
+}
+
+func (c *Client) HoldSeat(ctx context.Context, req *pb.PurchaseTicketRequest, opts ...grpc.CallOption) (*pb.SeatHold, error) {
+	return invoke(ctx, c, pb.TrainService_HoldSeat_FullMethodName, req, c.service.HoldSeat, opts)
+}
+
+func (c *Client) ConfirmHold(ctx context.Context, req *pb.HoldRequest, opts ...grpc.CallOption) (*pb.TicketReceipt, error) {
+	return invoke(ctx, c, pb.TrainService_ConfirmHold_FullMethodName, req, c.service.ConfirmHold, opts)
+}
+
+func (c *Client) CancelTicket(ctx context.Context, req *pb.CancelTicketRequest, opts ...grpc.CallOption) (*pb.Cancellation, error) {
+	return invoke(ctx, c, pb.TrainService_CancelTicket_FullMethodName, req, c.service.CancelTicket, opts)
+}
+
+func (c *Client) GetBookingHistory(ctx context.Context, req *pb.BookingHistoryRequest, opts ...grpc.CallOption) (*pb.BookingHistory, error) {
+	return invoke(ctx, c, pb.TrainService_GetBookingHistory_FullMethodName, req, c.service.GetBookingHistory, opts)
+}
+
+func (c *Client) JoinWaitlist(ctx context.Context, req *pb.WaitlistRequest, opts ...grpc.CallOption) (*pb.WaitlistEntry, error) {
+	return invoke(ctx, c, pb.TrainService_JoinWaitlist_FullMethodName, req, c.service.JoinWaitlist, opts)
+}
+
+func (c *Client) LeaveWaitlist(ctx context.Context, req *pb.WaitlistEntryRequest, opts ...grpc.CallOption) (*pb.EmptyResponse, error) {
+	return invoke(ctx, c, pb.TrainService_LeaveWaitlist_FullMethodName, req, c.service.LeaveWaitlist, opts)
+}
+
+func (c *Client) GetWaitlistPosition(ctx context.Context, req *pb.WaitlistEntryRequest, opts ...grpc.CallOption) (*pb.WaitlistEntry, error) {
+	return invoke(ctx, c, pb.TrainService_GetWaitlistPosition_FullMethodName, req, c.service.GetWaitlistPosition, opts)
+}
+
+func (c *Client) CreateDeparture(ctx context.Context, req *pb.CreateDepartureRequest, opts ...grpc.CallOption) (*pb.Departure, error) {
+	return invoke(ctx, c, pb.TrainService_CreateDeparture_FullMethodName, req, c.service.CreateDeparture, opts)
+}
+
+func (c *Client) ListDepartures(ctx context.Context, req *pb.ListDeparturesRequest, opts ...grpc.CallOption) (*pb.ListDeparturesResponse, error) {
+	return invoke(ctx, c, pb.TrainService_ListDepartures_FullMethodName, req, c.service.ListDepartures, opts)
+}
+
+func (c *Client) CancelDeparture(ctx context.Context, req *pb.DepartureRequest, opts ...grpc.CallOption) (*pb.Departure, error) {
+	return invoke(ctx, c, pb.TrainService_CancelDeparture_FullMethodName, req, c.service.CancelDeparture, opts)
+}
+
+// WatchAvailability opens a stream of seat changes. If the stream breaks
+// because the server is unavailable or the caller fell behind, Recv
+// reopens it from the last cursor received, within the retry policy's
+// attempts, so callers see one uninterrupted stream.
+func (c *Client) WatchAvailability(ctx context.Context, req *pb.WatchAvailabilityRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.AvailabilityEvent], error) {
+	s := &availabilityStream{c: c, ctx: ctx, req: req, opts: opts}
+	if err := s.open(1); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// availabilityStream resumes a WatchAvailability stream after transient
+// failures.
+type availabilityStream struct {
+	grpc.ServerStreamingClient[pb.AvailabilityEvent]
+	c      *Client
+	ctx    context.Context
+	req    *pb.WatchAvailabilityRequest
+	opts   []grpc.CallOption
+	cursor uint64
+}
+
+// open opens the stream from the last cursor, retrying transient failures
+// from attempt on.
+func (s *availabilityStream) open(attempt int) error {
+	req := s.req
+	if s.cursor != 0 {
+		req = &pb.WatchAvailabilityRequest{DepartureId: s.req.DepartureId, From: s.req.From, To: s.req.To, Cursor: s.cursor}
+	}
+	for ; ; attempt++ {
+		stream, err := s.c.service.WatchAvailability(s.ctx, req, s.opts...)
+		if err == nil {
+			s.ServerStreamingClient = stream
+			return nil
+		}
+		delay, retry := s.c.retry.backoff(attempt, err)
+		if !retry || !s.c.wait(s.ctx, delay) {
+			return newError(pb.TrainService_WatchAvailability_FullMethodName, attempt, err)
+		}
+	}
+}
+
+func (s *availabilityStream) Recv() (*pb.AvailabilityEvent, error) {
+	for attempt := 1; ; attempt++ {
+		event, err := s.ServerStreamingClient.Recv()
+		if err == nil {
+			s.cursor = event.Cursor
+			return event, nil
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if !s.resumable(attempt, err) {
+			return nil, newError(pb.TrainService_WatchAvailability_FullMethodName, attempt, err)
+		}
+		if err := s.open(attempt + 1); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// resumable reports whether the stream should be reopened after err, and
+// waits before it is. A subscriber that fell behind resumes at once.
+func (s *availabilityStream) resumable(attempt int, err error) bool {
+	if attempt >= s.c.retry.MaxAttempts {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.ResourceExhausted:
+		return s.cursor != 0
+	case codes.Unavailable:
+		return s.c.wait(s.ctx, s.c.retry.jitter(attempt))
+	}
+	return false
+}
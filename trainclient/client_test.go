@@ -0,0 +1,288 @@
+package trainclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeTrainService fails calls with the queued errors before answering
+// them, and records what each call carried.
+type fakeTrainService struct {
+	pb.UnimplementedTrainServiceServer
+	mu        sync.Mutex
+	failures  []error
+	keys      []string
+	deadlines []time.Duration
+	watches   []*pb.WatchAvailabilityRequest
+}
+
+// call records ctx and returns the next queued failure, if any.
+func (f *fakeTrainService) call(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	md, _ := metadata.FromIncomingContext(ctx)
+	f.keys = append(f.keys, append(md.Get(IdempotencyKeyHeader), "")[0])
+	var remaining time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining = time.Until(deadline)
+	}
+	f.deadlines = append(f.deadlines, remaining)
+	if len(f.failures) == 0 {
+		return nil
+	}
+	err := f.failures[0]
+	f.failures = f.failures[1:]
+	return err
+}
+
+func (f *fakeTrainService) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.keys)
+}
+
+func (f *fakeTrainService) PurchaseTicket(ctx context.Context, req *pb.PurchaseTicketRequest) (*pb.TicketReceipt, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	return &pb.TicketReceipt{User: req.User, BookingReference: "ABC123"}, nil
+}
+
+func (f *fakeTrainService) GetReceipt(ctx context.Context, req *pb.UserRequest) (*pb.TicketReceipt, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	return &pb.TicketReceipt{User: &pb.User{Email: req.Email}}, nil
+}
+
+// WatchAvailability sends one event after the requested cursor, then fails
+// with the next queued error or ends the stream.
+func (f *fakeTrainService) WatchAvailability(req *pb.WatchAvailabilityRequest, stream grpc.ServerStreamingServer[pb.AvailabilityEvent]) error {
+	f.mu.Lock()
+	f.watches = append(f.watches, req)
+	f.mu.Unlock()
+	if err := stream.Send(&pb.AvailabilityEvent{Cursor: req.Cursor + 1}); err != nil {
+		return err
+	}
+	return f.call(stream.Context())
+}
+
+// testRetryPolicy is DefaultRetryPolicy with backoffs short enough for
+// tests.
+func testRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 10 * time.Millisecond
+	return policy
+}
+
+// startFake serves a fakeTrainService over an in-memory listener and
+// returns a client for it.
+func startFake(t *testing.T, opts ...Option) (*fakeTrainService, *Client, *bufconn.Listener) {
+	listener := bufconn.Listen(1 << 20)
+	fake := &fakeTrainService{}
+	server := grpc.NewServer()
+	pb.RegisterTrainServiceServer(server, fake)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	opts = append([]Option{
+		WithRetryPolicy(testRetryPolicy()),
+		WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		})),
+	}, opts...)
+	client, err := Dial("passthrough:///bufnet", opts...)
+	require.NoError(t, err, "error dialling")
+	t.Cleanup(func() { client.Close() })
+	return fake, client, listener
+}
+
+func TestDefaultDeadline(t *testing.T) {
+	fake, client, _ := startFake(t, WithTimeout(time.Minute))
+
+	_, err := client.GetReceipt(context.Background(), &pb.UserRequest{Email: "john.doe@example.com"})
+	require.NoError(t, err, "error getting receipt")
+	assert.InDelta(t, time.Minute, fake.deadlines[0], float64(5*time.Second), "calls without a deadline should get the default")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	_, err = client.GetReceipt(ctx, &pb.UserRequest{Email: "john.doe@example.com"})
+	require.NoError(t, err, "error getting receipt")
+	assert.Greater(t, fake.deadlines[1], 50*time.Minute, "the caller's deadline should be kept")
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	fake, client, _ := startFake(t)
+	fake.failures = []error{
+		status.Error(codes.Unavailable, "connection reset"),
+		trainerr.Unavailable(time.Millisecond, "payment provider timed out"),
+	}
+
+	receipt, err := client.GetReceipt(context.Background(), &pb.UserRequest{Email: "john.doe@example.com"})
+	require.NoError(t, err, "error getting receipt")
+	assert.Equal(t, "john.doe@example.com", receipt.User.Email)
+	assert.Equal(t, 3, fake.calls())
+}
+
+func TestRetriesAreBounded(t *testing.T) {
+	fake, client, _ := startFake(t)
+	for range 5 {
+		fake.failures = append(fake.failures, status.Error(codes.Unavailable, "down"))
+	}
+
+	_, err := client.GetReceipt(context.Background(), &pb.UserRequest{Email: "john.doe@example.com"})
+	require.ErrorIs(t, err, ErrUnavailable)
+	var clientErr *Error
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, 4, clientErr.Attempts)
+	assert.Equal(t, 4, fake.calls())
+}
+
+func TestDoesNotRetryPermanentFailures(t *testing.T) {
+	fake, client, _ := startFake(t)
+	fake.failures = []error{
+		trainerr.NotFound(trainerr.ResourceUser, "john.doe@example.com", "no ticket for john.doe@example.com"),
+	}
+
+	_, err := client.GetReceipt(context.Background(), &pb.UserRequest{Email: "john.doe@example.com"})
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 1, fake.calls())
+
+	var clientErr *Error
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, pb.TrainService_GetReceipt_FullMethodName, clientErr.Method)
+	assert.Equal(t, &Resource{Type: trainerr.ResourceUser, Name: "john.doe@example.com"}, clientErr.Resource)
+	assert.Equal(t, "GetReceipt: NotFound: no ticket for john.doe@example.com", err.Error())
+}
+
+func TestDoesNotRetryLongSuggestedDelays(t *testing.T) {
+	fake, client, _ := startFake(t)
+	fake.failures = []error{
+		trainerr.ResourceExhausted(trainerr.ResourceDeparture, "dep-1", 10*time.Minute, "Train is full"),
+	}
+
+	_, err := client.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{})
+	require.ErrorIs(t, err, ErrNoCapacity)
+	assert.Equal(t, 1, fake.calls(), "a sold-out train should not be retried")
+	var clientErr *Error
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, 10*time.Minute, clientErr.RetryAfter)
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	fake, client, _ := startFake(t)
+	fake.failures = []error{status.Error(codes.Unavailable, "connection reset")}
+
+	_, err := client.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{})
+	require.NoError(t, err, "error purchasing ticket")
+	require.Len(t, fake.keys, 2)
+	assert.NotEmpty(t, fake.keys[0], "changes should carry an idempotency key")
+	assert.Equal(t, fake.keys[0], fake.keys[1], "retries should reuse the key")
+
+	_, err = client.PurchaseTicket(context.Background(), &pb.PurchaseTicketRequest{})
+	require.NoError(t, err, "error purchasing ticket")
+	assert.NotEqual(t, fake.keys[0], fake.keys[2], "each call should get its own key")
+
+	_, err = client.PurchaseTicket(WithIdempotencyKey(context.Background(), "order-42"), &pb.PurchaseTicketRequest{})
+	require.NoError(t, err, "error purchasing ticket")
+	assert.Equal(t, "order-42", fake.keys[3])
+
+	_, err = client.GetReceipt(context.Background(), &pb.UserRequest{Email: "john.doe@example.com"})
+	require.NoError(t, err, "error getting receipt")
+	assert.Empty(t, fake.keys[4], "reads should not carry a key")
+}
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind error
+	}{
+		{trainerr.InvalidField("user.email", "must be an email address"), ErrInvalidRequest},
+		{trainerr.AlreadyExists(trainerr.ResourceSeat, "A-1", "seat A-1 is taken"), ErrAlreadyExists},
+		{trainerr.FailedPrecondition("DEPARTURE_CANCELLED", "dep-1", "departure is cancelled"), ErrConflict},
+		{status.Error(codes.Aborted, "ticket changed"), ErrConflict},
+		{trainerr.Unauthenticated("missing token"), ErrUnauthenticated},
+		{trainerr.PermissionDenied("passengers may not remove users"), ErrPermissionDenied},
+		{status.Error(codes.DeadlineExceeded, "too slow"), context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		err := newError(pb.TrainService_PurchaseTicket_FullMethodName, 1, tt.err)
+		assert.ErrorIs(t, err, tt.kind, "error %v", tt.err)
+		assert.Equal(t, status.Code(tt.err), trainerr.Code(err), "the status should survive")
+	}
+
+	err := newError(pb.TrainService_PurchaseTicket_FullMethodName, 1, trainerr.InvalidField("user.email", "must be an email address"))
+	var clientErr *Error
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, []FieldViolation{{Field: "user.email", Description: "must be an email address"}}, clientErr.Violations)
+	assert.False(t, errors.Is(err, ErrNotFound))
+
+	plain := errors.New("not a status")
+	assert.Same(t, plain, newError(pb.TrainService_PurchaseTicket_FullMethodName, 1, plain))
+}
+
+func TestWatchResumesFromCursor(t *testing.T) {
+	fake, client, _ := startFake(t)
+	fake.failures = []error{status.Error(codes.Unavailable, "server restarting")}
+
+	stream, err := client.WatchAvailability(context.Background(), &pb.WatchAvailabilityRequest{DepartureId: "dep-1"})
+	require.NoError(t, err, "error watching availability")
+	event, err := stream.Recv()
+	require.NoError(t, err, "error receiving event")
+	assert.Equal(t, uint64(1), event.Cursor)
+	event, err = stream.Recv()
+	require.NoError(t, err, "error receiving event after the stream broke")
+	assert.Equal(t, uint64(2), event.Cursor)
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+
+	require.Len(t, fake.watches, 2)
+	assert.Equal(t, uint64(1), fake.watches[1].Cursor, "the stream should resume from the last cursor")
+	assert.Equal(t, "dep-1", fake.watches[1].DepartureId)
+	assert.Zero(t, fake.deadlines[0], "streams should not get the default deadline")
+}
+
+func TestConnect(t *testing.T) {
+	_, client, listener := startFake(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, client.Connect(ctx), "error connecting")
+
+	require.NoError(t, client.Close())
+	listener.Close()
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, client.Connect(ctx), context.Canceled, "a closed client should not connect")
+}
+
+func TestConnectTimesOut(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	listener.Close()
+	client, err := Dial("passthrough:///bufnet", WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	})))
+	require.NoError(t, err, "error dialling")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, client.Connect(ctx), ErrUnavailable)
+}
@@ -0,0 +1,159 @@
+package trainclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"test_train/trainerr"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Kinds of failure, matched with errors.Is against the errors the client
+// returns. Each covers one or more gRPC codes.
+var (
+	// ErrInvalidRequest: the request failed validation (InvalidArgument,
+	// OutOfRange). Error.Violations names the offending fields.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrNotFound: a user, ticket, departure or other resource does not
+	// exist (NotFound).
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists: the user already has a ticket or the seat is taken
+	// (AlreadyExists).
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrConflict: the current state does not allow the change, such as
+	// booking a cancelled departure (FailedPrecondition), or the ticket has
+	// changed since the expected version was read (Aborted).
+	ErrConflict = errors.New("conflict")
+	// ErrNoCapacity: the train is full or the caller fell behind a stream
+	// (ResourceExhausted). Error.RetryAfter says when capacity may free up.
+	ErrNoCapacity = errors.New("no capacity")
+	// ErrUnauthenticated: the call carried no valid credentials.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrPermissionDenied: the caller's role does not allow the call.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrUnavailable: the server could not be reached or asked to be
+	// retried, and retrying did not help (Unavailable, DeadlineExceeded).
+	ErrUnavailable = errors.New("unavailable")
+)
+
+var kinds = map[codes.Code]error{
+	codes.InvalidArgument:    ErrInvalidRequest,
+	codes.OutOfRange:         ErrInvalidRequest,
+	codes.NotFound:           ErrNotFound,
+	codes.AlreadyExists:      ErrAlreadyExists,
+	codes.FailedPrecondition: ErrConflict,
+	codes.Aborted:            ErrConflict,
+	codes.ResourceExhausted:  ErrNoCapacity,
+	codes.Unauthenticated:    ErrUnauthenticated,
+	codes.PermissionDenied:   ErrPermissionDenied,
+	codes.Unavailable:        ErrUnavailable,
+	codes.DeadlineExceeded:   ErrUnavailable,
+	codes.Canceled:           context.Canceled,
+}
+
+// Error is a failed call. It matches its kind with errors.Is, so callers
+// can write errors.Is(err, trainclient.ErrNotFound), and keeps the details
+// the server attached. DeadlineExceeded also matches
+// context.DeadlineExceeded and Canceled matches context.Canceled.
+type Error struct {
+	// Method is the full gRPC method name of the call.
+	Method string
+	Code   codes.Code
+	// Message is the server's description of the failure.
+	Message string
+	// Resource is the resource that was missing, taken or exhausted, if
+	// the server named one.
+	Resource *Resource
+	// Violations are the invalid fields of an ErrInvalidRequest.
+	Violations []FieldViolation
+	// Preconditions are the violated preconditions of an ErrConflict.
+	Preconditions []Precondition
+	// RetryAfter is the delay the server suggested before trying again;
+	// zero if it suggested none.
+	RetryAfter time.Duration
+	// Attempts is the number of times the call was sent.
+	Attempts int
+
+	status *status.Status
+}
+
+// Resource identifies a resource named by the server, e.g. the departure of
+// a full train.
+type Resource struct {
+	Type string
+	Name string
+}
+
+// FieldViolation is an invalid request field.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Precondition is a precondition the server found violated.
+type Precondition struct {
+	Type        string
+	Subject     string
+	Description string
+}
+
+func (e *Error) Error() string {
+	method := e.Method[strings.LastIndex(e.Method, "/")+1:]
+	return fmt.Sprintf("%s: %s: %s", method, e.Code, e.Message)
+}
+
+// Is reports whether target is the kind of e.
+func (e *Error) Is(target error) bool {
+	if e.Code == codes.DeadlineExceeded && target == context.DeadlineExceeded {
+		return true
+	}
+	return target != nil && kinds[e.Code] == target
+}
+
+// GRPCStatus returns the status the server sent, so status.FromError and
+// trainerr's readers keep working on errors from the client.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// newError converts the error of a call to method into an *Error. Errors
+// that are neither statuses nor context errors are returned unchanged.
+func newError(method string, attempts int, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		// grpc reports the caller's context errors as statuses; anything
+		// else did not come from the call
+		st = status.FromContextError(err)
+		if st.Code() == codes.Unknown {
+			return err
+		}
+	}
+	e := &Error{
+		Method:   method,
+		Code:     st.Code(),
+		Message:  st.Message(),
+		Attempts: attempts,
+		status:   st,
+	}
+	for _, v := range trainerr.FieldViolations(err) {
+		e.Violations = append(e.Violations, FieldViolation{Field: v.Field, Description: v.Description})
+	}
+	e.RetryAfter, _ = trainerr.RetryDelay(err)
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ResourceInfo:
+			e.Resource = &Resource{Type: d.ResourceType, Name: d.ResourceName}
+		case *errdetails.PreconditionFailure:
+			for _, v := range d.Violations {
+				e.Preconditions = append(e.Preconditions, Precondition{Type: v.Type, Subject: v.Subject, Description: v.Description})
+			}
+		}
+	}
+	return e
+}
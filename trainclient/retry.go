@@ -0,0 +1,168 @@
+package trainclient
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	mathrand "math/rand/v2"
+	"slices"
+	"time"
+
+	pb "test_train/protobuf"
+	"test_train/trainerr"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+)
+
+// RetryPolicy decides which failed calls are sent again and how long to
+// wait in between. The wait grows from InitialBackoff by Multiplier on each
+// attempt up to MaxBackoff, with jitter, and is never shorter than the delay
+// the server suggested. A call is not retried if the server suggested
+// waiting longer than MaxBackoff, as it does for a sold-out train, or if
+// the wait would run past the call's deadline.
+type RetryPolicy struct {
+	// MaxAttempts bounds the number of times a call is sent, counting the
+	// first; 1 disables retries.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Codes are the transient codes worth retrying. ResourceExhausted is
+	// only retried when the server suggests a delay.
+	Codes []codes.Code
+}
+
+// DefaultRetryPolicy retries unavailable servers and exhausted resources up
+// to four attempts in all.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Codes:          []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+	}
+}
+
+// backoff returns how long to wait after the given attempt failed with err,
+// and false if the call should not be retried.
+func (p RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	code := trainerr.Code(err)
+	if attempt >= p.MaxAttempts || !slices.Contains(p.Codes, code) {
+		return 0, false
+	}
+	suggested, ok := trainerr.RetryDelay(err)
+	if suggested > p.MaxBackoff || (code == codes.ResourceExhausted && (!ok || suggested == 0)) {
+		return 0, false
+	}
+	return max(p.jitter(attempt), suggested), true
+}
+
+// jitter returns a random wait between half and all of the backoff for
+// attempt, so clients failed by the same outage do not retry in step.
+func (p RetryPolicy) jitter(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	backoff = min(backoff, float64(p.MaxBackoff))
+	return time.Duration(backoff/2 + mathrand.Float64()*backoff/2)
+}
+
+// IdempotencyKeyHeader is the metadata key carrying a call's idempotency
+// key.
+const IdempotencyKeyHeader = "idempotency-key"
+
+// changes are the methods that change bookings. Each call to one carries
+// an idempotency key, the same on every attempt, so a server that honours
+// keys applies a retried change once.
+var changes = map[string]bool{
+	pb.TrainService_PurchaseTicket_FullMethodName:      true,
+	pb.TrainService_PurchaseGroupTicket_FullMethodName: true,
+	pb.TrainService_RemoveUser_FullMethodName:          true,
+	pb.TrainService_ModifyUserSeat_FullMethodName:      true,
+	pb.TrainService_SwapSeats_FullMethodName:           true,
+	pb.TrainService_HoldSeat_FullMethodName:            true,
+	pb.TrainService_ConfirmHold_FullMethodName:         true,
+	pb.TrainService_CancelTicket_FullMethodName:        true,
+	pb.TrainService_JoinWaitlist_FullMethodName:        true,
+	pb.TrainService_LeaveWaitlist_FullMethodName:       true,
+	pb.TrainService_CreateDeparture_FullMethodName:     true,
+	pb.TrainService_CancelDeparture_FullMethodName:     true,
+}
+
+// WithIdempotencyKey returns a context whose calls carry key instead of a
+// generated one. Use it to retry a change across restarts of the caller:
+// a key saved before the first attempt makes later attempts replays.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, IdempotencyKeyHeader, key)
+}
+
+// newIdempotencyKey returns a random key.
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// withIdempotencyKey adds a generated key to ctx unless it already has
+// one.
+func withIdempotencyKey(ctx context.Context) context.Context {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(IdempotencyKeyHeader)) > 0 {
+		return ctx
+	}
+	return WithIdempotencyKey(ctx, newIdempotencyKey())
+}
+
+// withDeadline applies the client's default deadline to calls without one.
+func (c *Client) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// invoke makes a unary call to method, retrying it according to the
+// client's policy, and converts its error.
+func invoke[Req, Resp any](ctx context.Context, c *Client, method string, req Req,
+	call func(context.Context, Req, ...grpc.CallOption) (Resp, error), opts []grpc.CallOption) (Resp, error) {
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+	if changes[method] {
+		ctx = withIdempotencyKey(ctx)
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := call(ctx, req, opts...)
+		if err == nil {
+			return resp, nil
+		}
+		delay, retry := c.retry.backoff(attempt, err)
+		if !retry || !c.wait(ctx, delay) {
+			var zero Resp
+			return zero, newError(method, attempt, err)
+		}
+	}
+}
+
+// wait sleeps for delay before another attempt, and reports false if ctx
+// ends first. A connection that went idle after failing is asked to
+// reconnect so the next attempt does not fail straight away.
+func (c *Client) wait(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	}
+	if c.conn != nil && c.conn.GetState() == connectivity.Idle {
+		c.conn.Connect()
+	}
+	return true
+}
@@ -0,0 +1,218 @@
+// Package trainerr defines the errors TrainService returns. Every error is a
+// gRPC status with a code clients can branch on and, where useful, standard
+// error details: BadRequest field violations for invalid input, ResourceInfo
+// naming the missing or conflicting resource, PreconditionFailure for
+// requests the current state does not allow, and RetryInfo when retrying
+// later may succeed.
+package trainerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Resource types reported in ResourceInfo details.
+const (
+	ResourceBooking       = "booking"
+	ResourceDeparture     = "departure"
+	ResourceHold          = "hold"
+	ResourceSeat          = "seat"
+	ResourceSubscription  = "subscription"
+	ResourceTicket        = "ticket"
+	ResourceUser          = "user"
+	ResourceWaitlistEntry = "waitlist_entry"
+)
+
+// newError builds a status error carrying details.
+func newError(code codes.Code, msg string, details ...protoadapt.MessageV1) error {
+	st := status.New(code, msg)
+	if len(details) > 0 {
+		if withDetails, err := st.WithDetails(details...); err == nil {
+			st = withDetails
+		}
+	}
+	return st.Err()
+}
+
+func resourceInfo(resourceType, name, description string) *errdetails.ResourceInfo {
+	return &errdetails.ResourceInfo{ResourceType: resourceType, ResourceName: name, Description: description}
+}
+
+// NotFound reports that the named resource does not exist.
+func NotFound(resourceType, name, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	return newError(codes.NotFound, msg, resourceInfo(resourceType, name, msg))
+}
+
+// AlreadyExists reports that the named resource is already taken.
+func AlreadyExists(resourceType, name, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	return newError(codes.AlreadyExists, msg, resourceInfo(resourceType, name, msg))
+}
+
+// FieldViolation describes one invalid request field.
+func FieldViolation(field, format string, args ...any) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)}
+}
+
+// InvalidArgument reports one or more invalid request fields. The message
+// lists every violation so clients that ignore details still see them.
+func InvalidArgument(violations ...*errdetails.BadRequest_FieldViolation) error {
+	parts := make([]string, 0, len(violations))
+	for _, v := range violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+	msg := "invalid request: " + strings.Join(parts, "; ")
+	return newError(codes.InvalidArgument, msg, &errdetails.BadRequest{FieldViolations: violations})
+}
+
+// InvalidField is InvalidArgument for a single field.
+func InvalidField(field, format string, args ...any) error {
+	return InvalidArgument(FieldViolation(field, format, args...))
+}
+
+// FailedPrecondition reports a request the current state of subject does
+// not allow. kind classifies the precondition, e.g. "DEPARTURE_CANCELLED".
+func FailedPrecondition(kind, subject, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	return newError(codes.FailedPrecondition, msg, &errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{Type: kind, Subject: subject, Description: msg}},
+	})
+}
+
+// Aborted reports a change that conflicts with a concurrent one to subject,
+// such as one based on a version of a ticket that is no longer current.
+// Clients should read subject again before deciding whether to retry.
+func Aborted(kind, subject, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	return newError(codes.Aborted, msg, &errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{Type: kind, Subject: subject, Description: msg}},
+	})
+}
+
+// ResourceExhausted reports that the named resource has no capacity left.
+// A positive retryAfter suggests when capacity may free up.
+func ResourceExhausted(resourceType, name string, retryAfter time.Duration, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	details := []protoadapt.MessageV1{resourceInfo(resourceType, name, msg)}
+	if retryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	}
+	return newError(codes.ResourceExhausted, msg, details...)
+}
+
+// Unavailable reports a transient failure worth retrying after retryAfter.
+func Unavailable(retryAfter time.Duration, format string, args ...any) error {
+	return newError(codes.Unavailable, fmt.Sprintf(format, args...),
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+}
+
+// Unauthenticated reports a request without valid credentials.
+func Unauthenticated(format string, args ...any) error {
+	return status.Error(codes.Unauthenticated, fmt.Sprintf(format, args...))
+}
+
+// PermissionDenied reports an authenticated caller acting beyond its role.
+func PermissionDenied(format string, args ...any) error {
+	return status.Error(codes.PermissionDenied, fmt.Sprintf(format, args...))
+}
+
+// Code returns the gRPC code of err; OK for nil and Unknown for errors that
+// are not statuses.
+func Code(err error) codes.Code {
+	return status.Code(err)
+}
+
+// FieldViolations returns the BadRequest field violations attached to err.
+func FieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range status.Convert(err).Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			violations = append(violations, br.FieldViolations...)
+		}
+	}
+	return violations
+}
+
+// RetryDelay returns the delay suggested by a RetryInfo detail on err.
+func RetryDelay(err error) (time.Duration, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if ri, ok := detail.(*errdetails.RetryInfo); ok {
+			return ri.RetryDelay.AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// Describe renders err with its code and details for people to read, e.g.
+// in command-line output.
+func Describe(err error) string {
+	st := status.Convert(err)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", st.Code(), st.Message())
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				fmt.Fprintf(&b, "\n  field %s: %s", v.Field, v.Description)
+			}
+		case *errdetails.ResourceInfo:
+			fmt.Fprintf(&b, "\n  %s %q", d.ResourceType, d.ResourceName)
+		case *errdetails.PreconditionFailure:
+			for _, v := range d.Violations {
+				fmt.Fprintf(&b, "\n  precondition %s on %s", v.Type, v.Subject)
+			}
+		case *errdetails.RetryInfo:
+			fmt.Fprintf(&b, "\n  retry after %s", d.RetryDelay.AsDuration())
+		}
+	}
+	return b.String()
+}
+
+// toStatus converts errors that are not already statuses. Context errors
+// keep their meaning; anything else is an internal error whose text is
+// logged rather than leaked to the client.
+func toStatus(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	log.Printf("%s: internal error: %v", method, err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// UnaryServerInterceptor converts handler errors that are not statuses into
+// proper status errors instead of letting gRPC report them as Unknown.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, toStatus(info.FullMethod, err)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return toStatus(info.FullMethod, handler(srv, ss))
+	}
+}
@@ -0,0 +1,79 @@
+package trainerr
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestInvalidArgumentCarriesFieldViolations(t *testing.T) {
+	err := InvalidArgument(
+		FieldViolation("user.email", "must be a valid email address"),
+		FieldViolation("from", "is required"),
+	)
+	assert.Equal(t, codes.InvalidArgument, Code(err))
+
+	violations := FieldViolations(err)
+	if assert.Len(t, violations, 2) {
+		assert.Equal(t, "user.email", violations[0].Field)
+		assert.Equal(t, "from", violations[1].Field)
+	}
+	assert.Contains(t, err.Error(), "user.email: must be a valid email address")
+}
+
+func TestResourceExhaustedCarriesRetryInfo(t *testing.T) {
+	err := ResourceExhausted(ResourceDeparture, "default", time.Minute, "Train is full")
+	assert.Equal(t, codes.ResourceExhausted, Code(err))
+
+	delay, ok := RetryDelay(err)
+	assert.True(t, ok, "expected a RetryInfo detail")
+	assert.Equal(t, time.Minute, delay)
+
+	description := Describe(err)
+	assert.True(t, strings.HasPrefix(description, "ResourceExhausted: Train is full"), description)
+	assert.Contains(t, description, `departure "default"`)
+	assert.Contains(t, description, "retry after 1m0s")
+}
+
+func TestAbortedCarriesPreconditionFailure(t *testing.T) {
+	err := Aborted("VERSION_MISMATCH", "TKT-1", "ticket TKT-1 has changed")
+	assert.Equal(t, codes.Aborted, Code(err))
+	assert.Contains(t, Describe(err), "VERSION_MISMATCH")
+}
+
+func TestUnaryServerInterceptorConvertsPlainErrors(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/train.TrainService/Test"}
+	call := func(err error) error {
+		_, got := interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+			return nil, err
+		})
+		return got
+	}
+
+	assert.NoError(t, call(nil))
+	assert.Equal(t, codes.NotFound, Code(call(NotFound(ResourceUser, "x", "user not found"))), "statuses pass through")
+	assert.Equal(t, codes.Internal, Code(call(errors.New("disk full"))))
+	assert.NotContains(t, call(errors.New("disk full")).Error(), "disk full", "internal details should not leak")
+	assert.Equal(t, codes.DeadlineExceeded, Code(call(context.DeadlineExceeded)))
+}
+
+func TestStreamServerInterceptorConvertsPlainErrors(t *testing.T) {
+	interceptor := StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/train.TrainService/Watch", IsServerStream: true}
+	call := func(err error) error {
+		return interceptor(nil, nil, info, func(srv any, stream grpc.ServerStream) error {
+			return err
+		})
+	}
+
+	assert.NoError(t, call(nil))
+	assert.Equal(t, codes.Internal, Code(call(errors.New("disk full"))))
+	assert.Equal(t, codes.Canceled, Code(call(context.Canceled)))
+}